@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/analysis"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/eco"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// analyze implements "chess analyze": it reviews a PGN game with the engine,
+// prints the inaccuracies, mistakes and blunders and writes the annotated
+// game.
+func analyze(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	out := flags.String("out", "", "annotated PGN file, the PGN name with .analyzed.pgn by default")
+	number := flags.Int("game", 1, "game of the PGN file to analyze")
+	depth := flags.Int("depth", 5, "plies searched in every position")
+	limit := flags.Duration("time", time.Second, "longest search of a position, 0 for no limit")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("usage: chess analyze game.pgn [--depth 5] [--time 1s] [--out game.analyzed.pgn]")
+	}
+
+	path := arguments[0]
+	if *out == "" {
+		*out = strings.TrimSuffix(path, filepath.Ext(path)) + ".analyzed.pgn"
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	games, err := pgn.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	if *number < 1 || *number > len(games) {
+		return fmt.Errorf("%s has %d games", path, len(games))
+	}
+	game := games[*number-1]
+
+	progress := func(done, total int) {
+		fmt.Fprintf(os.Stderr, "\ranalyzing position %d of %d", done, total)
+		if done == total {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+	report, err := analysis.Analyze(game, engine.Limits{Depth: *depth, Time: *limit}, progress)
+	if err != nil {
+		return err
+	}
+
+	for _, move := range report.Moves {
+		if move.Class < analysis.Inaccuracy {
+			continue
+		}
+		fmt.Printf("%-10s %-10s %-8s %s was best\n", moveCaption(pgn.Position{Number: move.Number, Team: move.Team, SAN: move.SAN}),
+			move.Class, fmt.Sprintf("-%d", move.Loss), move.Best)
+	}
+	fmt.Printf("White (%s): %s\n", name(game.Tag("White"), board.White), report.White)
+	fmt.Printf("Black (%s): %s\n", name(game.Tag("Black"), board.Black), report.Black)
+
+	annotated := report.Annotate(game)
+	eco.Tag(&annotated)
+	if err := os.WriteFile(*out, []byte(annotated.String()), 0o644); err != nil {
+		return err
+	}
+	fmt.Println("annotated game written to", *out)
+	return nil
+}
+
+// name returns the player named in the PGN or the side.
+func name(player string, team board.Team) string {
+	if player == "" || player == "?" {
+		return strings.ToLower(team.String())
+	}
+
+	return player
+}
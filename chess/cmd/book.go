@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/book"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+const bookUsage = "usage: chess book build games.pgn --out book.bin [--min-games 2] [--max-ply 20]\n       chess book probe book.bin [--fen FEN]"
+
+// openingBook implements "chess book": "build" makes a Polyglot book from
+// a PGN collection and "probe" lists the book moves of a position.
+func openingBook(args []string) error {
+	if len(args) == 0 {
+		return errors.New(bookUsage)
+	}
+
+	switch args[0] {
+	case "build":
+		return buildBook(args[1:])
+	case "probe":
+		return probeBook(args[1:])
+	default:
+		return errors.New(bookUsage)
+	}
+}
+
+func buildBook(args []string) error {
+	flags := flag.NewFlagSet("book build", flag.ContinueOnError)
+	out := flags.String("out", "book.bin", "book file to write")
+	minGames := flags.Int("min-games", 1, "leave out moves played in fewer games")
+	maxPly := flags.Int("max-ply", 20, "plies of each game to read, 0 for all")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) == 0 {
+		return errors.New(bookUsage)
+	}
+
+	var games []pgn.Game
+	for _, path := range arguments {
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		parsed, err := pgn.Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		games = append(games, parsed...)
+	}
+
+	opening, used, err := book.Build(games, book.BuildOptions{MinGames: *minGames, MaxPly: *maxPly})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := opening.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d entries from %d of %d games\n", *out, opening.Len(), used, len(games))
+	return nil
+}
+
+func probeBook(args []string) error {
+	flags := flag.NewFlagSet("book probe", flag.ContinueOnError)
+	fen := flags.String("fen", "", "position to look up, the initial one by default")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New(bookUsage)
+	}
+
+	opening, err := book.Open(arguments[0])
+	if err != nil {
+		return err
+	}
+
+	if *fen == "" {
+		*fen = board.Standard.StartFEN()
+	}
+	b, team, err := board.ParseFEN(*fen)
+	if err != nil {
+		return err
+	}
+
+	moves, err := opening.Moves(b, team)
+	if err != nil {
+		return err
+	}
+	if len(moves) == 0 {
+		fmt.Println("position is not in the book")
+		return nil
+	}
+
+	total := 0
+	for _, move := range moves {
+		total += move.Weight
+	}
+	for _, move := range moves {
+		san, _ := b.SAN(move.Command, team)
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(move.Weight) / float64(total)
+		}
+		fmt.Printf("%-8s %6d %5.1f%%\n", san, move.Weight, share)
+	}
+
+	return nil
+}
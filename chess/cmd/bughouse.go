@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+const bughouseUsage = "usage: chess bughouse [--style STYLE]"
+
+// playBughouse implements "chess bughouse": four players share the terminal
+// for a Bughouse match. Every move names its board first, e.g. "0 e2 e4" or
+// "1 N@f3", and the pieces a player captures can be dropped by the partner.
+func playBughouse(args []string) error {
+	flags := flag.NewFlagSet("bughouse", flag.ContinueOnError)
+	view := viewFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errors.New(bughouseUsage)
+	}
+
+	match := game.NewBughouse()
+	for i := 0; i < 2; i++ {
+		if err := view.apply(match.Game(i)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("White on board 0 and Black on board 1 are partners, as are the other two.")
+	printBughouse(match)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		if result, over := match.Result(); over {
+			fmt.Printf("Board 0: %s, %s.\n", result.Score(), result.Termination)
+			return nil
+		}
+
+		fmt.Print("Board and move (e.g. 0 e2 e4), or quit: ")
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		input := strings.TrimSpace(scanner.Text())
+		if input == "quit" {
+			return nil
+		}
+
+		index, move, _ := strings.Cut(input, " ")
+		if index != "0" && index != "1" {
+			fmt.Println(game.ErrUnknownBoard)
+			continue
+		}
+		if err := match.Play(int(index[0]-'0'), move); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		printBughouse(match)
+	}
+}
+
+func printBughouse(match *game.BughouseMatch) {
+	for i := 0; i < 2; i++ {
+		chessGame := match.Game(i)
+		fmt.Printf("\nBoard %d, %s to move:\n", i, chessGame.Turn())
+		chessGame.PrintGameStatus()
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+)
+
+// diagram implements "chess render": it writes a position as an SVG or PNG
+// picture, chosen by the extension of --out.
+func diagram(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	fen := flags.String("fen", board.StartFEN, "position to draw")
+	out := flags.String("out", "", "output file, .svg or .png")
+	size := flags.Int("size", 480, "width and height in pixels")
+	perspective := flags.String("perspective", "white", "side at the bottom: white, black or auto for the side to move")
+	coordinates := flags.Bool("coords", true, "draw files and ranks")
+	highlight := flags.String("highlight", "", "squares to highlight, e.g. e2,e4")
+	arrows := flags.String("arrows", "", "arrows to draw, e.g. e2e4,g1f3")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("usage: chess render --fen FEN --out pos.svg|pos.png")
+	}
+
+	b, team, err := board.ParseFEN(*fen)
+	if err != nil {
+		return err
+	}
+
+	options := render.DiagramOptions{
+		Options: render.Options{Coordinates: *coordinates},
+		Size:    *size,
+	}
+
+	if options.Perspective, err = parsePerspective(*perspective); err != nil {
+		return err
+	}
+	if options.Perspective == board.Undecided {
+		options.Perspective = team
+	}
+
+	if *highlight != "" {
+		options.Highlight = strings.Split(*highlight, ",")
+	}
+	if options.Arrows, err = render.ParseArrows(*arrows); err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(*out)) {
+	case ".svg":
+		return os.WriteFile(*out, []byte(render.SVG(b, options)), 0o644)
+	case ".png":
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		if err := render.PNG(file, b, options); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	default:
+		return fmt.Errorf("unknown picture format %q, use .svg or .png", filepath.Ext(*out))
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/eco"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// classify implements "chess eco": it names the opening of every game of a
+// PGN file, or of the position given with --fen, and can write the games
+// back with their ECO and Opening tags.
+func classify(args []string) error {
+	flags := flag.NewFlagSet("eco", flag.ContinueOnError)
+	fen := flags.String("fen", "", "position to name instead of a PGN file")
+	out := flags.String("out", "", "write the games with their ECO, Opening and Variation tags to this file")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *fen != "" {
+		b, team, err := board.ParseFEN(*fen)
+		if err != nil {
+			return err
+		}
+		opening, ok := eco.Lookup(b, team)
+		if !ok {
+			return errors.New("position is not in the opening table")
+		}
+		fmt.Println(opening)
+		return nil
+	}
+
+	if len(arguments) != 1 {
+		return errors.New("usage: chess eco games.pgn [--out tagged.pgn] | chess eco --fen FEN")
+	}
+
+	text, err := os.ReadFile(arguments[0])
+	if err != nil {
+		return err
+	}
+	games, err := pgn.Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	var tagged strings.Builder
+	for i := range games {
+		game := &games[i]
+		players := fmt.Sprintf("%s - %s", name(game.Tag("White"), board.White), name(game.Tag("Black"), board.Black))
+		if eco.Tag(game) {
+			fmt.Printf("%d. %s: %s %s\n", i+1, players, game.Tag("ECO"), openingName(*game))
+		} else {
+			fmt.Printf("%d. %s: unknown opening\n", i+1, players)
+		}
+
+		if i > 0 {
+			tagged.WriteString("\n")
+		}
+		tagged.WriteString(game.String())
+	}
+
+	if *out == "" {
+		return nil
+	}
+	return os.WriteFile(*out, []byte(tagged.String()), 0o644)
+}
+
+// openingName joins the Opening and Variation tags.
+func openingName(game pgn.Game) string {
+	if variation := game.Tag("Variation"); variation != "" {
+		return game.Tag("Opening") + ": " + variation
+	}
+
+	return game.Tag("Opening")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+)
+
+// animate implements "chess gif": it replays a PGN game and writes one
+// frame per ply into an animated GIF.
+func animate(args []string) error {
+	flags := flag.NewFlagSet("gif", flag.ContinueOnError)
+	out := flags.String("out", "", "output file, the PGN name with .gif by default")
+	number := flags.Int("game", 1, "game of the PGN file to animate")
+	delay := flags.Duration("delay", time.Second, "time each position is shown")
+	size := flags.Int("size", 360, "width of the picture in pixels")
+	perspective := flags.String("perspective", "white", "side at the bottom: white or black")
+	coordinates := flags.Bool("coords", true, "draw files and ranks")
+	lastMove := flags.Bool("last-move", true, "highlight the squares of the last move")
+	caption := flags.Bool("caption", true, "write the move number and move below the board")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("usage: chess gif game.pgn [--out game.gif]")
+	}
+
+	path := arguments[0]
+	if *out == "" {
+		*out = strings.TrimSuffix(path, filepath.Ext(path)) + ".gif"
+	}
+
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	games, err := pgn.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	if *number < 1 || *number > len(games) {
+		return fmt.Errorf("%s has %d games", path, len(games))
+	}
+	game := games[*number-1]
+
+	positions, err := game.Replay()
+	if err != nil {
+		return err
+	}
+
+	options := render.DiagramOptions{
+		Options: render.Options{Coordinates: *coordinates},
+		Size:    *size,
+	}
+	if options.Perspective, err = parsePerspective(*perspective); err != nil {
+		return err
+	}
+	if options.Perspective == board.Undecided {
+		options.Perspective = board.White
+	}
+
+	frames := make([]render.Frame, 0, len(positions))
+	for i, position := range positions {
+		frame := render.Frame{Board: position.Board}
+		if *lastMove && position.Command != "" {
+			frame.Highlight = render.LastMove([]string{position.Command})
+		}
+		if *caption {
+			frame.Caption = moveCaption(position)
+			if i == len(positions)-1 && game.Result != "" && game.Result != "*" {
+				frame.Caption = strings.TrimSpace(frame.Caption + " " + game.Result)
+			}
+		}
+		frames = append(frames, frame)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := render.GIF(file, frames, options, *delay); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// moveCaption writes "12. Nf3" for White and "12... Nc6" for Black.
+func moveCaption(position pgn.Position) string {
+	switch {
+	case position.SAN == "":
+		return ""
+	case position.Team == board.White:
+		return fmt.Sprintf("%d. %s", position.Number, position.SAN)
+	default:
+		return fmt.Sprintf("%d... %s", position.Number, position.SAN)
+	}
+}
+
+// parseArguments parses flags written before, between or after positional
+// arguments and returns the positional ones.
+func parseArguments(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
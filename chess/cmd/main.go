@@ -1,8 +1,45 @@
 package main
 
-import "github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+import (
+	"fmt"
+	"os"
+)
+
+var commands = map[string]func(args []string) error{
+	"play":       play,
+	"bughouse":   playBughouse,
+	"resume":     resume,
+	"list":       list,
+	"export":     export,
+	"serve":      serve,
+	"render":     diagram,
+	"gif":        animate,
+	"analyze":    analyze,
+	"book":       openingBook,
+	"eco":        classify,
+	"tb":         endgameTables,
+	"solve":      solve,
+	"puzzles":    puzzles,
+	"repertoire": drillRepertoire,
+	"match":      playMatch,
+	"uci":        serveUCI,
+	"tournament": manageTournament,
+	"ratings":    showRatings,
+}
 
 func main() {
-	game := game.New()
-	game.Start()
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	if err := play(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }
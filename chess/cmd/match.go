@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/match"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+)
+
+// playMatch implements "chess match": it plays games between two engines,
+// writes them to a PGN file and reports the Elo difference and the SPRT.
+func playMatch(args []string) error {
+	flags := flag.NewFlagSet("match", flag.ContinueOnError)
+	first := flags.String("engine1", "internal", "first engine: internal or cmd=PATH, then name=, depth=, movetime= and option.NAME=")
+	second := flags.String("engine2", "internal", "second engine, as --engine1")
+	games := flags.Int("games", 10, "games to play, each opening twice with the colours swapped")
+	concurrency := flags.Int("concurrency", 2, "games played at the same time")
+	depth := flags.Int("depth", 3, "plies searched by engines without depth or movetime")
+	moveTime := flags.Duration("movetime", 0, "time of a move for engines without depth or movetime")
+	openings := flags.String("openings", "", "EPD or PGN file of the openings, the initial position by default")
+	out := flags.String("out", "match.pgn", "PGN file of the games")
+	tables := flags.String("tablebases", tablebase.DefaultDir(), "directory of the endgame tablebases adjudicating games, empty for none")
+	resignScore := flags.Int("resign-score", 800, "centipawns both engines must see for a side to adjudicate a win")
+	resignMoves := flags.Int("resign-moves", 4, "moves each in a row for a win adjudication, 0 for none")
+	drawScore := flags.Int("draw-score", 10, "centipawns both engines must stay within to adjudicate a draw")
+	drawMoves := flags.Int("draw-moves", 8, "moves each in a row for a draw adjudication, 0 for none")
+	drawAfter := flags.Int("draw-after", 40, "move from which draws are adjudicated")
+	sprt := flags.String("sprt", "", "Elo bounds of an SPRT, e.g. 0,10, the match stopping once it passes or fails")
+	alpha := flags.Float64("alpha", 0.05, "false positive rate of the SPRT")
+	beta := flags.Float64("beta", 0.05, "false negative rate of the SPRT")
+	event := flags.String("event", "", "Event tag of the games")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 0 || *games < 1 {
+		return errors.New("usage: chess match --engine1 SPEC --engine2 SPEC [--games 10] [--concurrency 2] [--openings FILE] [--sprt 0,10] [--out match.pgn]")
+	}
+
+	config := match.Config{
+		Games:       *games,
+		Concurrency: *concurrency,
+		Depth:       *depth,
+		MoveTime:    *moveTime,
+		Adjudication: match.Adjudication{
+			ResignScore: *resignScore,
+			ResignMoves: *resignMoves,
+			DrawScore:   *drawScore,
+			DrawMoves:   *drawMoves,
+			DrawAfter:   *drawAfter,
+		},
+		Event: *event,
+	}
+	for i, text := range []string{*first, *second} {
+		if config.Engines[i], err = match.ParseSpec(text); err != nil {
+			return err
+		}
+	}
+	if *openings != "" {
+		if config.Openings, err = match.LoadOpenings(*openings); err != nil {
+			return err
+		}
+	}
+	if *tables != "" {
+		config.Tablebases = tablebase.Open(*tables)
+	}
+	if *sprt != "" {
+		if config.SPRT, err = parseSPRT(*sprt, *alpha, *beta); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	started := time.Now()
+	score, err := match.Run(config, func(played match.Game, score match.Score) error {
+		result := played.Result
+		fmt.Printf("Game %d, %s - %s: %s (%s). %s\n", played.Round, played.Record.Tag("White"), played.Record.Tag("Black"),
+			result.Score(), result.Termination, score)
+		if config.SPRT != nil {
+			printSPRT(*config.SPRT, score)
+		}
+		_, err := fmt.Fprintln(file, played.Record.String())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d games in %s, first engine %s\n", score.Games(), time.Since(started).Round(time.Second), score)
+	if config.SPRT != nil {
+		printSPRT(*config.SPRT, score)
+	}
+	fmt.Println("games written to", *out)
+	return nil
+}
+
+// parseSPRT reads the Elo bounds of an SPRT, "elo0,elo1".
+func parseSPRT(text string, alpha, beta float64) (*match.SPRT, error) {
+	bounds := strings.Split(text, ",")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("sprt %q: want elo0,elo1", text)
+	}
+
+	elo0, err := strconv.ParseFloat(bounds[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("sprt %q: %w", text, err)
+	}
+	elo1, err := strconv.ParseFloat(bounds[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("sprt %q: %w", text, err)
+	}
+	if elo1 <= elo0 || alpha <= 0 || alpha >= 1 || beta <= 0 || beta >= 1 {
+		return nil, fmt.Errorf("sprt %q: want elo0 < elo1 and error rates between 0 and 1", text)
+	}
+
+	return &match.SPRT{Elo0: elo0, Elo1: elo1, Alpha: alpha, Beta: beta}, nil
+}
+
+func printSPRT(sprt match.SPRT, score match.Score) {
+	lower, upper := sprt.Bounds()
+	fmt.Printf("SPRT [%g, %g]: LLR %.2f (%.2f, %.2f), %s\n", sprt.Elo0, sprt.Elo1, sprt.LLR(score), lower, upper, sprt.Decide(score))
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/book"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tui"
+)
+
+func play(args []string) error {
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	fen := flags.String("fen", "", "start from this position instead of the playbook")
+	chess960 := flags.String("chess960", "", "play Fischer Random from start position 0-959 or random")
+	variant := flags.String("variant", "", "rules to play by, e.g. three-check, king-of-the-hill, atomic, crazyhouse, antichess, horde, gardner or capablanca")
+	clock := flags.String("clock", "", "time control as minutes+increment, e.g. 5+3")
+	white := flags.String("white", "", "name of the white player")
+	black := flags.String("black", "", "name of the black player")
+	dir := flags.String("dir", storage.DefaultDir(), "directory of saved games")
+	fullScreen := flags.Bool("tui", false, "play in the full-screen terminal interface")
+	computer := flags.String("computer", "", "side the engine plays: white or black")
+	openings := flags.String("book", "", "Polyglot opening book for the engine")
+	tables := flags.String("tablebases", tablebase.DefaultDir(), "directory of the endgame tablebases for the engine")
+	view := viewFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	rules, err := board.ParseVariant(*variant)
+	if err != nil {
+		return err
+	}
+
+	var chessGame *game.ChessGame
+	switch {
+	case *chess960 != "" && (*fen != "" || rules != board.Standard):
+		return errors.New("chess960 cannot be combined with fen or variant")
+	case *chess960 != "":
+		index := -1
+		if *chess960 != "random" {
+			if index, err = strconv.Atoi(*chess960); err != nil {
+				return fmt.Errorf("bad chess960 position %q, expected 0-959 or random", *chess960)
+			}
+		}
+		if chessGame, err = game.NewChess960(index); err != nil {
+			return err
+		}
+		fmt.Printf("Chess960 position %s, castle by taking your own rook, e.g. \"b1 a1\".\n", chessGame.StartFEN())
+	case *fen != "" || rules != board.Standard:
+		if chessGame, err = game.NewVariant(*fen, rules); err != nil {
+			return err
+		}
+	default:
+		newGame := game.New()
+		chessGame = &newGame
+		chessGame.SetupPlaybook()
+	}
+
+	if *clock != "" {
+		control, err := parseClock(*clock)
+		if err != nil {
+			return err
+		}
+		chessGame.SetClock(game.NewClock(control))
+	}
+
+	if err := view.apply(chessGame); err != nil {
+		return err
+	}
+
+	if err := setComputer(chessGame, *computer, *openings, *tables, *fullScreen); err != nil {
+		return err
+	}
+
+	record := &storage.Record{White: *white, Black: *black}
+	return run(chessGame, persist(store, record), *fullScreen)
+}
+
+// run plays the game in the line-based loop or the full-screen interface,
+// saving it with onChange.
+func run(chessGame *game.ChessGame, onChange func(*game.ChessGame), fullScreen bool) error {
+	chessGame.OnChange(onChange)
+
+	if !fullScreen {
+		chessGame.Continue()
+		return nil
+	}
+
+	onChange(chessGame)
+	return tui.Run(chessGame)
+}
+
+// persist saves the game after every change.
+func persist(store storage.Store, record *storage.Record) func(*game.ChessGame) {
+	return func(chessGame *game.ChessGame) {
+		created := record.ID == ""
+
+		record.Capture(chessGame)
+		if err := store.Save(record); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to save the game:", err)
+			return
+		}
+
+		if created {
+			fmt.Printf("Game %s is saved after every move, continue it later with \"chess resume %s\".\n", record.ID, record.ID)
+		}
+	}
+}
+
+// setComputer lets the engine play the side, with moves from the opening
+// book at the path while it has them and the tablebases of the directory.
+func setComputer(chessGame *game.ChessGame, side, path, tables string, fullScreen bool) error {
+	switch {
+	case side == "" && path != "":
+		return errors.New("book is used by the engine, choose its side with --computer")
+	case side == "":
+		return nil
+	case fullScreen:
+		return errors.New("computer is only available in the line-based game")
+	}
+
+	team, err := board.ParseTeam(side)
+	if err != nil {
+		return err
+	}
+
+	var opening *book.Book
+	if path != "" {
+		if opening, err = book.Open(path); err != nil {
+			return err
+		}
+	}
+
+	computer := game.NewComputer(team, opening)
+	computer.Limits.Tablebases = tablebase.Open(tables)
+	chessGame.SetComputer(computer)
+	return nil
+}
+
+// parseClock reads "minutes+increment" with the increment in seconds.
+func parseClock(value string) (game.TimeControl, error) {
+	minutes, increment, _ := strings.Cut(value, "+")
+
+	base, err := strconv.ParseFloat(minutes, 64)
+	if err != nil || base <= 0 {
+		return game.TimeControl{}, fmt.Errorf("bad clock %q, expected minutes+increment", value)
+	}
+
+	var inc float64
+	if increment != "" {
+		if inc, err = strconv.ParseFloat(increment, 64); err != nil || inc < 0 {
+			return game.TimeControl{}, fmt.Errorf("bad clock %q, expected minutes+increment", value)
+		}
+	}
+
+	return game.TimeControl{
+		Base:      time.Duration(base * float64(time.Minute)),
+		Increment: time.Duration(inc * float64(time.Second)),
+	}, nil
+}
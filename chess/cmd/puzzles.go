@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/puzzle"
+)
+
+// puzzles implements "chess puzzles": tactics training from a CSV or EPD
+// file with the profile of the user kept in a directory.
+func puzzles(args []string) error {
+	flags := flag.NewFlagSet("puzzles", flag.ContinueOnError)
+	user := flags.String("user", "player", "name of the profile to train with")
+	count := flags.Int("count", 10, "puzzles to play, 0 for all")
+	dir := flags.String("dir", puzzle.DefaultDir(), "directory of the training profiles")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("usage: chess puzzles puzzles.csv|puzzles.epd [--user NAME] [--count 10] [--dir DIR]")
+	}
+
+	set, err := puzzle.Load(arguments[0])
+	if err != nil {
+		return err
+	}
+	profile, err := puzzle.LoadProfile(*dir, *user)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d puzzles. %s: rating %.0f, %d due for review. Type moves like Nf3 or \"g1 f3\", skip or quit.\n\n",
+		len(set), profile.User, profile.Rating, len(profile.Reviews))
+
+	trainer := puzzle.NewTrainer(profile, set, os.Stdin, os.Stdout)
+	return trainer.Run(*count, func(profile *puzzle.Profile) error {
+		return profile.Save(*dir)
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/rating"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+// showRatings implements "chess ratings": the leaderboards of the rated
+// games played on the servers, or the ratings of one player.
+func showRatings(args []string) error {
+	flags := flag.NewFlagSet("ratings", flag.ContinueOnError)
+	categoryName := flags.String("category", "", "bullet, blitz, rapid or classical, all of them by default")
+	history := flags.Bool("history", false, "list the rated games of the player")
+	dir := flags.String("dir", storage.DefaultRatingsDir(), "directory of the player ratings")
+	arguments, err := parseArguments(flags, args)
+	if err != nil || len(arguments) > 1 || (*history && len(arguments) == 0) {
+		return errors.New("usage: chess ratings [NAME [--history]] [--category blitz] [--dir DIR]")
+	}
+
+	categories := rating.Categories
+	if *categoryName != "" {
+		category, err := rating.ParseCategory(*categoryName)
+		if err != nil {
+			return err
+		}
+		categories = []rating.Category{category}
+	}
+
+	store, err := storage.NewRatingStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	if len(arguments) == 0 {
+		players, err := store.Players()
+		if err != nil {
+			return err
+		}
+		for i, category := range categories {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s\n%s", category, rating.Table(rating.Leaderboard(players, category)))
+		}
+		return nil
+	}
+
+	player, err := store.Player(arguments[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(player.Summary())
+	if *history {
+		for _, category := range categories {
+			printRatingHistory(player, category)
+		}
+	}
+	return nil
+}
+
+func printRatingHistory(player *rating.Player, category rating.Category) {
+	rated, ok := player.Ratings[category]
+	if !ok {
+		return
+	}
+
+	fmt.Printf("\n%s games\n", category)
+	for _, change := range rated.History {
+		fmt.Printf("%s  %-16s %-4s  Glicko %4.0f ± %3.0f  Elo %4.0f\n",
+			change.Time.Format("2006-01-02 15:04"), change.Opponent,
+			map[float64]string{1: "won", 0.5: "drew", 0: "lost"}[change.Score],
+			change.Glicko, change.Deviation, change.Elo)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/repertoire"
+)
+
+// drillRepertoire implements "chess repertoire": it drills the lines of a
+// PGN repertoire for one side, keeping the progress in a file.
+func drillRepertoire(args []string) error {
+	flags := flag.NewFlagSet("repertoire", flag.ContinueOnError)
+	side := flags.String("side", "white", "side of the repertoire: white or black")
+	count := flags.Int("count", 10, "lines to drill, 0 for all those due")
+	progressPath := flags.String("progress", "", "file of the progress, by default named after the PGN file and the side in "+repertoire.DefaultDir())
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("usage: chess repertoire repertoire.pgn [--side white|black] [--count 10] [--progress FILE]")
+	}
+
+	team, err := board.ParseTeam(*side)
+	if err != nil {
+		return err
+	}
+
+	text, err := os.ReadFile(arguments[0])
+	if err != nil {
+		return err
+	}
+	games, err := pgn.Parse(string(text))
+	if err != nil {
+		return err
+	}
+	tree, err := repertoire.Build(games, team)
+	if err != nil {
+		return fmt.Errorf("%s: %w", arguments[0], err)
+	}
+
+	path := *progressPath
+	if path == "" {
+		base := strings.TrimSuffix(filepath.Base(arguments[0]), filepath.Ext(arguments[0]))
+		path = filepath.Join(repertoire.DefaultDir(), base+"-"+team.String()+".json")
+	}
+	progress, err := repertoire.LoadProgress(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d lines for %s. Type moves like Nf3 or \"g1 f3\", or quit.\n\n", len(tree.Lines), team)
+	trainer := repertoire.NewTrainer(tree, progress, os.Stdin, os.Stdout)
+	return trainer.Run(*count, func(progress *repertoire.Progress) error {
+		return progress.Save(path)
+	})
+}
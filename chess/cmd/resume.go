@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+func resume(args []string) error {
+	flags := flag.NewFlagSet("resume", flag.ContinueOnError)
+	dir := flags.String("dir", storage.DefaultDir(), "directory of saved games")
+	fullScreen := flags.Bool("tui", false, "play in the full-screen terminal interface")
+	view := viewFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: chess resume [--dir DIR] [--tui] <id>")
+	}
+
+	store, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	record, err := store.Load(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("game %s: %w", flags.Arg(0), err)
+	}
+
+	chessGame, err := storage.Restore(record)
+	if err != nil {
+		return fmt.Errorf("game %s: %w", record.ID, err)
+	}
+
+	if record.Finished() {
+		fmt.Println(chessGame.Board().String())
+		fmt.Printf("Game %s is over: %s %s\n", record.ID, record.Result, record.Termination)
+		return nil
+	}
+
+	if err := view.apply(chessGame); err != nil {
+		return err
+	}
+
+	return run(chessGame, persist(store, &record), *fullScreen)
+}
+
+func list(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	dir := flags.String("dir", storage.DefaultDir(), "directory of saved games")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	records, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("No saved games.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUPDATED\tWHITE\tBLACK\tMOVES\tRESULT")
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s %s\n",
+			record.ID,
+			record.UpdatedAt.Format("2006-01-02 15:04"),
+			playerName(record.White),
+			playerName(record.Black),
+			len(record.Moves),
+			record.Result,
+			record.Termination)
+	}
+
+	return w.Flush()
+}
+
+func playerName(name string) string {
+	if name == "" {
+		return "?"
+	}
+
+	return name
+}
+
+// export implements "chess export": it prints a saved game as PGN.
+func export(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	dir := flags.String("dir", storage.DefaultDir(), "directory of saved games")
+	out := flags.String("out", "", "write to this file instead of the standard output")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 1 {
+		return errors.New("usage: chess export [--dir DIR] [--out game.pgn] <id>")
+	}
+
+	store, err := storage.NewFileStore(*dir)
+	if err != nil {
+		return err
+	}
+
+	record, err := store.Load(arguments[0])
+	if err != nil {
+		return fmt.Errorf("game %s: %w", arguments[0], err)
+	}
+
+	chessGame, err := storage.Restore(record)
+	if err != nil {
+		return fmt.Errorf("game %s: %w", record.ID, err)
+	}
+
+	game := chessGame.PGN()
+	game.SetTag("Date", record.CreatedAt.Format("2006.01.02"))
+	game.SetTag("White", playerName(record.White))
+	game.SetTag("Black", playerName(record.Black))
+
+	if *out == "" {
+		fmt.Print(game.String())
+		return nil
+	}
+
+	return os.WriteFile(*out, []byte(game.String()), 0o644)
+}
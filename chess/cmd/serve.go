@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/ics"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/server"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address of the HTTP API, empty to disable it")
+	tcp := flags.String("tcp", "", "address of the telnet server, e.g. :5000")
+	ratingsDir := flags.String("ratings", storage.DefaultRatingsDir(), "directory of the player ratings, empty for unrated games")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *addr == "" && *tcp == "" {
+		return errors.New("serve: nothing to serve, set --addr or --tcp")
+	}
+
+	var ratings *storage.RatingStore
+	if *ratingsDir != "" {
+		var err error
+		if ratings, err = storage.NewRatingStore(*ratingsDir); err != nil {
+			return err
+		}
+	}
+
+	errs := make(chan error, 2)
+
+	if *tcp != "" {
+		fmt.Println("Serving telnet games on", *tcp)
+		icsServer := ics.NewServer()
+		if ratings != nil {
+			icsServer.SetRatings(ratings)
+		}
+		go func() { errs <- icsServer.ListenAndServe(*tcp) }()
+	}
+
+	if *addr != "" {
+		fmt.Println("Serving HTTP API on", *addr)
+		store := server.NewStore()
+		if ratings != nil {
+			store.SetRatings(ratings)
+		}
+		go func() { errs <- http.ListenAndServe(*addr, server.New(store)) }()
+	}
+
+	return <-errs
+}
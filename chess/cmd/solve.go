@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/solver"
+)
+
+// solve implements "chess solve": it proves the mate of a problem, prints
+// every key with its solution tree and reports a cooked problem.
+func solve(args []string) error {
+	flags := flag.NewFlagSet("solve", flag.ContinueOnError)
+	fen := flags.String("fen", "", "position of the problem, the attacker to move")
+	mate := flags.Int("mate", 2, "moves the attacker has to mate in")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if *fen == "" || len(arguments) != 0 {
+		return errors.New("usage: chess solve --fen FEN --mate N")
+	}
+
+	b, team, err := board.ParseFEN(*fen)
+	if err != nil {
+		return err
+	}
+
+	solution, err := solver.Solve(b, team, *mate)
+	if errors.Is(err, solver.ErrNoMate) {
+		return fmt.Errorf("%s does not mate in %d (%d nodes)", team, *mate, solution.Nodes)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Plies are numbered from the key, one more when Black starts.
+	offset := 0
+	if team == board.Black {
+		offset = 1
+	}
+
+	if solution.Cooked() {
+		fmt.Printf("Cooked: %d keys mate in at most %d (%d nodes)\n", len(solution.Keys), *mate, solution.Nodes)
+	} else {
+		fmt.Printf("Unique key, mate in %d (%d nodes)\n", solution.Keys[0].Moves, solution.Nodes)
+	}
+	for _, key := range solution.Keys {
+		fmt.Printf("\n%s %s! mate in %d\n", moveNumber(0, offset), key.SAN, key.Moves)
+		printTree(key.Attack, 0, offset, "    ")
+	}
+
+	return nil
+}
+
+// printTree writes every defence against the attack with the attacker's
+// answer on a line, and the play that follows indented below it.
+func printTree(attack solver.Attack, ply, offset int, indent string) {
+	for _, defence := range attack.Defences {
+		line := fmt.Sprintf("%s%s %s", indent, moveNumber(ply+1, offset), defence.SAN)
+		if defence.Attack.SAN != "" {
+			line += fmt.Sprintf(" %s %s", moveNumber(ply+2, offset), defence.Attack.SAN)
+		}
+		fmt.Println(line)
+		printTree(defence.Attack, ply+2, offset, indent+"    ")
+	}
+}
+
+// moveNumber writes the number of a ply, "2." for White and "2..." for
+// Black.
+func moveNumber(ply, offset int) string {
+	ply += offset
+	if ply%2 == 0 {
+		return fmt.Sprintf("%d.", ply/2+1)
+	}
+
+	return fmt.Sprintf("%d...", ply/2+1)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+)
+
+const tablebaseUsage = "usage: chess tb generate [--dir DIR] [KQK KRK KPK KBNK]\n       chess tb --fen FEN [--dir DIR]"
+
+// endgameTables implements "chess tb": "generate" builds the tablebases
+// into a directory, otherwise the position given with --fen is probed.
+func endgameTables(args []string) error {
+	if len(args) > 0 && args[0] == "generate" {
+		return generateTables(args[1:])
+	}
+
+	flags := flag.NewFlagSet("tb", flag.ContinueOnError)
+	fen := flags.String("fen", "", "position to probe")
+	dir := flags.String("dir", tablebase.DefaultDir(), "directory of the tablebases")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if *fen == "" || len(arguments) != 0 {
+		return errors.New(tablebaseUsage)
+	}
+
+	b, team, err := board.ParseFEN(*fen)
+	if err != nil {
+		return err
+	}
+
+	tables := tablebase.Open(*dir)
+	result, err := tables.Probe(b, team)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w, build the tables with \"chess tb generate\"", err)
+	}
+	if err != nil {
+		return err
+	}
+	moves, err := tables.Moves(b, team)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s to move: %s\n", team, describe(result))
+	for _, move := range moves {
+		san, err := b.SAN(move.Command, team)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %-8s %s\n", san, describe(move.Result))
+	}
+
+	return nil
+}
+
+// describe writes a result as WDL and DTM, the distance in moves and plies.
+func describe(result tablebase.Result) string {
+	if result.WDL == tablebase.Draw {
+		return "draw"
+	}
+
+	return fmt.Sprintf("%s, mate in %d (DTM %d plies)", result.WDL, (result.DTM+1)/2, result.DTM)
+}
+
+func generateTables(args []string) error {
+	flags := flag.NewFlagSet("tb generate", flag.ContinueOnError)
+	dir := flags.String("dir", tablebase.DefaultDir(), "directory to write the tablebases to")
+	names, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = tablebase.Endgames
+	}
+
+	generated := make(map[string]*tablebase.Table)
+	var generate func(name string) (*tablebase.Table, error)
+	generate = func(name string) (*tablebase.Table, error) {
+		if table, ok := generated[name]; ok {
+			return table, nil
+		}
+
+		start := time.Now()
+		table, err := tablebase.Generate(name, func(required string) (*tablebase.Table, error) {
+			if table, err := tablebase.Load(*dir, required); err == nil {
+				return table, nil
+			}
+			return generate(required)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := table.Save(*dir); err != nil {
+			return nil, err
+		}
+
+		generated[name] = table
+		fmt.Printf("%s: longest mate %d moves, %s in %s\n", name, (table.Longest()+1)/2,
+			tablebase.Path(*dir, name), time.Since(start).Round(time.Millisecond))
+		return table, nil
+	}
+
+	for _, name := range names {
+		if _, err := generate(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
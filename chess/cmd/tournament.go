@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tournament"
+)
+
+const tournamentUsage = `usage: chess tournament new t.json --name NAME --system round-robin|swiss [--rounds 5]
+       chess tournament add t.json NAME [--rating 1500]
+       chess tournament pair t.json
+       chess tournament result t.json --board 1 (--result 1-0 | --game ID) [--round N]
+       chess tournament show t.json [--html crosstable.html]`
+
+// manageTournament implements "chess tournament": its subcommands create a
+// tournament file, register players, pair rounds, record results and show
+// the crosstable.
+func manageTournament(args []string) error {
+	subcommands := map[string]func(path string, args []string) error{
+		"new":    newTournament,
+		"add":    addTournamentPlayer,
+		"pair":   pairTournamentRound,
+		"result": recordTournamentResult,
+		"show":   showTournament,
+	}
+	if len(args) < 2 || subcommands[args[0]] == nil {
+		return errors.New(tournamentUsage)
+	}
+
+	return subcommands[args[0]](args[1], args[2:])
+}
+
+func newTournament(path string, args []string) error {
+	flags := flag.NewFlagSet("tournament new", flag.ContinueOnError)
+	name := flags.String("name", "Tournament", "name of the tournament")
+	system := flags.String("system", "swiss", "pairing system: round-robin or swiss")
+	rounds := flags.Int("rounds", 5, "rounds of a Swiss tournament")
+	if arguments, err := parseArguments(flags, args); err != nil || len(arguments) != 0 {
+		return errors.New(tournamentUsage)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	pairing, err := tournament.ParseSystem(*system)
+	if err != nil {
+		return err
+	}
+	created, err := tournament.New(*name, pairing, *rounds)
+	if err != nil {
+		return err
+	}
+
+	return created.Save(path)
+}
+
+func addTournamentPlayer(path string, args []string) error {
+	flags := flag.NewFlagSet("tournament add", flag.ContinueOnError)
+	rating := flags.Int("rating", 0, "rating of the player, used to rank Swiss pairings")
+	arguments, err := parseArguments(flags, args)
+	if err != nil || len(arguments) == 0 {
+		return errors.New(tournamentUsage)
+	}
+
+	current, err := tournament.Load(path)
+	if err != nil {
+		return err
+	}
+	if _, err := current.AddPlayer(strings.Join(arguments, " "), *rating); err != nil {
+		return err
+	}
+
+	return current.Save(path)
+}
+
+func pairTournamentRound(path string, args []string) error {
+	if len(args) != 0 {
+		return errors.New(tournamentUsage)
+	}
+
+	current, err := tournament.Load(path)
+	if err != nil {
+		return err
+	}
+	if _, err := current.NextRound(); err != nil {
+		return err
+	}
+	if err := current.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Round %d\n%s", len(current.Rounds), current.Pairings(len(current.Rounds)))
+	return nil
+}
+
+func recordTournamentResult(path string, args []string) error {
+	flags := flag.NewFlagSet("tournament result", flag.ContinueOnError)
+	round := flags.Int("round", 0, "round of the game, the last one by default")
+	number := flags.Int("board", 0, "board of the game")
+	result := flags.String("result", "", "result: 1-0, 0-1 or 1/2-1/2")
+	id := flags.String("game", "", "ID of a saved game to take the result from")
+	dir := flags.String("dir", storage.DefaultDir(), "directory of saved games")
+	arguments, err := parseArguments(flags, args)
+	if err != nil || len(arguments) != 0 || *number < 1 || (*result == "") == (*id == "") {
+		return errors.New(tournamentUsage)
+	}
+
+	current, err := tournament.Load(path)
+	if err != nil {
+		return err
+	}
+	if *round == 0 {
+		*round = len(current.Rounds)
+	}
+
+	if *id != "" {
+		err = recordStoredGame(current, *round, *number, *dir, *id)
+	} else {
+		err = current.Record(*round, *number, *result)
+	}
+	if err != nil {
+		return err
+	}
+	if err := current.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Round %d\n%s", *round, current.Pairings(*round))
+	return nil
+}
+
+// recordStoredGame records the result of a saved game, whose player names,
+// when it has them, must be those of the board.
+func recordStoredGame(current *tournament.Tournament, round, number int, dir, id string) error {
+	store, err := storage.NewFileStore(dir)
+	if err != nil {
+		return err
+	}
+	record, err := store.Load(id)
+	if err != nil {
+		return err
+	}
+
+	if round >= 1 && round <= len(current.Rounds) && number >= 1 && number <= len(current.Rounds[round-1].Pairings) {
+		pairing := current.Rounds[round-1].Pairings[number-1]
+		for _, side := range []struct{ recorded, player string }{{record.White, tournamentPlayer(current, pairing.White)}, {record.Black, tournamentPlayer(current, pairing.Black)}} {
+			if side.recorded != "" && !strings.EqualFold(side.recorded, side.player) {
+				return fmt.Errorf("game %s is %s - %s, not the game of board %d", id, record.White, record.Black, number)
+			}
+		}
+	}
+
+	chessGame, err := storage.Restore(record)
+	if err != nil {
+		return err
+	}
+
+	return current.RecordGame(round, number, chessGame)
+}
+
+func tournamentPlayer(current *tournament.Tournament, player int) string {
+	if player == tournament.Bye {
+		return "bye"
+	}
+
+	return current.Players[player].Name
+}
+
+func showTournament(path string, args []string) error {
+	flags := flag.NewFlagSet("tournament show", flag.ContinueOnError)
+	out := flags.String("html", "", "HTML file to write the crosstable to")
+	if arguments, err := parseArguments(flags, args); err != nil || len(arguments) != 0 {
+		return errors.New(tournamentUsage)
+	}
+
+	current, err := tournament.Load(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(current.Crosstable())
+	if *out != "" {
+		if err := os.WriteFile(*out, []byte(current.CrosstableHTML()), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("crosstable written to", *out)
+	}
+	return nil
+}
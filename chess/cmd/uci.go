@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/uci"
+)
+
+// serveUCI implements "chess uci": the engine answers a UCI GUI or match
+// runner on the standard input and output.
+func serveUCI(args []string) error {
+	flags := flag.NewFlagSet("uci", flag.ContinueOnError)
+	tables := flags.String("tablebases", tablebase.DefaultDir(), "directory of the endgame tablebases for the engine")
+	arguments, err := parseArguments(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(arguments) != 0 {
+		return errors.New("usage: chess uci [--tablebases DIR]")
+	}
+
+	return uci.Serve(os.Stdin, os.Stdout, tablebase.Open(*tables))
+}
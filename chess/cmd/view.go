@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+)
+
+type viewOptions struct {
+	style       *string
+	perspective *string
+	coordinates *bool
+	lastMove    *bool
+}
+
+// viewFlags registers the flags that choose how boards are printed.
+func viewFlags(flags *flag.FlagSet) viewOptions {
+	return viewOptions{
+		style:       flags.String("style", "", "board renderer: "+strings.Join(render.Names(), ", ")+"; the classic board when empty"),
+		perspective: flags.String("perspective", "auto", "side at the bottom: white, black or auto for the side to move"),
+		coordinates: flags.Bool("coords", true, "print files and ranks"),
+		lastMove:    flags.Bool("last-move", true, "highlight the last move"),
+	}
+}
+
+func (options viewOptions) apply(chessGame *game.ChessGame) error {
+	if *options.style == "" {
+		return nil
+	}
+
+	renderer, err := render.New(*options.style)
+	if err != nil {
+		return err
+	}
+
+	perspective, err := parsePerspective(*options.perspective)
+	if err != nil {
+		return err
+	}
+
+	chessGame.SetRenderer(renderer, game.View{
+		Perspective: perspective,
+		Coordinates: *options.coordinates,
+		LastMove:    *options.lastMove,
+	})
+	return nil
+}
+
+// parsePerspective returns Undecided for "auto".
+func parsePerspective(value string) (board.Team, error) {
+	if value == "auto" {
+		return board.Undecided, nil
+	}
+
+	team, err := board.ParseTeam(value)
+	if err != nil {
+		return board.Undecided, fmt.Errorf("bad perspective %q, expected white, black or auto", value)
+	}
+
+	return team, nil
+}
@@ -0,0 +1,3 @@
+package chessongolang
+
+const PATH = "playBook/initial.txt"
@@ -0,0 +1,313 @@
+// Package analysis reviews a played game with the engine. Every move is
+// compared with the best move of the position and classified by the
+// evaluation it gave away, and each player gets an average centipawn loss
+// and an accuracy.
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// Class rates a move by the evaluation it lost.
+type Class int
+
+const (
+	Best Class = iota
+	Good
+	Inaccuracy
+	Mistake
+	Blunder
+)
+
+// Centipawns lost from which a move is an inaccuracy, a mistake and a
+// blunder.
+const (
+	inaccuracyLoss = 50
+	mistakeLoss    = 100
+	blunderLoss    = 300
+)
+
+// evalCap bounds the evaluations the losses are taken from, so that a mate
+// found or missed in a won position does not count as thousands of
+// centipawns.
+const evalCap = 1000
+
+var classNames = []string{"Best", "Good", "Inaccuracy", "Mistake", "Blunder"}
+
+func (class Class) String() string {
+	return classNames[class]
+}
+
+// NAG returns the Numeric Annotation Glyph of the class: $6 (?!) for an
+// inaccuracy, $2 (?) for a mistake and $4 (??) for a blunder, 0 for the
+// others.
+func (class Class) NAG() int {
+	switch class {
+	case Inaccuracy:
+		return 6
+	case Mistake:
+		return 2
+	case Blunder:
+		return 4
+	}
+
+	return 0
+}
+
+// Move is the review of one move. Scores are in centipawns for the player
+// who made the move.
+type Move struct {
+	Number int
+	Team   board.Team
+	SAN    string
+	// Best is the move the engine prefers and BestLine the line that
+	// follows it, both in SAN.
+	Best     string
+	BestLine []string
+	// BestScore is the evaluation before the move, Score the one after it.
+	BestScore int
+	Score     int
+	Loss      int
+	Class     Class
+	// Accuracy is 100 for the best move and drops with the chances to win
+	// the move gave away.
+	Accuracy float64
+}
+
+// Player sums up the moves of one side.
+type Player struct {
+	Moves int
+	// ACPL is the average centipawn loss.
+	ACPL     float64
+	Accuracy float64
+	// Classes counts the moves of each class.
+	Classes [5]int
+}
+
+// Report is the review of a game.
+type Report struct {
+	Moves  []Move
+	White  Player
+	Black  Player
+	Limits engine.Limits
+}
+
+// Analyze searches every position of the main line with the limits.
+// progress, if not nil, is called after each position.
+func Analyze(game pgn.Game, limits engine.Limits, progress func(done, total int)) (Report, error) {
+	positions, err := game.Replay()
+	if err != nil {
+		return Report{}, err
+	}
+
+	// evaluations[i] is the search of positions[i] for the side to move.
+	evaluations := make([]engine.Result, len(positions))
+	for i, position := range positions {
+		team := toMove(positions, i)
+		result, err := engine.Search(position.Board, team, limits)
+		switch {
+		case errors.Is(err, engine.ErrNoMoves):
+			result = engine.Result{Score: finalScore(position.Board, team)}
+		case err != nil:
+			return Report{}, err
+		}
+		evaluations[i] = result
+
+		if progress != nil {
+			progress(i+1, len(positions))
+		}
+	}
+
+	report := Report{Limits: limits}
+	for i := 1; i < len(positions); i++ {
+		before, position := evaluations[i-1], positions[i]
+		move := Move{
+			Number:    position.Number,
+			Team:      position.Team,
+			SAN:       position.SAN,
+			BestScore: before.Score,
+			Score:     -evaluations[i].Score,
+		}
+
+		line := engine.LineSAN(positions[i-1].Board, position.Team, before.PV)
+		if len(line) > 0 {
+			move.Best, move.BestLine = line[0], line
+		}
+
+		// The search after the move may see further than the one before it,
+		// the best move loses nothing either way.
+		best := position.Command == before.Move
+		if !best {
+			move.Loss = max(capped(move.BestScore)-capped(move.Score), 0)
+		}
+		move.Class = classify(move, best)
+		move.Accuracy = accuracy(capped(move.BestScore), capped(move.BestScore)-move.Loss)
+
+		report.Moves = append(report.Moves, move)
+	}
+
+	report.White = summarize(report.Moves, board.White)
+	report.Black = summarize(report.Moves, board.Black)
+	return report, nil
+}
+
+// toMove returns the side to move in the position, Position.Team being the
+// side that made the move leading to it.
+func toMove(positions []pgn.Position, i int) board.Team {
+	if i == 0 {
+		return positions[0].Team
+	}
+
+	return positions[i].Team.Opponent()
+}
+
+// finalScore scores a position without moves for the side to move.
+func finalScore(b *board.Board, team board.Team) int {
+	if winner, _, over := b.VariantWinner(team); over {
+		switch winner {
+		case team:
+			return engine.Mate
+		case board.Undecided:
+			return 0
+		default:
+			return -engine.Mate
+		}
+	}
+	if b.InCheck(team) {
+		return -engine.Mate
+	}
+
+	return 0
+}
+
+func classify(move Move, best bool) Class {
+	switch {
+	case best:
+		return Best
+	case move.Loss >= blunderLoss:
+		return Blunder
+	case move.Loss >= mistakeLoss:
+		return Mistake
+	case move.Loss >= inaccuracyLoss:
+		return Inaccuracy
+	default:
+		return Good
+	}
+}
+
+func capped(score int) int {
+	return min(max(score, -evalCap), evalCap)
+}
+
+// winChance turns an evaluation into the chance to win in percent.
+func winChance(score int) float64 {
+	return 50 + 50*(2/(1+math.Exp(-0.00368208*float64(score)))-1)
+}
+
+// accuracy of a move from the chances to win it gave away, on the curve
+// used by lichess.
+func accuracy(before, after int) float64 {
+	lost := max(winChance(before)-winChance(after), 0)
+	return min(max(103.1668*math.Exp(-0.04354*lost)-3.1669, 0), 100)
+}
+
+func summarize(moves []Move, team board.Team) Player {
+	var player Player
+	var loss, accuracy float64
+
+	for _, move := range moves {
+		if move.Team != team {
+			continue
+		}
+		player.Moves++
+		player.Classes[move.Class]++
+		loss += float64(move.Loss)
+		accuracy += move.Accuracy
+	}
+
+	if player.Moves > 0 {
+		player.ACPL = loss / float64(player.Moves)
+		player.Accuracy = accuracy / float64(player.Moves)
+	}
+
+	return player
+}
+
+// Annotate returns the game with the review written into it: the NAG of
+// every inaccuracy, mistake and blunder, the evaluation after each move as
+// an [%eval] command from White's view, and the best line as a variation
+// where a better move was missed.
+func (report Report) Annotate(game pgn.Game) pgn.Game {
+	annotated := game
+	annotated.Moves = append([]pgn.Move(nil), game.Moves...)
+
+	for i, review := range report.Moves {
+		if i >= len(annotated.Moves) {
+			break
+		}
+		move := &annotated.Moves[i]
+
+		if nag := review.Class.NAG(); nag != 0 {
+			move.NAGs = append(append([]int(nil), move.NAGs...), nag)
+		}
+
+		// The position after a mate has nothing left to evaluate.
+		comment := ""
+		if review.Score != engine.Mate {
+			comment = fmt.Sprintf("[%%eval %s]", whiteEval(review.Score, review.Team))
+		}
+		if review.Class >= Inaccuracy && review.Best != "" {
+			comment += fmt.Sprintf(" %s. %s was best.", review.Class, review.Best)
+			move.Variations = append(append([][]pgn.Move(nil), move.Variations...), variation(review.BestLine))
+		}
+		move.Comment = strings.TrimSpace(comment + " " + move.Comment)
+	}
+
+	annotated.Comment = strings.TrimSpace(game.Comment + " " + report.Summary())
+	return annotated
+}
+
+// Summary writes the average centipawn loss and the accuracy of both
+// players and their inaccuracies, mistakes and blunders.
+func (report Report) Summary() string {
+	limits := fmt.Sprintf("depth %d", report.Limits.Depth)
+	if report.Limits.Time > 0 {
+		limits += fmt.Sprintf(", at most %s a move", report.Limits.Time)
+	}
+
+	return fmt.Sprintf("Analysis at %s. White: %s. Black: %s.", limits, report.White, report.Black)
+}
+
+func (player Player) String() string {
+	return fmt.Sprintf("ACPL %.0f, accuracy %.1f%%, %d inaccuracies, %d mistakes, %d blunders",
+		player.ACPL, player.Accuracy, player.Classes[Inaccuracy], player.Classes[Mistake], player.Classes[Blunder])
+}
+
+// whiteEval writes a score of the team from White's view in the form of
+// the [%eval] command, pawns or # and the moves to mate.
+func whiteEval(score int, team board.Team) string {
+	if team == board.Black {
+		score = -score
+	}
+
+	if moves, ok := engine.MateIn(score); ok {
+		return fmt.Sprintf("#%d", moves)
+	}
+	return fmt.Sprintf("%.2f", float64(score)/100)
+}
+
+func variation(line []string) []pgn.Move {
+	moves := make([]pgn.Move, len(line))
+	for i, san := range line {
+		moves[i] = pgn.Move{SAN: san}
+	}
+
+	return moves
+}
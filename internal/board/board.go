@@ -2,30 +2,50 @@ package board
 
 import (
 	"bytes"
+	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/DmitriyKolesnikM8O/chess_on_golang/pkg/utils"
 )
 
 func NewBoard() *Board {
+	return newBoard(boardSize, boardSize)
+}
+
+func newBoard(files, ranks int) *Board {
 	board := new(Board)
-	squares := make([][]Square, boardSize)
+	board.files = files
+	board.ranks = ranks
+	squares := make([][]Square, ranks)
 
-	for i := 0; i < boardSize; i++ {
-		squares[i] = make([]Square, boardSize)
-		for j := 0; j < boardSize; j++ {
+	for i := 0; i < ranks; i++ {
+		squares[i] = make([]Square, files)
+		for j := 0; j < files; j++ {
 			squares[i][j] = Square{
-				row:   i,
-				col:   j,
-				piece: nil,
+				row:      i,
+				col:      j,
+				position: board.position(i, j),
+				piece:    nil,
 			}
 		}
 	}
 	board.squares = squares
+	board.fullmoveNumber = 1
 	return board
 
 }
 
+// Files returns the number of columns of the board.
+func (board Board) Files() int {
+	return board.files
+}
+
+// Ranks returns the number of rows of the board.
+func (board Board) Ranks() int {
+	return board.ranks
+}
+
 func (board *Board) Setup(testCase utils.TestCase) {
 	for _, id := range testCase.InitialPositions {
 		board.initPiece(id.Position, id.Sign)
@@ -33,6 +53,7 @@ func (board *Board) Setup(testCase utils.TestCase) {
 
 	board.blackCaptures = testCase.BlackCapture
 	board.whiteCaptures = testCase.WhiteCapture
+	board.castling = board.homeCastlingRights()
 }
 
 func (board *Board) initPiece(position string, sign string) {
@@ -46,15 +67,7 @@ func (board *Board) initPiece(position string, sign string) {
 }
 
 func (board Board) GetSquare(position string) *Square {
-	col := int(position[0] - 'a')
-	if col < 0 || col >= boardSize {
-		return nil
-	}
-	row := ((int)(position[1]-'0'))*-1 + boardSize
-	if row < 0 || row >= boardSize {
-		return nil
-	}
-	return &board.squares[row][col]
+	return board.getSquare(position)
 }
 
 func (square Square) hasPiece() bool {
@@ -85,10 +98,10 @@ func (square *Square) SetPiece(piece *Piece) {
 func (board *Board) String() string {
 	var buffer bytes.Buffer
 
-	boardString := make([][]string, boardSize)
-	for i := 0; i < boardSize; i++ {
-		boardString[i] = make([]string, boardSize)
-		for j := 0; j < boardSize; j++ {
+	boardString := make([][]string, board.ranks)
+	for i := 0; i < board.ranks; i++ {
+		boardString[i] = make([]string, board.files)
+		for j := 0; j < board.files; j++ {
 			boardString[i][j] = board.squares[i][j].String()
 		}
 	}
@@ -128,60 +141,55 @@ func (piece Piece) String() string {
 	return getPieceSymbol(piece.sign)
 }
 
-func getPieceSymbol(sign string) string {
-	switch sign {
-	case "k":
-		return WhiteKing
-	case "K":
-		return BlackKing
-	case "q":
-		return WhiteQueen
-	case "Q":
-		return BlackQueen
-	case "r":
-		return WhiteRook
-	case "R":
-		return BlackRook
-	case "b":
-		return WhiteBishop
-	case "B":
-		return BlackBishop
-	case "n":
-		return WhiteKnight
-	case "N":
-		return BlackKnight
-	case "p":
-		return WhitePawn
-	case "P":
-		return BlackPawn
+// Symbol returns the Unicode chess glyph of a piece sign.
+func Symbol(sign string) string {
+	return getPieceSymbol(sign)
+}
 
-	default:
+func getPieceSymbol(sign string) string {
+	kind, ok := pieceTypes[strings.ToLower(sign)]
+	if !ok {
 		panic("Cannot Print Piece. Unknown Sign:" + sign)
 	}
+
+	symbol := kind.symbols[0]
+	if sign == strings.ToUpper(sign) {
+		symbol = kind.symbols[1]
+	}
+	if symbol == "" {
+		// Fairy pieces have no glyph and are shown by their FEN letter.
+		return FENLetter(sign)
+	}
+
+	return symbol
 }
 
 func (board *Board) Execute(command string, team Team) bool {
+	tokens := strings.Split(command, " ")
+
+	// Check the movePiece first, panic if it's illegal movePiece on board
+	var move Move
+	switch {
+	case len(tokens) == 1 && strings.Contains(command, "@"):
+		move = board.checkDrop(command, team)
+	case len(tokens) == 2:
+		move = board.checkMove(tokens[0], tokens[1], "", team)
+	case len(tokens) == 3:
+		move = board.checkMove(tokens[0], tokens[1], tokens[2], team)
+	default:
+		panic(illegalMoveMessage)
+	}
+
 	// Handle "in check situation" first
 	if board.InCheck(team) {
 		validMoves := board.GetAvailableMovesInCheck(team)
-		if !containsMove(validMoves, command) {
+		if !containsMove(validMoves, move.command()) {
 			panic(illegalMoveMessage)
 		}
 	}
 
-	tokens := strings.Split(command, " ")
-	if len(tokens) != 2 {
-		panic(illegalMoveMessage)
-	}
-
-	// Check the movePiece first, panic if it's illegal movePiece on board
-	move := board.checkMove(tokens[0], tokens[1], team)
-
-	// Move Piece
-	board.movePiece(move.piece, move.squareFrom, move.squareTo)
-
-	//Promote
-	//TODO: Handle promotion for Pawn
+	// Move Piece, including the rook of a castling, the pawn taken en passant and promotion
+	board.makeMove(move, team)
 
 	// return if the opponent team is in checkmate
 	return board.inCheckmate(getOpponentTeam(team))
@@ -194,16 +202,46 @@ func (board Board) inCheckmate(curTeam Team) bool {
 type Move struct {
 	piece                *Piece
 	squareFrom, squareTo *Square
+
+	// promotion is the sign the pawn turns into.
+	promotion string
+	// rookFrom and rookTo are set for castling.
+	rookFrom, rookTo *Square
+	// kingTakesRook writes a castling with the square of the rook, as in
+	// Chess960 where the king may move a single square or not at all.
+	kingTakesRook bool
+	// captureSquare holds the pawn taken en passant.
+	captureSquare *Square
+	// squareFrom is nil for a piece dropped from the pocket.
 }
 
-func (board Board) checkMove(origin, destination string, team Team) Move {
+// command returns the move in the form accepted by Execute.
+func (move Move) command() string {
+	if move.isDrop() {
+		return strings.ToUpper(move.piece.sign) + "@" + getSquarePosition(*move.squareTo)
+	}
+
+	squareTo := move.squareTo
+	if move.kingTakesRook {
+		squareTo = move.rookFrom
+	}
+
+	command := getSquarePosition(*move.squareFrom) + " " + getSquarePosition(*squareTo)
+	if move.promotion != "" {
+		command += " " + strings.ToLower(move.promotion)
+	}
+
+	return command
+}
+
+func (board Board) checkMove(origin, destination, promotion string, team Team) Move {
 
 	//Check input positions
-	squareFrom := board.getSquare(origin)
+	squareFrom := board.getSquareSafe(origin)
 	if squareFrom == nil {
 		panic(illegalMoveMessage)
 	}
-	squareTo := board.getSquare(destination)
+	squareTo := board.getSquareSafe(destination)
 	if squareTo == nil {
 		panic(illegalMoveMessage)
 	}
@@ -213,6 +251,13 @@ func (board Board) checkMove(origin, destination string, team Team) Move {
 
 	}
 
+	//Castling is checked completely on its own
+	if isKing(*piece) {
+		if move, ok := board.castlingMove(squareFrom, squareTo, team); ok {
+			return move
+		}
+	}
+
 	//Check if the piece's movement is valid
 	moves := getMoves(board, *piece)
 	if !containsMove(moves, destination) {
@@ -220,48 +265,69 @@ func (board Board) checkMove(origin, destination string, team Team) Move {
 
 	}
 
+	move := board.newMove(piece, squareFrom, squareTo)
+	if err := board.setPromotion(&move, promotion, team); err != nil {
+		panic(illegalMoveMessage)
+	}
+	if !board.rules().allows(board, move, team) {
+		panic(illegalMoveMessage)
+	}
+
 	//Check if causing self in check (considered as invalid movePiece in current rule)
-	if board.moveWillCauseSelfCheck(origin, destination, team) {
+	if !board.rules().legal(board, move, team) {
 		panic(causingSelfInCheckMessage)
 	}
 
-	return Move{piece, squareFrom, squareTo}
+	return move
 
 }
 
 func (board Board) GetAvailableMovesInCheck(current Team) []string {
-	var moves []string
+	return board.LegalMoves(current)
+}
 
-	moves = append(moves, board.getAvailableMovesByMovingKing(current)...)
-	moves = append(moves, board.getAvailableMovesByMovingOtherPieces(current)...)
+// LegalMoves returns every move the team can play, in the "e2 e4" form
+// accepted by Execute. Promotions carry the piece letter, e.g. "e7 e8 q".
+func (board Board) LegalMoves(current Team) []string {
+	var moves []string
+	for _, move := range board.legalMoves(current) {
+		moves = append(moves, move.command())
+	}
 
 	return moves
 }
 
-func (board Board) getAvailableMovesByMovingOtherPieces(current Team) []string {
-	var moves []string
-	threatenKingPieces := board.getThreateningKingPieces(current)
-	if len(threatenKingPieces) != 1 {
-		return moves
-	}
+func (board Board) legalMoves(current Team) []Move {
+	var moves []Move
 
-	threatenPiece := threatenKingPieces[0]
-	threatenPosition := getCoordinatePosition(threatenPiece.row, threatenPiece.col)
+	for _, piece := range board.getAllPiece(current) {
+		squareFrom := &board.squares[piece.row][piece.col]
 
-	for _, ownPiece := range board.getAllPiece(current) {
-		if isKing(ownPiece) {
-			continue //king's move was already considered in method getAvailableMovesByMovingKing()
-		}
-		positionFrom := getCoordinatePosition(ownPiece.row, ownPiece.col)
+		for _, positionTo := range getMoves(board, piece) {
+			move := board.newMove(squareFrom.piece, squareFrom, board.getSquare(positionTo))
+			if !board.isLegal(move, current) {
+				continue
+			}
 
-		for _, positionTo := range getMoves(board, ownPiece) {
-			if !board.moveWillCauseSelfCheck(positionFrom, positionTo, current) {
-				moves = append(moves, positionFrom+" "+threatenPosition)
+			if !board.isPromotion(move) {
+				moves = append(moves, move)
+				continue
+			}
+			for _, letter := range board.rules().promotions() {
+				promotion := move
+				board.setPromotion(&promotion, letter, current)
+				moves = append(moves, promotion)
 			}
 		}
 	}
 
-	return moves
+	moves = append(moves, board.castlingMoves(current)...)
+	return append(moves, board.dropMoves(current)...)
+}
+
+// InStalemate reports whether the team is not in check but has no legal move.
+func (board Board) InStalemate(current Team) bool {
+	return !board.InCheck(current) && len(board.LegalMoves(current)) == 0
 }
 
 func (board Board) moveWillCauseSelfCheck(positionFrom, positionTo string, team Team) bool {
@@ -283,6 +349,28 @@ func (board Board) moveWillCauseSelfCheck(positionFrom, positionTo string, team
 	return selfInCheck
 }
 
+// leavesKingInCheck is moveWillCauseSelfCheck that also lifts the pawn taken
+// en passant.
+func (board Board) leavesKingInCheck(move Move, team Team) bool {
+	if move.isDrop() {
+		move.squareTo.setPiece(move.piece)
+		selfInCheck := board.InCheck(team)
+		move.squareTo.setPiece(nil)
+		return selfInCheck
+	}
+
+	if move.captureSquare == nil {
+		return board.moveWillCauseSelfCheck(getSquarePosition(*move.squareFrom), getSquarePosition(*move.squareTo), team)
+	}
+
+	captured := move.captureSquare.piece
+	move.captureSquare.setPiece(nil)
+	selfInCheck := board.moveWillCauseSelfCheck(getSquarePosition(*move.squareFrom), getSquarePosition(*move.squareTo), team)
+	move.captureSquare.setPiece(captured)
+
+	return selfInCheck
+}
+
 func (board *Board) movePiece(piece *Piece, squareFrom, squareTo *Square) {
 
 	capturedPiece := squareTo.piece
@@ -304,9 +392,24 @@ func (square *Square) setPiece(piece *Piece) {
 	square.piece = piece
 }
 
+func (board *Board) advanceMoveCounters(piece Piece, capture bool, team Team) {
+	if capture || isPawn(piece) {
+		board.halfmoveClock = 0
+	} else {
+		board.halfmoveClock++
+	}
+
+	if team == Black {
+		board.fullmoveNumber++
+	}
+}
+
 func (board *Board) captured(capturedPiece Piece) {
 	team := getOpponentTeam(capturedPiece.team)
 	sign := capturedPiece.sign //board will print the symbols from piece.sign
+	if capturedPiece.promoted && board.rules().pockets() {
+		sign = teamSign("p", capturedPiece.team)
+	}
 	switch team {
 	case White:
 		// panic("TEST")
@@ -318,36 +421,11 @@ func (board *Board) captured(capturedPiece Piece) {
 }
 
 func isKing(piece Piece) bool {
-	symbol := getPieceSymbol(piece.sign)
-	return symbol == WhiteKing || symbol == BlackKing
+	return piece.sign == "k" || piece.sign == "K"
 }
 
-func (board Board) getThreateningKingPieces(current Team) []Piece {
-	var threatenPieces []Piece
-	kingPosition := board.getKingPosition(current)
-	for _, opponentPiece := range board.getAllPiece(getOpponentTeam(current)) {
-		opponentMoves := getMoves(board, opponentPiece)
-		if containsMove(opponentMoves, kingPosition) {
-			threatenPieces = append(threatenPieces, opponentPiece)
-		}
-	}
-	return threatenPieces
-}
-
-func (board Board) getAvailableMovesByMovingKing(current Team) []string {
-	var moves []string
-	kingPosition := board.getKingPosition(current)
-	kingPiece := board.GetSquare(kingPosition).piece
-
-	opponentsMoves := board.getReachablePositions(getOpponentTeam(current))
-
-	for _, kingMove := range getMoves(board, *kingPiece) {
-		if !containsMove(opponentsMoves, kingMove) {
-			moves = append(moves, kingPosition+" "+kingMove)
-		}
-	}
-
-	return moves
+func isPawn(piece Piece) bool {
+	return piece.sign == "p" || piece.sign == "P"
 }
 
 func containsMove(moves []string, move string) bool {
@@ -359,14 +437,26 @@ func containsMove(moves []string, move string) bool {
 	return false
 }
 
+// InCheck reports whether the king of the team is attacked, as the variant
+// of the board defines it. A team without a king is never in check.
 func (board Board) InCheck(current Team) bool {
-	kingPosition := board.getKingPosition(current)
+	return board.rules().inCheck(board, current)
+}
 
-	opponentPosition := board.getReachablePositions(getOpponentTeam(current))
+func (board Board) isKingAttacked(current Team) bool {
+	king := board.kingSquare(current)
+	if king == nil {
+		return false
+	}
 
-	for _, position := range opponentPosition {
-		if kingPosition == position {
-			return true
+	// Scanning the squares spares the slice of getAllPiece, this runs for
+	// every move that is checked for legality.
+	opponent := getOpponentTeam(current)
+	for i := range board.squares {
+		for _, square := range board.squares[i] {
+			if square.piece != nil && square.piece.team == opponent && board.attacks(*square.piece, king.row, king.col) {
+				return true
+			}
 		}
 	}
 
@@ -383,33 +473,14 @@ func (board Board) getReachablePositions(current Team) []string {
 	return moves
 }
 
+// getMoves returns the squares the piece can move to, pawns by their own
+// rules and every other piece by its entry in pieceTypes.
 func getMoves(board Board, piece Piece) []string {
-
-	row := piece.row
-	col := piece.col
-	team := piece.team
-
-	switch piece.String() {
-	case WhiteKing, BlackKing:
-		return getKingMoves(row, col, board, team)
-	case WhiteQueen, BlackQueen:
-		return getQueenMoves(row, col, board, team)
-
-	case WhiteRook, BlackRook:
-		return getRookMoves(row, col, board, team)
-
-	case WhiteBishop, BlackBishop:
-		return getBishopMovesAt(row, col, board, team)
-
-	case WhiteKnight, BlackKnight:
-		return getKnightMoves(row, col, board, team)
-
-	case WhitePawn, BlackPawn:
-		return getPawnMoves(row, col, board, team)
-
-	default:
-		panic("piece.String() hasn't been defined: " + piece.String())
+	if isPawn(piece) {
+		return getPawnMoves(piece.row, piece.col, board, piece.team)
 	}
+
+	return board.pieceMoves(piece, false)
 }
 
 func getPawnMoves(row, col int, board Board, team Team) []string {
@@ -425,287 +496,274 @@ func getPawnMoves(row, col int, board Board, team Team) []string {
 	case White:
 		oneStepRow--
 		twoStepsRow -= 2
-		firstMove = row == boardSize-2
 	case Black:
 		oneStepRow++
 		twoStepsRow += 2
-		firstMove = row == 1
 	}
+	firstMove = board.rules().canDoubleStep(board, row, team)
 
 	//Get one step forwards positions
-	position := getCoordinatePosition(oneStepRow, col)
+	position := board.position(oneStepRow, col)
 	if board.isEmptyAt(position) {
 		moves = append(moves, position)
 	}
 
 	//Get two step forwards positions if it's first move
-	position = getCoordinatePosition(twoStepsRow, col)
-	if firstMove && board.isEmptyAt(position) {
+	blocked := !board.isEmptyAt(position)
+	position = board.position(twoStepsRow, col)
+	if firstMove && !blocked && board.isEmptyAt(position) {
 		moves = append(moves, position)
 	}
 
-	//Get Two Killing positions if there is enemy nearby to kill
-	position = getCoordinatePosition(oneStepRow, col-1)
-	if board.canMoveTo(position, team) {
+	//Get Two Killing positions if there is enemy nearby to kill, or the en passant square
+	position = board.position(oneStepRow, col-1)
+	if board.canMoveTo(position, team) && (!board.isEmptyAt(position) || position == board.enPassant) {
 		moves = append(moves, position)
 	}
-	position = getCoordinatePosition(oneStepRow, col+1)
-	if board.canMoveTo(position, team) {
+	position = board.position(oneStepRow, col+1)
+	if board.canMoveTo(position, team) && (!board.isEmptyAt(position) || position == board.enPassant) {
 		moves = append(moves, position)
 	}
 
 	return moves
 }
 
-func getKnightMoves(row, col int, board Board, team Team) []string {
-	var moves []string
+func (board Board) isEmptyAt(position string) bool {
+	square := board.getSquare(position)
+	return square != nil && !square.hasPiece()
+}
 
-	for i := -2; i <= 2; i++ {
-		if i == 0 {
-			continue
-		}
-		var j int
-		//up
-		if i == -2 || i == 2 {
-			j = 1
-		} else {
-			j = 2
-		}
-		leftward := getCoordinatePosition(row+i, col-j)
-		if board.canMoveTo(leftward, team) {
-			moves = append(moves, leftward)
-		}
-		rightward := getCoordinatePosition(row+i, col+j)
-		if board.canMoveTo(rightward, team) {
-			moves = append(moves, rightward)
-		}
+// getSquareSafe is getSquare for untrusted input of any length.
+func (board Board) getSquareSafe(position string) *Square {
+	if len(position) < 2 || len(position) > 3 {
+		return nil
 	}
 
-	return moves
+	return board.getSquare(position)
 }
 
-func getQueenMoves(row, col int, board Board, team Team) []string {
-	var moves []string
-	moves = append(moves, getRookMoves(row, col, board, team)...)
-	moves = append(moves, getBishopMovesAt(row, col, board, team)...)
-
-	return moves
-}
-
-func getBishopMovesAt(row, col int, board Board, team Team) []string {
-
-	var moves []string
-
-	//Move Top Left
-	for i, j := row-1, col-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
-		position := getCoordinatePosition(i, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
-		}
+// getSquare reads a position such as "e4" or "j10", nil if it is not on the
+// board.
+func (board Board) getSquare(position string) *Square {
+	if len(position) < 2 {
+		return nil
 	}
-
-	//Move Top Right
-	for i, j := row-1, col+1; i >= 0 && j < boardSize; i, j = i-1, j+1 {
-		position := getCoordinatePosition(i, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
+	col := int(position[0] - 'a')
+	if col < 0 || col >= board.files {
+		return nil
+	}
+	rank := 0
+	for _, r := range position[1:] {
+		if r < '0' || r > '9' {
+			return nil
 		}
+		rank = rank*10 + int(r-'0')
 	}
+	row := board.ranks - rank
+	if row < 0 || row >= board.ranks {
+		return nil
+	}
+	return &board.squares[row][col]
+}
 
-	//Move Bottom Left
-	for i, j := row+1, col-1; i < boardSize && j >= 0; i, j = i+1, j-1 {
-		position := getCoordinatePosition(i, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
-		}
+func (board Board) canMoveTo(position string, team Team) bool {
+	square := board.GetSquare(position)
+	if square == nil {
+		return false
 	}
+	piece := square.GetPiece()
+	return piece == nil || piece.team != team
+}
 
-	//Move Bottom Right
-	for i, j := row+1, col+1; i < boardSize && j < boardSize; i, j = i+1, j+1 {
-		position := getCoordinatePosition(i, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
+func (board Board) getAllPiece(current Team) []Piece {
+	var pieces []Piece
+	for i := 0; i < board.ranks; i++ {
+		for j := 0; j < board.files; j++ {
+			piece := board.squares[i][j].GetPiece()
+			if piece != nil && piece.team == current {
+				pieces = append(pieces, *piece)
+			}
 		}
 	}
 
-	return moves
+	return pieces
 }
 
-func getRookMoves(row, col int, board Board, team Team) []string {
-	var moves []string
-
-	//left
-	for j := col - 1; j >= 0; j-- {
-		position := getCoordinatePosition(row, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
-		}
+func getOpponentTeam(current Team) Team {
+	if current == White {
+		return Black
 	}
 
-	//Moving Right
-	for j := col + 1; j < boardSize; j++ {
-		position := getCoordinatePosition(row, j)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
+	return White
+}
+
+func (board Board) getKingPosition(current Team) string {
+	for i := 0; i < board.ranks; i++ {
+		for j := 0; j < board.files; j++ {
+			square := board.squares[i][j]
+			piece := square.GetPiece()
+			if piece != nil && piece.team == current && isKing(*piece) {
+				return getSquarePosition(square)
+			}
 		}
 	}
 
-	//Moving Up
-	for i := row - 1; i >= 0; i-- {
-		position := getCoordinatePosition(i, col)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
+	panic("Cannot find king in the board")
+}
+
+// kingSquare returns the square of the king of the team, nil if it has none.
+func (board Board) kingSquare(current Team) *Square {
+	for i := range board.squares {
+		for j := range board.squares[i] {
+			if piece := board.squares[i][j].piece; piece != nil && piece.team == current && isKing(*piece) {
+				return &board.squares[i][j]
+			}
 		}
 	}
 
-	//Moving Down
-	for i := row + 1; i < boardSize; i++ {
-		position := getCoordinatePosition(i, col)
-		if board.isEmptyAt(position) {
-			moves = append(moves, position)
-		} else if board.canMoveTo(position, team) {
-			moves = append(moves, position)
-			break
-		} else {
-			break
+	return nil
+}
+
+func (board Board) hasKing(current Team) bool {
+	for _, piece := range board.getAllPiece(current) {
+		if isKing(piece) {
+			return true
 		}
 	}
 
-	return moves
+	return false
 }
 
-func (board Board) isEmptyAt(position string) bool {
-	square := board.getSquare(position)
-	return square != nil && !square.hasPiece()
+func (square Square) GetPiece() *Piece {
+	return square.piece
 }
 
-func (board Board) getSquare(position string) *Square {
-	col := int(position[0] - 'a')
-	if col < 0 || col >= boardSize {
-		return nil
-	}
-	row := ((int)(position[1]-'0'))*-1 + boardSize
-	if row < 0 || row >= boardSize {
-		return nil
+func getSquarePosition(square Square) string {
+	return square.position
+}
+
+// position names the square at the row and column, e.g. "e4", and returns
+// an empty string off the board.
+func (board Board) position(row, col int) string {
+	if !board.onBoard(row, col) {
+		return ""
 	}
-	return &board.squares[row][col]
+
+	return string(rune('a'+col)) + strconv.Itoa(board.ranks-row)
 }
 
-func getKingMoves(row, col int, board Board, team Team) []string {
+func (board Board) onBoard(row, col int) bool {
+	return row >= 0 && row < board.ranks && col >= 0 && col < board.files
+}
 
-	var moves []string
+// Clone returns a deep copy of the board, so that it can be changed without
+// touching the original.
+func (board Board) Clone() *Board {
+	clone := newBoard(board.files, board.ranks)
 
-	for i := -1; i <= 1; i++ {
-		for j := -1; j <= 1; j++ {
-			if i == 0 && j == 0 {
-				continue
-			}
-			position := getCoordinatePosition(row+i, col+j)
-			if board.canMoveTo(position, team) {
-				moves = append(moves, position)
+	for i := 0; i < board.ranks; i++ {
+		for j := 0; j < board.files; j++ {
+			if piece := board.squares[i][j].GetPiece(); piece != nil {
+				copied := *piece
+				clone.squares[i][j].setPiece(&copied)
 			}
 		}
 	}
 
-	return moves
+	clone.whiteCaptures = append([]string(nil), board.whiteCaptures...)
+	clone.blackCaptures = append([]string(nil), board.blackCaptures...)
+	clone.halfmoveClock = board.halfmoveClock
+	clone.fullmoveNumber = board.fullmoveNumber
+	clone.enPassant = board.enPassant
+	clone.chess960 = board.chess960
+	clone.variant = board.variant
+	clone.checks = board.checks
+	clone.castling = make(map[Team][2]int, len(board.castling))
+	for team, rooks := range board.castling {
+		clone.castling[team] = rooks
+	}
+
+	return clone
 }
 
-func (board Board) canMoveTo(position string, team Team) bool {
-	square := board.GetSquare(position)
-	if square == nil {
-		return false
-	}
-	piece := square.GetPiece()
-	return piece == nil || piece.team != team
+// NullMove returns a copy of the board as it would be if the side to move
+// passed, which only takes away the en passant capture.
+func (board Board) NullMove() *Board {
+	clone := board.Clone()
+	clone.enPassant = ""
+
+	return clone
 }
 
-func (board Board) getAllPiece(current Team) []Piece {
-	var pieces []Piece
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			piece := board.squares[i][j].GetPiece()
-			if piece != nil && piece.team == current {
-				pieces = append(pieces, *piece)
+// Grid returns the piece signs by rows, starting from the last rank. Empty
+// squares are empty strings.
+func (board Board) Grid() [][]string {
+	grid := make([][]string, board.ranks)
+	for i := 0; i < board.ranks; i++ {
+		grid[i] = make([]string, board.files)
+		for j := 0; j < board.files; j++ {
+			if piece := board.squares[i][j].GetPiece(); piece != nil {
+				grid[i][j] = piece.sign
 			}
 		}
 	}
 
-	return pieces
+	return grid
 }
 
-func getOpponentTeam(current Team) Team {
-	if current == White {
-		return Black
-	}
-
-	return White
+// HalfmoveClock returns the number of moves by either side since the last
+// capture or pawn move, for the fifty-move rule.
+func (board Board) HalfmoveClock() int {
+	return board.halfmoveClock
 }
 
-func (board Board) getKingPosition(current Team) string {
-	var kingSymbol string
+func (board Board) WhiteCaptures() []string {
+	return nonEmptySigns(board.whiteCaptures)
+}
 
-	if current == White {
-		kingSymbol = WhiteKing
-	} else {
-		kingSymbol = BlackKing
-	}
+func (board Board) BlackCaptures() []string {
+	return nonEmptySigns(board.blackCaptures)
+}
 
-	for i := 0; i < boardSize; i++ {
-		for j := 0; j < boardSize; j++ {
-			square := board.squares[i][j]
-			piece := square.GetPiece()
-			if piece != nil && piece.String() == kingSymbol {
-				return getSquarePosition(square)
-			}
+func nonEmptySigns(signs []string) []string {
+	result := []string{}
+	for _, sign := range signs {
+		if sign != "" {
+			result = append(result, sign)
 		}
 	}
 
-	panic("Cannot find king in the board")
+	return result
 }
 
-func (square Square) GetPiece() *Piece {
-	return square.piece
+func (team Team) String() string {
+	switch team {
+	case White:
+		return "white"
+	case Black:
+		return "black"
+	default:
+		return "undecided"
+	}
 }
 
-func getSquarePosition(square Square) string {
-	return getCoordinatePosition(square.row, square.col)
+// ParseTeam accepts "w"/"white" and "b"/"black" in any case.
+func ParseTeam(s string) (Team, error) {
+	switch strings.ToLower(s) {
+	case "w", "white":
+		return White, nil
+	case "b", "black":
+		return Black, nil
+	default:
+		return Undecided, errors.New("unknown team " + s)
+	}
+}
+
+// Opponent returns the other team.
+func (team Team) Opponent() Team {
+	return getOpponentTeam(team)
 }
 
-func getCoordinatePosition(row, col int) string {
-	return (string)('a'+col) + (string)('0'-row+boardSize)
+func (piece Piece) Team() Team {
+	return piece.team
 }
@@ -0,0 +1,82 @@
+package board
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Chess960Positions is the number of Fischer Random start positions.
+const Chess960Positions = 960
+
+// Chess960StandardIndex is the number of the standard start position.
+const Chess960StandardIndex = 518
+
+// knightPlacements lists the squares, among the five left after bishops and
+// queen, taken by the knights in the Scharnagl numbering.
+var knightPlacements = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+// Chess960FEN returns the start position with the given number, 0 to 959,
+// in the usual Scharnagl numbering where 518 is the standard position.
+func Chess960FEN(index int) (string, error) {
+	if index < 0 || index >= Chess960Positions {
+		return "", fmt.Errorf("chess960: position %d is not between 0 and %d", index, Chess960Positions-1)
+	}
+
+	rank := make([]string, boardSize)
+	n := index
+
+	rank[2*(n%4)+1] = "b"
+	n /= 4
+	rank[2*(n%4)] = "b"
+	n /= 4
+
+	empty := func() []int {
+		var cols []int
+		for col, letter := range rank {
+			if letter == "" {
+				cols = append(cols, col)
+			}
+		}
+		return cols
+	}
+
+	rank[empty()[n%6]] = "q"
+	n /= 6
+
+	cols := empty()
+	for _, i := range knightPlacements[n] {
+		rank[cols[i]] = "n"
+	}
+
+	// The rooks stand on both sides of the king.
+	for i, col := range empty() {
+		rank[col] = []string{"r", "k", "r"}[i]
+	}
+
+	black := strings.Join(rank, "")
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1", black, strings.ToUpper(black)), nil
+}
+
+// RandomChess960FEN returns one of the 960 start positions at random with
+// its number.
+func RandomChess960FEN() (string, int) {
+	index := rand.Intn(Chess960Positions)
+	fen, _ := Chess960FEN(index)
+
+	return fen, index
+}
+
+// Chess960 reports whether castlings are written as the king taking its
+// rook.
+func (board Board) Chess960() bool {
+	return board.chess960
+}
+
+// SetChess960 switches to Chess960 notation of castlings and castling
+// rights. The rules are the same, standard castling being a special case.
+func (board *Board) SetChess960(chess960 bool) {
+	board.chess960 = chess960
+}
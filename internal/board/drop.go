@@ -0,0 +1,158 @@
+package board
+
+import (
+	"sort"
+	"strings"
+)
+
+// dropLetters are the pieces that can be dropped, in the order pockets are
+// written.
+var dropLetters = []string{"q", "r", "b", "n", "p"}
+
+func (move Move) isDrop() bool {
+	return move.squareFrom == nil
+}
+
+// newDrop describes a piece of the team put from its pocket on the square.
+func newDrop(letter string, square *Square, team Team) Move {
+	piece := CreatePiece(teamSign(letter, team), square.row, square.col)
+
+	return Move{piece: &piece, squareTo: square}
+}
+
+// checkDrop parses a drop such as "N@f3" and panics if it is illegal.
+func (board Board) checkDrop(command string, team Team) Move {
+	letter, position, _ := strings.Cut(command, "@")
+	letter = strings.ToLower(letter)
+	if letter == "" {
+		letter = "p"
+	}
+
+	square := board.getSquareSafe(position)
+	if !board.rules().pockets() || square == nil || !board.canDrop(letter, square, team) {
+		panic(illegalMoveMessage)
+	}
+
+	move := newDrop(letter, square, team)
+	if !board.isLegal(move, team) {
+		panic(causingSelfInCheckMessage)
+	}
+
+	return move
+}
+
+// canDrop checks the pocket and the square, ignoring the king: pawns are
+// never dropped on the first or last rank.
+func (board Board) canDrop(letter string, square *Square, team Team) bool {
+	if square.hasPiece() || board.pocketIndex(letter, team) < 0 {
+		return false
+	}
+
+	return letter != "p" || square.row != 0 && square.row != board.ranks-1
+}
+
+// dropMoves returns the legal drops of the team in variants with pockets.
+func (board Board) dropMoves(team Team) []Move {
+	if !board.rules().pockets() {
+		return nil
+	}
+
+	var moves []Move
+	for _, letter := range dropLetters {
+		if board.pocketIndex(letter, team) < 0 {
+			continue
+		}
+
+		for row := 0; row < board.ranks; row++ {
+			for col := 0; col < board.files; col++ {
+				square := &board.squares[row][col]
+				if !board.canDrop(letter, square, team) {
+					continue
+				}
+				if move := newDrop(letter, square, team); board.isLegal(move, team) {
+					moves = append(moves, move)
+				}
+			}
+		}
+	}
+
+	return moves
+}
+
+func (board *Board) makeDrop(move Move, team Team) {
+	captures := board.captures(team)
+	i := board.pocketIndex(strings.ToLower(move.piece.sign), team)
+	*captures = append((*captures)[:i], (*captures)[i+1:]...)
+
+	board.placePiece(move.piece, move.squareTo)
+	board.enPassant = ""
+	board.advanceMoveCounters(*move.piece, false, team)
+	board.rules().afterMove(board, move, team, false)
+}
+
+// captures returns the list of pieces the team captured, which is its
+// pocket in Crazyhouse.
+func (board *Board) captures(team Team) *[]string {
+	if team == White {
+		return &board.whiteCaptures
+	}
+
+	return &board.blackCaptures
+}
+
+// pocketIndex finds a piece in the pocket of the team, -1 if it is not
+// there. Pockets hold the signs of the captured pieces of the opponent.
+func (board Board) pocketIndex(letter string, team Team) int {
+	sign := teamSign(letter, getOpponentTeam(team))
+	for i, captured := range *board.captures(team) {
+		if captured == sign {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Pocket returns the pieces the team can drop as FEN letters of its own
+// colour, queens first.
+func (board Board) Pocket(team Team) []string {
+	var pocket []string
+	for _, sign := range *board.captures(team) {
+		pocket = append(pocket, FENLetter(swapCase(sign)))
+	}
+
+	sort.SliceStable(pocket, func(i, j int) bool {
+		return dropOrder(pocket[i]) < dropOrder(pocket[j])
+	})
+
+	return pocket
+}
+
+func dropOrder(letter string) int {
+	for i, l := range dropLetters {
+		if l == strings.ToLower(letter) {
+			return i
+		}
+	}
+
+	return len(dropLetters)
+}
+
+// AddToPocket gives the team pieces to drop, written as FEN letters of any
+// colour.
+func (board *Board) AddToPocket(team Team, letters ...string) {
+	captures := board.captures(team)
+	for _, letter := range letters {
+		*captures = append(*captures, teamSign(strings.ToLower(letter), getOpponentTeam(team)))
+	}
+}
+
+// GivePocket hands the pieces the team captured to the team's opponent on
+// the partner board, as in Bughouse where partners play opposite colours.
+func (board *Board) GivePocket(team Team, partner *Board) {
+	captures := board.captures(team)
+	for _, sign := range *captures {
+		partner.AddToPocket(getOpponentTeam(team), sign)
+	}
+	*captures = nil
+}
@@ -0,0 +1,354 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// ParseFEN builds a board from a FEN record and returns it together with the
+// side to move.
+func ParseFEN(fen string) (*Board, Team, error) {
+	return ParseVariantFEN(fen, Standard)
+}
+
+// ParseVariantFEN is ParseFEN for a position of the variant, which decides
+// for example how many kings there must be.
+func ParseVariantFEN(fen string, variant Variant) (*Board, Team, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 2 {
+		return nil, Undecided, errors.New("fen: expected at least piece placement and side to move")
+	}
+
+	placement, pocket := splitPocket(fields[0])
+	ranks := strings.Split(placement, "/")
+	if len(ranks) > 1 && pocket == "" && variant.pockets() {
+		// The pocket may also be written as an extra rank, which is shorter
+		// than the others or a ninth one.
+		last := ranks[len(ranks)-1]
+		if rankWidth(last) != rankWidth(ranks[0]) || len(ranks) == boardSize+1 {
+			ranks, pocket = ranks[:len(ranks)-1], last
+		}
+	}
+	if pocket != "" && !variant.pockets() {
+		return nil, Undecided, fmt.Errorf("fen: %s has no pockets", variant.Name())
+	}
+
+	files := rankWidth(ranks[0])
+	if len(ranks) < MinBoardSize || len(ranks) > MaxBoardSize || files < MinBoardSize || files > MaxBoardSize {
+		return nil, Undecided, fmt.Errorf("fen: the board must have %d to %d files and ranks, got %dx%d",
+			MinBoardSize, MaxBoardSize, files, len(ranks))
+	}
+
+	board := newBoard(files, len(ranks))
+	for row, rank := range ranks {
+		col := 0
+		for i := 0; i < len(rank); i++ {
+			r := rank[i]
+			if r >= '0' && r <= '9' {
+				empty := 0
+				for ; i < len(rank) && rank[i] >= '0' && rank[i] <= '9'; i++ {
+					empty = empty*10 + int(rank[i]-'0')
+				}
+				i--
+				col += empty
+				continue
+			}
+			if r == '~' {
+				// The piece before is a promoted pawn in Crazyhouse.
+				if col == 0 || col > files || !board.squares[row][col-1].hasPiece() {
+					return nil, Undecided, fmt.Errorf("fen: misplaced ~ in rank %d", board.ranks-row)
+				}
+				board.squares[row][col-1].piece.promoted = true
+				continue
+			}
+
+			sign := FENSign(string(r))
+			if !isPieceSign(sign) {
+				return nil, Undecided, fmt.Errorf("fen: unknown piece %q", r)
+			}
+			if col >= files {
+				return nil, Undecided, fmt.Errorf("fen: rank %d is too long", board.ranks-row)
+			}
+
+			piece := CreatePiece(sign, row, col)
+			board.squares[row][col].SetPiece(&piece)
+			col++
+		}
+
+		if col != files {
+			return nil, Undecided, fmt.Errorf("fen: rank %d has %d squares", board.ranks-row, col)
+		}
+	}
+
+	if err := variant.validate(*board); err != nil {
+		return nil, Undecided, fmt.Errorf("fen: %v", err)
+	}
+
+	for _, r := range pocket {
+		letter := string(r)
+		if dropOrder(letter) == len(dropLetters) {
+			return nil, Undecided, fmt.Errorf("fen: bad piece %q in pocket", r)
+		}
+		team := Black
+		if letter == strings.ToUpper(letter) {
+			team = White
+		}
+		board.AddToPocket(team, letter)
+	}
+
+	team, err := ParseTeam(fields[1])
+	if err != nil {
+		return nil, Undecided, fmt.Errorf("fen: %v", err)
+	}
+
+	board.castling = map[Team][2]int{White: {noRook, noRook}, Black: {noRook, noRook}}
+	if len(fields) >= 3 {
+		if err := board.parseCastling(fields[2]); err != nil {
+			return nil, Undecided, err
+		}
+	}
+
+	if len(fields) >= 4 && fields[3] != "-" {
+		if board.getSquareSafe(fields[3]) == nil {
+			return nil, Undecided, fmt.Errorf("fen: bad en passant square %q", fields[3])
+		}
+		board.enPassant = fields[3]
+	}
+
+	if len(fields) >= 5 {
+		if board.halfmoveClock, err = strconv.Atoi(fields[4]); err != nil {
+			return nil, Undecided, fmt.Errorf("fen: bad halfmove clock %q", fields[4])
+		}
+	}
+	if len(fields) >= 6 {
+		if board.fullmoveNumber, err = strconv.Atoi(fields[5]); err != nil || board.fullmoveNumber < 1 {
+			return nil, Undecided, fmt.Errorf("fen: bad fullmove number %q", fields[5])
+		}
+	}
+
+	board.SetVariant(variant)
+	return board, team, nil
+}
+
+// FEN returns the FEN record of the position with the given side to move.
+// Chess960 castling rights are written as X-FEN.
+func (board Board) FEN(team Team) string {
+	return board.fen(team, false)
+}
+
+// ShredderFEN is FEN with the castling rights written as the files of the
+// rooks, e.g. HAha for the standard start position.
+func (board Board) ShredderFEN(team Team) string {
+	return board.fen(team, true)
+}
+
+func (board Board) fen(team Team, shredder bool) string {
+	var buffer strings.Builder
+
+	for i := 0; i < board.ranks; i++ {
+		empty := 0
+		for j := 0; j < board.files; j++ {
+			piece := board.squares[i][j].GetPiece()
+			if piece == nil {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				buffer.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			buffer.WriteString(FENLetter(piece.sign))
+			if piece.promoted && board.rules().pockets() {
+				buffer.WriteString("~")
+			}
+		}
+		if empty > 0 {
+			buffer.WriteString(strconv.Itoa(empty))
+		}
+		if i != board.ranks-1 {
+			buffer.WriteString("/")
+		}
+	}
+
+	if board.rules().pockets() {
+		buffer.WriteString("[" + strings.Join(board.Pocket(White), "") + strings.Join(board.Pocket(Black), "") + "]")
+	}
+
+	side := "w"
+	if team == Black {
+		side = "b"
+	}
+
+	enPassant := board.enPassant
+	if enPassant == "" {
+		enPassant = "-"
+	}
+
+	return fmt.Sprintf("%s %s %s %s %d %d", buffer.String(), side, board.castlingField(shredder), enPassant, board.halfmoveClock, board.fullmoveNumber)
+}
+
+// rankWidth counts the squares of a rank of the piece placement.
+func rankWidth(rank string) int {
+	width := 0
+	for i := 0; i < len(rank); i++ {
+		switch r := rank[i]; {
+		case r >= '0' && r <= '9':
+			empty := 0
+			for ; i < len(rank) && rank[i] >= '0' && rank[i] <= '9'; i++ {
+				empty = empty*10 + int(rank[i]-'0')
+			}
+			i--
+			width += empty
+		case r != '~':
+			width++
+		}
+	}
+
+	return width
+}
+
+// PositionKey identifies the position for the repetition rule: the FEN
+// without the move counters, with the en passant square only when a pawn
+// can take on it.
+func (board Board) PositionKey(team Team) string {
+	fields := strings.Fields(board.FEN(team))
+	if fields[3] != "-" && !board.canTakeEnPassant(team) {
+		fields[3] = "-"
+	}
+
+	return strings.Join(fields[:4], " ")
+}
+
+func (board Board) canTakeEnPassant(team Team) bool {
+	for _, move := range board.legalMoves(team) {
+		if move.captureSquare != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitPocket separates the Crazyhouse pocket written in brackets after the
+// piece placement, e.g. "...RNBQKBNR[Qn]".
+func splitPocket(placement string) (string, string) {
+	i := strings.IndexByte(placement, '[')
+	if i < 0 || !strings.HasSuffix(placement, "]") {
+		return placement, ""
+	}
+
+	return placement[:i], placement[i+1 : len(placement)-1]
+}
+
+// parseCastling reads the castling field as KQkq, X-FEN or Shredder-FEN:
+// K and Q stand for the outermost rook on that side of the king, a file
+// letter for the rook on that file, uppercase for White. Rights without the
+// king and rook on their home row are dropped.
+func (board *Board) parseCastling(field string) error {
+	if field == "-" {
+		return nil
+	}
+
+	for _, r := range field {
+		team := White
+		if r >= 'a' && r <= 'z' {
+			team = Black
+		}
+		kingCol := board.homeKingCol(team)
+
+		var side, rookCol int
+		switch letter := strings.ToLower(string(r)); {
+		case letter == "k":
+			side, rookCol = kingside, board.outermostRook(team, kingside)
+		case letter == "q":
+			side, rookCol = queenside, board.outermostRook(team, queenside)
+		case letter >= "a" && letter <= string(rune('a'+board.files-1)):
+			rookCol = int(letter[0] - 'a')
+			side = kingside
+			if rookCol < kingCol {
+				side = queenside
+			}
+			if kingCol < 0 || !board.isPieceAt(board.homeRow(team), rookCol, "r", team) {
+				rookCol = noRook
+			}
+			board.chess960 = true
+		default:
+			return fmt.Errorf("fen: bad castling field %q", field)
+		}
+
+		if rookCol != noRook && (kingCol != board.files/2 || rookCol != 0 && rookCol != board.files-1) {
+			board.chess960 = true
+		}
+
+		rights := board.castling[team]
+		rights[side] = rookCol
+		board.castling[team] = rights
+	}
+
+	return nil
+}
+
+// castlingField writes the rights as X-FEN, which is KQkq for standard
+// chess, or with file letters only as Shredder-FEN.
+func (board Board) castlingField(shredder bool) string {
+	var field strings.Builder
+
+	for _, team := range []Team{White, Black} {
+		rights, ok := board.castling[team]
+		if !ok {
+			continue
+		}
+
+		for _, side := range []int{kingside, queenside} {
+			rookCol := rights[side]
+			if rookCol == noRook {
+				continue
+			}
+
+			letter := []string{"k", "q"}[side]
+			if shredder || rookCol != board.outermostRook(team, side) {
+				letter = string(rune('a' + rookCol))
+			}
+			field.WriteString(teamLetter(letter, team))
+		}
+	}
+
+	if field.Len() == 0 {
+		return "-"
+	}
+
+	return field.String()
+}
+
+// teamLetter turns a lowercase letter into the FEN letter of the team.
+func teamLetter(letter string, team Team) string {
+	return FENLetter(teamSign(letter, team))
+}
+
+// FENLetter converts a board sign (lowercase for White) to its FEN letter
+// (uppercase for White).
+func FENLetter(sign string) string {
+	return swapCase(sign)
+}
+
+// FENSign converts a FEN letter to the board sign.
+func FENSign(letter string) string {
+	return swapCase(letter)
+}
+
+func swapCase(s string) string {
+	if s == strings.ToUpper(s) {
+		return strings.ToLower(s)
+	}
+
+	return strings.ToUpper(s)
+}
+
+func isPieceSign(sign string) bool {
+	_, ok := pieceTypes[strings.ToLower(sign)]
+	return len(sign) == 1 && ok
+}
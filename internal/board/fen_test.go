@@ -0,0 +1,102 @@
+package board
+
+import "testing"
+
+func TestFENRoundTrip(t *testing.T) {
+	for _, fen := range []string{
+		StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 b - - 12 40",
+		"4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+	} {
+		b, team, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("ParseFEN(%s): %v", fen, err)
+		}
+		if got := b.FEN(team); got != fen {
+			t.Errorf("FEN = %s, want %s", got, fen)
+		}
+	}
+}
+
+func TestParseFENErrors(t *testing.T) {
+	for _, fen := range []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+		"rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	} {
+		if _, _, err := ParseFEN(fen); err == nil {
+			t.Errorf("ParseFEN(%q) succeeded, want an error", fen)
+		}
+	}
+}
+
+func TestPocketFEN(t *testing.T) {
+	for _, fen := range []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[Qp] w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR/Qp w KQkq - 0 1",
+	} {
+		if _, _, err := ParseFEN(fen); err == nil {
+			t.Errorf("ParseFEN(%q) took a pocket in standard chess", fen)
+		}
+		if _, _, err := ParseVariantFEN(fen, Crazyhouse); err != nil {
+			t.Errorf("ParseVariantFEN(%q, Crazyhouse): %v", fen, err)
+		}
+	}
+}
+
+func TestSAN(t *testing.T) {
+	for _, test := range []struct {
+		fen, command, san string
+	}{
+		{StartFEN, "g1 f3", "Nf3"},
+		{StartFEN, "e2 e4", "e4"},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", "e1 g1", "O-O"},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", "e1 c1", "O-O-O"},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", "d5 e6", "dxe6"},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", "e5 f6", "exf6"},
+		{"4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7 a8 n", "a8=N"},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "a1 d1", "Rad1"},
+		{"7k/8/6K1/8/8/8/8/1Q6 w - - 0 1", "b1 b8", "Qb8#"},
+		{"4k3/8/8/8/8/8/8/R3K3 w - - 0 1", "a1 a8", "Ra8+"},
+	} {
+		b, team, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if san, err := b.SAN(test.command, team); err != nil || san != test.san {
+			t.Errorf("SAN(%s) in %s = %q, %v, want %q", test.command, test.fen, san, err, test.san)
+		}
+		if command, err := b.ParseSAN(test.san, team); err != nil || command != test.command {
+			t.Errorf("ParseSAN(%s) in %s = %q, %v, want %q", test.san, test.fen, command, err, test.command)
+		}
+	}
+}
+
+// TestSANRoundTrip writes every legal move of busy positions in SAN and
+// reads it back.
+func TestSANRoundTrip(t *testing.T) {
+	for _, fen := range []string{
+		StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	} {
+		b, team, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, command := range b.LegalMoves(team) {
+			san, err := b.SAN(command, team)
+			if err != nil {
+				t.Errorf("SAN(%s) in %s: %v", command, fen, err)
+				continue
+			}
+			if back, err := b.ParseSAN(san, team); err != nil || back != command {
+				t.Errorf("ParseSAN(%s) in %s = %q, %v, want %q", san, fen, back, err, command)
+			}
+		}
+	}
+}
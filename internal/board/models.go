@@ -1,24 +1,31 @@
 package board
 
+// Boards are square-ruled from 5x5 as in Gardner's minichess to 10x10,
+// boardSize by boardSize unless a FEN says otherwise.
 const (
-	boardSize = 8
-	BlackKing = "\u2654"
-	WhiteKing = "\u265A"
+	MinBoardSize = 5
+	MaxBoardSize = 10
+	boardSize    = 8
+)
+
+const (
+	WhiteKing = "\u2654"
+	BlackKing = "\u265A"
 
-	BlackQueen = "\u2655"
-	WhiteQueen = "\u265B"
+	WhiteQueen = "\u2655"
+	BlackQueen = "\u265B"
 
-	BlackRook = "\u2656"
-	WhiteRook = "\u265C"
+	WhiteRook = "\u2656"
+	BlackRook = "\u265C"
 
-	BlackBishop = "\u2657"
-	WhiteBishop = "\u265D"
+	WhiteBishop = "\u2657"
+	BlackBishop = "\u265D"
 
-	BlackKnight = "\u2658"
-	WhiteKnight = "\u265E"
+	WhiteKnight = "\u2658"
+	BlackKnight = "\u265E"
 
-	BlackPawn = "\u2659"
-	WhitePawn = "\u265F"
+	WhitePawn = "\u2659"
+	BlackPawn = "\u265F"
 
 	illegalMoveMessage        = "Illegal move! Please enter again."
 	causingSelfInCheckMessage = "This move will cause yourself in check! Please enter again."
@@ -26,15 +33,28 @@ const (
 )
 
 type Board struct {
-	squares       [][]Square
-	whiteCaptures []string
-	blackCaptures []string
+	squares        [][]Square
+	files, ranks   int
+	whiteCaptures  []string
+	blackCaptures  []string
+	halfmoveClock  int
+	fullmoveNumber int
+	castling       map[Team][2]int
+	enPassant      string
+	// chess960 writes castlings as the king taking its rook and the
+	// castling rights of the FEN as X-FEN.
+	chess960 bool
+	// variant is nil for standard chess.
+	variant Variant
+	// checks counts the checks given by each team in Three-check.
+	checks [3]int
 }
 
 type Square struct {
-	row   int
-	col   int
-	piece *Piece
+	row      int
+	col      int
+	position string
+	piece    *Piece
 }
 
 type Piece struct {
@@ -42,6 +62,8 @@ type Piece struct {
 	team Team
 	row  int
 	col  int
+	// promoted pieces go back to a pocket as pawns in Crazyhouse.
+	promoted bool
 }
 
 type Team int
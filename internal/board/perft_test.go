@@ -0,0 +1,47 @@
+package board
+
+import "testing"
+
+// perft counts the leaf nodes of the legal move tree to the depth.
+func perft(b *Board, team Team, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+
+	nodes := 0
+	for _, move := range b.LegalMoves(team) {
+		next := b.Clone()
+		next.Execute(move, team)
+		nodes += perft(next, team.Opponent(), depth-1)
+	}
+
+	return nodes
+}
+
+// TestPerft checks the move generator against the node counts of the
+// positions of the Chess Programming Wiki perft page.
+func TestPerft(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		fen   string
+		nodes []int
+	}{
+		{"initial", StartFEN, []int{20, 400, 8902}},
+		{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039}},
+		{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812}},
+		{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467}},
+		{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			b, team, err := ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for depth, want := range test.nodes {
+				if got := perft(b, team, depth+1); got != want {
+					t.Errorf("perft(%d) = %d, want %d", depth+1, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pieces other than the pawn move as described in Betza's notation. The
+// atoms are the leaps W (1,0), F (1,1), D (2,0), N (2,1), A (2,2), H (3,0),
+// C (3,1), Z (3,2) and G (3,3); K stands for WF, Q for WWFF, R for WW and B
+// for FF. A doubled atom rides any number of steps in its direction and a
+// number after it rides that many, e.g. "NN" or "W2". An atom may be
+// prefixed by m (moves only), c (captures only), f or b (forwards or
+// backwards only) and g, which makes it hop over the first piece on its line
+// to the square right behind it as the grasshopper does.
+type pieceType struct {
+	name  string
+	betza string
+	// symbols are the glyphs of the white and the black piece, the FEN
+	// letter is shown when there are none.
+	symbols [2]string
+	rules   []moveRule
+}
+
+type moveRule struct {
+	directions [][2]int
+	// limit is the number of steps, 0 for any.
+	limit          int
+	move, capture  bool
+	forwards, back bool
+	hop            bool
+}
+
+var atoms = map[byte][2]int{
+	'W': {1, 0}, 'F': {1, 1}, 'D': {2, 0}, 'N': {2, 1}, 'A': {2, 2},
+	'H': {3, 0}, 'C': {3, 1}, 'Z': {3, 2}, 'G': {3, 3},
+}
+
+// shorthands are written with atoms, a doubled atom being a rider.
+var shorthands = map[byte]string{
+	'K': "WF", 'Q': "WWFF", 'R': "WW", 'B': "FF",
+}
+
+// pieceTypes maps the lowercase letter of a piece to its movement. The pawn
+// is listed without one, its moves are generated by getPawnMoves.
+var pieceTypes = map[string]*pieceType{
+	"k": {name: "King", betza: "K", symbols: [2]string{WhiteKing, BlackKing}},
+	"q": {name: "Queen", betza: "Q", symbols: [2]string{WhiteQueen, BlackQueen}},
+	"r": {name: "Rook", betza: "R", symbols: [2]string{WhiteRook, BlackRook}},
+	"b": {name: "Bishop", betza: "B", symbols: [2]string{WhiteBishop, BlackBishop}},
+	"n": {name: "Knight", betza: "N", symbols: [2]string{WhiteKnight, BlackKnight}},
+	"p": {name: "Pawn", symbols: [2]string{WhitePawn, BlackPawn}},
+
+	"a": {name: "Archbishop", betza: "BN"},
+	"c": {name: "Chancellor", betza: "RN"},
+	"z": {name: "Amazon", betza: "QN"},
+	"l": {name: "Camel", betza: "C"},
+	"g": {name: "Grasshopper", betza: "gQ"},
+}
+
+func init() {
+	for _, kind := range pieceTypes {
+		rules, err := parseBetza(kind.betza)
+		if err != nil {
+			panic(err)
+		}
+		kind.rules = rules
+	}
+}
+
+// RegisterPiece adds a fairy piece moving as the Betza description says,
+// so that it can be used in FEN positions of any variant. The letter must
+// not be taken by another piece.
+func RegisterPiece(letter, name, betza string) error {
+	letter = strings.ToLower(letter)
+	if len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		return fmt.Errorf("piece letter %q is not a single letter", letter)
+	}
+	if _, ok := pieceTypes[letter]; ok {
+		return fmt.Errorf("piece letter %q is already taken", letter)
+	}
+
+	rules, err := parseBetza(betza)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("piece %s has no moves", name)
+	}
+
+	pieceTypes[letter] = &pieceType{name: name, betza: betza, rules: rules}
+	return nil
+}
+
+// PieceName returns the name of the piece the sign stands for.
+func PieceName(sign string) string {
+	if kind, ok := pieceTypes[strings.ToLower(sign)]; ok {
+		return kind.name
+	}
+
+	return ""
+}
+
+func parseBetza(betza string) ([]moveRule, error) {
+	var rules []moveRule
+
+	for i := 0; i < len(betza); {
+		var rule moveRule
+		for ; i < len(betza) && strings.IndexByte("mcfbg", betza[i]) >= 0; i++ {
+			switch betza[i] {
+			case 'm':
+				rule.move = true
+			case 'c':
+				rule.capture = true
+			case 'f':
+				rule.forwards = true
+			case 'b':
+				rule.back = true
+			case 'g':
+				rule.hop = true
+			}
+		}
+		if !rule.move && !rule.capture {
+			rule.move, rule.capture = true, true
+		}
+		if i == len(betza) {
+			return nil, fmt.Errorf("betza %q: modifiers without an atom", betza)
+		}
+
+		atom := betza[i]
+		i++
+		expansion, ok := shorthands[atom]
+		if !ok {
+			if _, ok := atoms[atom]; !ok {
+				return nil, fmt.Errorf("betza %q: unknown atom %c", betza, atom)
+			}
+			expansion = string(atom)
+		}
+
+		// count is the number of steps written after the atom, -1 for none.
+		count := -1
+		switch {
+		case i < len(betza) && betza[i] == atom:
+			count = 0
+			i++
+		case i < len(betza) && betza[i] >= '0' && betza[i] <= '9':
+			count = 0
+			for ; i < len(betza) && betza[i] >= '0' && betza[i] <= '9'; i++ {
+				count = count*10 + int(betza[i]-'0')
+			}
+		}
+
+		// The shorthands mix leapers and riders, so each of their atoms
+		// becomes a rule of its own.
+		for j := 0; j < len(expansion); {
+			part := rule
+			part.directions = directions(atoms[expansion[j]])
+			part.limit = 1
+			j++
+			if j < len(expansion) && expansion[j] == expansion[j-1] {
+				part.limit = 0
+				j++
+			}
+			if count >= 0 {
+				part.limit = count
+			}
+			rules = append(rules, part)
+		}
+	}
+
+	return rules, nil
+}
+
+// directions returns the distinct moves of a leap in every orientation, as
+// {rows, columns}.
+func directions(leap [2]int) [][2]int {
+	var result [][2]int
+	seen := make(map[[2]int]bool)
+
+	for _, d := range [][2]int{{leap[0], leap[1]}, {leap[1], leap[0]}} {
+		for _, sr := range []int{1, -1} {
+			for _, sc := range []int{1, -1} {
+				direction := [2]int{d[0] * sr, d[1] * sc}
+				if !seen[direction] {
+					seen[direction] = true
+					result = append(result, direction)
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// pieceMoves returns the squares the piece can move to by its rules. With
+// attacks it returns the squares it could capture on instead, empty or not.
+func (board Board) pieceMoves(piece Piece, attacks bool) []string {
+	kind, ok := pieceTypes[strings.ToLower(piece.sign)]
+	if !ok || kind.rules == nil {
+		panic("Unknown piece sign: " + piece.sign)
+	}
+
+	forwards := -1
+	if piece.team == Black {
+		forwards = 1
+	}
+
+	var moves []string
+	for _, rule := range kind.rules {
+		for _, direction := range rule.directions {
+			if rule.forwards && direction[0]*forwards <= 0 || rule.back && direction[0]*forwards >= 0 {
+				continue
+			}
+			moves = board.ride(moves, piece, rule, direction, attacks)
+		}
+	}
+
+	return moves
+}
+
+// ride adds the squares reached in the direction, stopping at the first
+// piece, or the square behind it for a hopper.
+func (board Board) ride(moves []string, piece Piece, rule moveRule, direction [2]int, attacks bool) []string {
+	row, col := piece.row, piece.col
+	hurdle := false
+
+	for step := 1; rule.limit == 0 || step <= rule.limit; step++ {
+		row, col = row+direction[0], col+direction[1]
+		if !board.onBoard(row, col) {
+			break
+		}
+
+		target := board.squares[row][col].piece
+		if rule.hop && !hurdle {
+			hurdle = target != nil
+			continue
+		}
+
+		switch {
+		case attacks:
+			if rule.capture {
+				moves = append(moves, board.position(row, col))
+			}
+		case target == nil:
+			if rule.move {
+				moves = append(moves, board.position(row, col))
+			}
+		case target.team != piece.team && rule.capture:
+			moves = append(moves, board.position(row, col))
+		}
+
+		if target != nil || rule.hop {
+			break
+		}
+	}
+
+	return moves
+}
+
+// attacks reports whether the piece could capture on the square, which is
+// what pieceMoves with attacks finds without listing every square.
+func (board Board) attacks(piece Piece, row, col int) bool {
+	forwards := -1
+	if piece.team == Black {
+		forwards = 1
+	}
+
+	if isPawn(piece) {
+		return row == piece.row+forwards && (col == piece.col-1 || col == piece.col+1)
+	}
+
+	kind, ok := pieceTypes[strings.ToLower(piece.sign)]
+	if !ok || kind.rules == nil {
+		panic("Unknown piece sign: " + piece.sign)
+	}
+
+	for _, rule := range kind.rules {
+		if !rule.capture {
+			continue
+		}
+		for _, direction := range rule.directions {
+			if rule.forwards && direction[0]*forwards <= 0 || rule.back && direction[0]*forwards >= 0 {
+				continue
+			}
+			if board.reaches(piece, rule, direction, row, col) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// reaches reports whether the square lies in the direction of the piece
+// within the limit of the rule, with the squares in between as the rule
+// needs them: empty, or for a hopper empty but for the one right before the
+// square.
+func (board Board) reaches(piece Piece, rule moveRule, direction [2]int, row, col int) bool {
+	steps := stepsTo(row-piece.row, col-piece.col, direction)
+	if steps == 0 || rule.limit != 0 && steps > rule.limit {
+		return false
+	}
+
+	empty := steps
+	if rule.hop {
+		if steps < 2 || board.squares[row-direction[0]][col-direction[1]].piece == nil {
+			return false
+		}
+		empty--
+	}
+
+	for step := 1; step < empty; step++ {
+		if board.squares[piece.row+step*direction[0]][piece.col+step*direction[1]].piece != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stepsTo returns the number of steps in the direction that cover the
+// distance, 0 if no number does.
+func stepsTo(rows, cols int, direction [2]int) int {
+	steps := 0
+	if direction[0] != 0 {
+		steps = rows / direction[0]
+	} else {
+		steps = cols / direction[1]
+	}
+
+	if steps <= 0 || steps*direction[0] != rows || steps*direction[1] != cols {
+		return 0
+	}
+	return steps
+}
@@ -0,0 +1,355 @@
+package board
+
+import (
+	"errors"
+	"strings"
+)
+
+// Castling rights are kept per team as the columns of the rooks the king
+// may still castle with, -1 once a right is lost.
+const (
+	kingside  = 0
+	queenside = 1
+	noRook    = -1
+)
+
+var promotionLetters = []string{"q", "r", "b", "n"}
+
+// newMove describes a piece movement, recognising en passant captures.
+func (board Board) newMove(piece *Piece, squareFrom, squareTo *Square) Move {
+	move := Move{piece: piece, squareFrom: squareFrom, squareTo: squareTo}
+
+	if isPawn(*piece) && squareFrom.col != squareTo.col && !squareTo.hasPiece() {
+		move.captureSquare = &board.squares[squareFrom.row][squareTo.col]
+	}
+
+	return move
+}
+
+func (move Move) isCapture() bool {
+	return move.squareTo.hasPiece() && move.rookFrom == nil || move.captureSquare != nil
+}
+
+func (board Board) isPromotion(move Move) bool {
+	return isPawn(*move.piece) && (move.squareTo.row == 0 || move.squareTo.row == board.ranks-1)
+}
+
+// setPromotion sets the piece of a promotion from its letter, a queen when
+// the letter is empty, if the variant allows it.
+func (board Board) setPromotion(move *Move, letter string, team Team) error {
+	if !board.isPromotion(*move) {
+		if letter != "" {
+			return errors.New("not a promotion")
+		}
+		return nil
+	}
+
+	if letter == "" {
+		letter = "q"
+	}
+	letter = strings.ToLower(letter)
+	if !containsMove(board.rules().promotions(), letter) {
+		return errors.New("cannot promote to " + letter)
+	}
+
+	move.promotion = teamSign(letter, team)
+	return nil
+}
+
+// teamSign turns a lowercase piece letter into the sign of the team.
+func teamSign(letter string, team Team) string {
+	if team == Black {
+		return strings.ToUpper(letter)
+	}
+
+	return strings.ToLower(letter)
+}
+
+// makeMove plays a move that was already checked.
+func (board *Board) makeMove(move Move, team Team) {
+	if move.isDrop() {
+		board.makeDrop(move, team)
+		return
+	}
+
+	moved := *move.piece
+	capture := move.isCapture()
+
+	board.updateCastlingRights(move, team)
+
+	if move.captureSquare != nil {
+		board.captured(*move.captureSquare.piece)
+		move.captureSquare.setPiece(nil)
+	}
+
+	if move.rookFrom != nil {
+		// In Chess960 the king and the rook may land on each other's
+		// squares, so both are lifted before they are put down.
+		rook := move.rookFrom.piece
+		move.squareFrom.setPiece(nil)
+		move.rookFrom.setPiece(nil)
+		board.placePiece(move.piece, move.squareTo)
+		board.placePiece(rook, move.rookTo)
+	} else {
+		board.movePiece(move.piece, move.squareFrom, move.squareTo)
+	}
+	if move.promotion != "" {
+		move.piece.sign = move.promotion
+		move.piece.promoted = true
+	}
+
+	board.enPassant = ""
+	if isPawn(moved) && (move.squareFrom.row-move.squareTo.row == 2 || move.squareTo.row-move.squareFrom.row == 2) {
+		board.enPassant = board.position((move.squareFrom.row+move.squareTo.row)/2, move.squareFrom.col)
+	}
+
+	board.advanceMoveCounters(moved, capture, team)
+	board.rules().afterMove(board, move, team, capture)
+}
+
+func (board *Board) placePiece(piece *Piece, square *Square) {
+	square.setPiece(piece)
+	piece.row = square.row
+	piece.col = square.col
+}
+
+func (board *Board) updateCastlingRights(move Move, team Team) {
+	if isKing(*move.piece) {
+		board.loseCastlingRights(team)
+	}
+
+	// A rook leaving or taken on its home square loses its right.
+	board.loseCastlingRight(move.squareFrom)
+	board.loseCastlingRight(move.squareTo)
+}
+
+func (board *Board) loseCastlingRights(team Team) {
+	if board.castling != nil {
+		board.castling[team] = [2]int{noRook, noRook}
+	}
+}
+
+// loseCastlingRight drops the right of a rook on its home square.
+func (board *Board) loseCastlingRight(square *Square) {
+	for _, owner := range []Team{White, Black} {
+		rights, ok := board.castling[owner]
+		if !ok || square.row != board.homeRow(owner) {
+			continue
+		}
+		for side, col := range rights {
+			if col == square.col {
+				rights[side] = noRook
+			}
+		}
+		board.castling[owner] = rights
+	}
+}
+
+func (board Board) homeRow(team Team) int {
+	if team == White {
+		return board.ranks - 1
+	}
+
+	return 0
+}
+
+// homeCastlingRights grants castling for every king on its home row with
+// the outermost rook on each side of it.
+func (board Board) homeCastlingRights() map[Team][2]int {
+	rights := make(map[Team][2]int)
+
+	for _, team := range []Team{White, Black} {
+		rights[team] = [2]int{board.outermostRook(team, kingside), board.outermostRook(team, queenside)}
+	}
+
+	return rights
+}
+
+// homeKingCol returns the column of the king on its home row, -1 if it is
+// not there.
+func (board Board) homeKingCol(team Team) int {
+	for col := 0; col < board.files; col++ {
+		if board.isPieceAt(board.homeRow(team), col, "k", team) {
+			return col
+		}
+	}
+
+	return -1
+}
+
+// outermostRook returns the column of the rook nearest to the corner on the
+// side of the king, noRook if there is none.
+func (board Board) outermostRook(team Team, side int) int {
+	kingCol := board.homeKingCol(team)
+	if kingCol < 0 {
+		return noRook
+	}
+
+	col, step := board.files-1, -1
+	if side == queenside {
+		col, step = 0, 1
+	}
+
+	for ; col != kingCol; col += step {
+		if board.isPieceAt(board.homeRow(team), col, "r", team) {
+			return col
+		}
+	}
+
+	return noRook
+}
+
+func (board Board) isPieceAt(row, col int, letter string, team Team) bool {
+	piece := board.squares[row][col].piece
+	return piece != nil && piece.team == team && strings.ToLower(piece.sign) == letter
+}
+
+// castlingMoves returns the castlings the team can play. The king and the
+// rook end on the same squares as in standard chess, g- and f-file or c- and
+// d-file counted from the edges on other boards, which covers Chess960: the
+// king is not in check, the squares both pieces cross are empty but for the
+// two of them and the king does not pass an attacked square.
+func (board Board) castlingMoves(team Team) []Move {
+	rights, ok := board.castling[team]
+	if !ok {
+		return nil
+	}
+
+	row := board.homeRow(team)
+	kingCol := board.homeKingCol(team)
+	if kingCol < 0 || board.InCheck(team) {
+		return nil
+	}
+
+	var moves []Move
+	var attacked map[string]bool
+
+	for side, rookCol := range rights {
+		if rookCol == noRook || !board.isPieceAt(row, rookCol, "r", team) {
+			continue
+		}
+
+		kingTo, rookTo := board.files-2, board.files-3
+		if side == queenside {
+			kingTo, rookTo = 2, 3
+		}
+
+		if !board.isEmptyExcept(row, []int{kingCol, kingTo, rookCol, rookTo}, kingCol, rookCol) {
+			continue
+		}
+
+		if attacked == nil {
+			attacked = board.attackedPositions(getOpponentTeam(team))
+		}
+		if board.isPathAttacked(row, kingCol, kingTo, attacked) {
+			continue
+		}
+
+		move := Move{
+			piece:         board.squares[row][kingCol].piece,
+			squareFrom:    &board.squares[row][kingCol],
+			squareTo:      &board.squares[row][kingTo],
+			rookFrom:      &board.squares[row][rookCol],
+			rookTo:        &board.squares[row][rookTo],
+			kingTakesRook: board.chess960 || kingTo-kingCol < 2 && kingCol-kingTo < 2,
+		}
+
+		// The rook may have shielded the destination of the king.
+		after := board.Clone()
+		after.makeMove(after.translate(move), team)
+		if after.InCheck(team) {
+			continue
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves
+}
+
+// castlingMove returns the castling written as the king moving to squareTo
+// or as the king taking its own rook.
+func (board Board) castlingMove(squareFrom, squareTo *Square, team Team) (Move, bool) {
+	for _, move := range board.castlingMoves(team) {
+		if move.squareFrom != squareFrom {
+			continue
+		}
+		if move.rookFrom == squareTo || move.squareTo == squareTo && !move.kingTakesRook {
+			return move, true
+		}
+	}
+
+	return Move{}, false
+}
+
+// isEmptyExcept checks that the squares of the row between the leftmost and
+// rightmost of the columns hold no piece but at the ignored columns.
+func (board Board) isEmptyExcept(row int, cols []int, ignored ...int) bool {
+	from, to := cols[0], cols[0]
+	for _, col := range cols {
+		from, to = min(from, col), max(to, col)
+	}
+
+	for col := from; col <= to; col++ {
+		if board.squares[row][col].hasPiece() && !containsCol(ignored, col) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsCol(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isPathAttacked checks the squares the king crosses, its destination
+// included.
+func (board Board) isPathAttacked(row, from, to int, attacked map[string]bool) bool {
+	step := 1
+	if to < from {
+		step = -1
+	}
+
+	for col := from; col != to; {
+		col += step
+		if attacked[board.position(row, col)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// attackedPositions returns the squares the team attacks. Unlike
+// getReachablePositions it counts pawn diagonals even when they are empty.
+func (board Board) attackedPositions(team Team) map[string]bool {
+	attacked := make(map[string]bool)
+
+	for _, piece := range board.getAllPiece(team) {
+		if !isPawn(piece) {
+			for _, position := range board.pieceMoves(piece, true) {
+				attacked[position] = true
+			}
+			continue
+		}
+
+		row := piece.row - 1
+		if team == Black {
+			row = piece.row + 1
+		}
+		for _, col := range []int{piece.col - 1, piece.col + 1} {
+			if board.onBoard(row, col) {
+				attacked[board.position(row, col)] = true
+			}
+		}
+	}
+
+	return attacked
+}
@@ -0,0 +1,231 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SAN returns the move in Standard Algebraic Notation, e.g. "Nf3", "exd5",
+// "O-O" or "e8=Q+". The command must be legal for the team.
+func (board Board) SAN(command string, team Team) (string, error) {
+	for _, move := range board.legalMoves(team) {
+		if move.command() == normalizeCommand(command) {
+			return board.san(move, team), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: %s", command, illegalMoveMessage)
+}
+
+// ParseSAN finds the legal move of the team written in Standard Algebraic
+// Notation and returns it in the form accepted by Execute.
+func (board Board) ParseSAN(san string, team Team) (string, error) {
+	text := strings.TrimRight(san, "+#!?")
+	if text == "0-0" || text == "0-0-0" {
+		text = strings.ReplaceAll(text, "0", "O")
+	}
+
+	moves := board.legalMoves(team)
+
+	if letter, position, ok := strings.Cut(text, "@"); ok {
+		if letter == "" {
+			letter = "P"
+		}
+		command := strings.ToUpper(letter) + "@" + position
+		for _, move := range moves {
+			if move.isDrop() && move.command() == command {
+				return command, nil
+			}
+		}
+		return "", fmt.Errorf("%s: %s", san, illegalMoveMessage)
+	}
+
+	if text == "O-O" || text == "O-O-O" {
+		for _, move := range moves {
+			if move.rookFrom != nil && castlingSAN(move) == text {
+				return move.command(), nil
+			}
+		}
+		return "", fmt.Errorf("%s: castling is not possible", san)
+	}
+
+	letter := "p"
+	if text != "" && isPieceLetter(text[0]) {
+		letter = strings.ToLower(text[:1])
+		text = text[1:]
+	}
+
+	promotion := ""
+	if i := strings.IndexByte(text, '='); i >= 0 {
+		promotion = strings.ToLower(text[i+1:])
+		text = text[:i]
+	} else if letter == "p" && len(text) > 2 && isPieceLetter(text[len(text)-1]) {
+		promotion = strings.ToLower(text[len(text)-1:])
+		text = text[:len(text)-1]
+	}
+
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "x", ""), "-", "")
+	// The destination is the last file letter with the rank after it, which
+	// may have two digits.
+	i := strings.LastIndexFunc(text, func(r rune) bool { return r >= 'a' && r <= 'z' })
+	if i < 0 || i == len(text)-1 {
+		return "", fmt.Errorf("%s: cannot read the move", san)
+	}
+	destination, hint := text[i:], text[:i]
+
+	var found []Move
+	for _, move := range moves {
+		if move.isDrop() || move.rookFrom != nil || strings.ToLower(move.piece.sign) != letter ||
+			getSquarePosition(*move.squareTo) != destination ||
+			strings.ToLower(move.promotion) != promotion ||
+			!matchesHint(*move.squareFrom, hint) {
+			continue
+		}
+		found = append(found, move)
+	}
+
+	switch len(found) {
+	case 1:
+		return found[0].command(), nil
+	case 0:
+		return "", fmt.Errorf("%s: %s", san, illegalMoveMessage)
+	default:
+		return "", fmt.Errorf("%s: ambiguous move", san)
+	}
+}
+
+// matchesHint checks a disambiguation of a file, a rank or both.
+func matchesHint(square Square, hint string) bool {
+	position := getSquarePosition(square)
+	if hint != "" && hint[0] >= 'a' && hint[0] <= 'z' {
+		if hint[0] != position[0] {
+			return false
+		}
+		hint = hint[1:]
+	}
+
+	return hint == "" || hint == position[1:]
+}
+
+// isPieceLetter reports whether the SAN letter names a piece other than the
+// pawn.
+func isPieceLetter(letter byte) bool {
+	return letter >= 'A' && letter <= 'Z' && letter != 'P' && isPieceSign(string(letter))
+}
+
+func (board Board) san(move Move, team Team) string {
+	var san string
+
+	if move.isDrop() {
+		san = move.command()
+	} else if move.rookFrom != nil {
+		san = castlingSAN(move)
+	} else {
+		from, to := getSquarePosition(*move.squareFrom), getSquarePosition(*move.squareTo)
+		capture := move.squareTo.hasPiece() || move.captureSquare != nil
+		letter := strings.ToUpper(move.piece.sign)
+
+		if letter == "P" {
+			if capture {
+				san = from[:1] + "x"
+			}
+			san += to
+			if move.promotion != "" {
+				san += "=" + strings.ToUpper(move.promotion)
+			}
+		} else {
+			san = letter + board.disambiguation(move, team)
+			if capture {
+				san += "x"
+			}
+			san += to
+		}
+	}
+
+	after := board.Clone()
+	after.makeMove(after.translate(move), team)
+	opponent := getOpponentTeam(team)
+	if after.InCheck(opponent) {
+		if len(after.legalMoves(opponent)) == 0 {
+			return san + "#"
+		}
+		return san + "+"
+	}
+
+	return san
+}
+
+// disambiguation returns the file, rank or square needed when another piece
+// of the same kind can reach the destination too.
+func (board Board) disambiguation(move Move, team Team) string {
+	from := getSquarePosition(*move.squareFrom)
+	sameFile, sameRank, others := false, false, false
+
+	for _, other := range board.legalMoves(team) {
+		if other.isDrop() || other.squareTo != move.squareTo || other.squareFrom == move.squareFrom ||
+			other.piece.sign != move.piece.sign || other.rookFrom != nil {
+			continue
+		}
+
+		others = true
+		otherFrom := getSquarePosition(*other.squareFrom)
+		sameFile = sameFile || otherFrom[0] == from[0]
+		sameRank = sameRank || otherFrom[1:] == from[1:]
+	}
+
+	switch {
+	case !others:
+		return ""
+	case !sameFile:
+		return from[:1]
+	case !sameRank:
+		return from[1:]
+	default:
+		return from
+	}
+}
+
+func castlingSAN(move Move) string {
+	if move.rookFrom.col > move.squareFrom.col {
+		return "O-O"
+	}
+
+	return "O-O-O"
+}
+
+// translate returns the same move on a clone of the board.
+func (board *Board) translate(move Move) Move {
+	square := func(s *Square) *Square {
+		if s == nil {
+			return nil
+		}
+		return &board.squares[s.row][s.col]
+	}
+
+	translated := move
+	translated.squareFrom = square(move.squareFrom)
+	translated.squareTo = square(move.squareTo)
+	translated.rookFrom = square(move.rookFrom)
+	translated.rookTo = square(move.rookTo)
+	translated.captureSquare = square(move.captureSquare)
+	if move.isDrop() {
+		// A dropped piece is not on any board yet and must not be shared.
+		piece := *move.piece
+		translated.piece = &piece
+	} else {
+		translated.piece = translated.squareFrom.piece
+	}
+
+	return translated
+}
+
+// normalizeCommand adds the queen to promotions written without a piece and
+// lowercases the piece letter.
+func normalizeCommand(command string) string {
+	tokens := strings.Fields(command)
+	if len(tokens) == 3 {
+		tokens[2] = strings.ToLower(tokens[2])
+	}
+
+	return strings.Join(tokens, " ")
+}
@@ -0,0 +1,545 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Variant changes the rules of standard chess: which moves are legal, what
+// a move does besides moving the piece and how a game is won. Variants are
+// stateless, anything they count is kept on the board.
+type Variant interface {
+	// Name is the value of the PGN Variant tag.
+	Name() string
+	// StartFEN is the initial position of the variant.
+	StartFEN() string
+
+	// allows rejects moves the variant forbids whatever the position of the
+	// king, such as captures by the king in Atomic.
+	allows(board Board, move Move, team Team) bool
+	// legal reports whether the team may play the move, usually when it
+	// does not leave its king in check.
+	legal(board Board, move Move, team Team) bool
+	inCheck(board Board, team Team) bool
+	// afterMove applies the side effects of a move just made on the board.
+	afterMove(board *Board, move Move, team Team, capture bool)
+	// winner returns the team that won by a rule of the variant and the
+	// reason, Undecided if none did, with toMove to play next. Checkmate
+	// and stalemate are handled by the board.
+	winner(board Board, toMove Team) (Team, string)
+	// pockets reports whether captured pieces can be dropped back.
+	pockets() bool
+	// promotions returns the letters a pawn can promote to.
+	promotions() []string
+	// canDoubleStep reports whether a pawn on the row may move two squares.
+	canDoubleStep(board Board, row int, team Team) bool
+	// setup adapts a position to the variant when it is selected.
+	setup(board *Board)
+	// validate checks a position, e.g. the number of kings.
+	validate(board Board) error
+}
+
+var (
+	Standard      Variant = standard{}
+	ThreeCheck    Variant = threeCheck{}
+	KingOfTheHill Variant = kingOfTheHill{}
+	Atomic        Variant = atomic{}
+	Crazyhouse    Variant = crazyhouse{}
+	// Bughouse is Crazyhouse where the pockets are filled from the partner
+	// board, see GivePocket.
+	Bughouse  Variant = bughouse{}
+	Antichess Variant = antichess{}
+	Horde     Variant = horde{}
+
+	// Gardner is minichess on a 5x5 board, without double steps.
+	Gardner Variant = &configured{config: VariantConfig{
+		Name:       "Gardner",
+		StartFEN:   "rnbqk/ppppp/5/PPPPP/RNBQK w - - 0 1",
+		SingleStep: true,
+	}}
+	// Capablanca adds the archbishop and the chancellor on a 10x8 board.
+	Capablanca Variant = &configured{config: VariantConfig{
+		Name:       "Capablanca",
+		StartFEN:   "rnabqkbcnr/pppppppppp/10/10/10/10/PPPPPPPPPP/RNABQKBCNR w KQkq - 0 1",
+		Promotions: []string{"q", "c", "a", "r", "b", "n"},
+	}}
+)
+
+// registered are the variants added by RegisterVariant.
+var registered []Variant
+
+// Variants returns every variant, Standard first.
+func Variants() []Variant {
+	variants := []Variant{Standard, ThreeCheck, KingOfTheHill, Atomic, Crazyhouse, Bughouse, Antichess, Horde, Gardner, Capablanca}
+
+	return append(variants, registered...)
+}
+
+// RegisterVariant makes the variant known to ParseVariant and Variants.
+func RegisterVariant(variant Variant) error {
+	for _, known := range Variants() {
+		if variantKey(known.Name()) == variantKey(variant.Name()) {
+			return fmt.Errorf("variant %q is already known", variant.Name())
+		}
+	}
+
+	registered = append(registered, variant)
+	return nil
+}
+
+// ParseVariant finds a variant by its name, ignoring case, spaces and
+// dashes, e.g. "three-check" or "KingOfTheHill". An empty name is Standard.
+func ParseVariant(name string) (Variant, error) {
+	key := variantKey(name)
+	if key == "" {
+		return Standard, nil
+	}
+
+	for _, variant := range Variants() {
+		if variantKey(variant.Name()) == key {
+			return variant, nil
+		}
+	}
+
+	var names []string
+	for _, variant := range Variants() {
+		names = append(names, variant.Name())
+	}
+	return nil, fmt.Errorf("unknown variant %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+func variantKey(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// Variant returns the rules the board is played by.
+func (board Board) Variant() Variant {
+	return board.rules()
+}
+
+// SetVariant changes the rules the board is played by.
+func (board *Board) SetVariant(variant Variant) {
+	board.variant = variant
+	board.rules().setup(board)
+}
+
+func (board Board) rules() Variant {
+	if board.variant == nil {
+		return Standard
+	}
+
+	return board.variant
+}
+
+// VariantWinner returns the team that won by a rule of the variant, such as
+// the third check in Three-check, and the reason. toMove is the team to
+// play next.
+func (board Board) VariantWinner(toMove Team) (Team, string, bool) {
+	winner, reason := board.rules().winner(board, toMove)
+
+	return winner, reason, winner != Undecided
+}
+
+// Checks returns the number of checks the team has given, counted in
+// Three-check only.
+func (board Board) Checks(team Team) int {
+	return board.checks[team]
+}
+
+// isLegal checks a move that the piece can make against the variant.
+func (board Board) isLegal(move Move, team Team) bool {
+	rules := board.rules()
+
+	return rules.allows(board, move, team) && rules.legal(board, move, team)
+}
+
+// afterMoveBoard plays the move on a copy of the board.
+func (board Board) afterMoveBoard(move Move, team Team) *Board {
+	after := board.Clone()
+	after.makeMove(after.translate(move), team)
+
+	return after
+}
+
+type standard struct{}
+
+func (standard) Name() string {
+	return "Standard"
+}
+
+func (standard) StartFEN() string {
+	return StartFEN
+}
+
+func (standard) allows(board Board, move Move, team Team) bool {
+	return true
+}
+
+func (standard) legal(board Board, move Move, team Team) bool {
+	return !board.leavesKingInCheck(move, team)
+}
+
+func (standard) inCheck(board Board, team Team) bool {
+	return board.isKingAttacked(team)
+}
+
+func (standard) afterMove(board *Board, move Move, team Team, capture bool) {}
+
+func (standard) winner(board Board, toMove Team) (Team, string) {
+	return Undecided, ""
+}
+
+func (standard) pockets() bool {
+	return false
+}
+
+func (standard) promotions() []string {
+	return promotionLetters
+}
+
+func (standard) canDoubleStep(board Board, row int, team Team) bool {
+	return row == board.pawnRow(team)
+}
+
+func (standard) setup(board *Board) {}
+
+func (standard) validate(board Board) error {
+	return validateKings(board, 1, 1)
+}
+
+// validateKings checks the number of kings of each team, -1 for any.
+func validateKings(board Board, white, black int) error {
+	for team, want := range map[Team]int{White: white, Black: black} {
+		kings := 0
+		for _, piece := range board.getAllPiece(team) {
+			if isKing(piece) {
+				kings++
+			}
+		}
+		if want >= 0 && kings != want {
+			return fmt.Errorf("%s needs %d king(s), found %d", team, want, kings)
+		}
+	}
+
+	return nil
+}
+
+// pawnRow is the row pawns of the team start on.
+func (board Board) pawnRow(team Team) int {
+	if team == White {
+		return board.ranks - 2
+	}
+
+	return 1
+}
+
+// threeCheck is won by giving check for the third time.
+type threeCheck struct {
+	standard
+}
+
+const checksToWin = 3
+
+func (threeCheck) Name() string {
+	return "Three-check"
+}
+
+func (threeCheck) afterMove(board *Board, move Move, team Team, capture bool) {
+	if board.isKingAttacked(getOpponentTeam(team)) {
+		board.checks[team]++
+	}
+}
+
+func (threeCheck) winner(board Board, toMove Team) (Team, string) {
+	for _, team := range []Team{White, Black} {
+		if board.checks[team] >= checksToWin {
+			return team, "Three checks"
+		}
+	}
+
+	return Undecided, ""
+}
+
+// kingOfTheHill is also won by bringing the king to one of the four
+// centre squares, d4, e4, d5 and e5 on the standard board.
+type kingOfTheHill struct {
+	standard
+}
+
+func (kingOfTheHill) Name() string {
+	return "King of the Hill"
+}
+
+func (kingOfTheHill) winner(board Board, toMove Team) (Team, string) {
+	for _, team := range []Team{White, Black} {
+		if board.hasKing(team) && board.isCenter(board.getSquare(board.getKingPosition(team))) {
+			return team, "King reached the center"
+		}
+	}
+
+	return Undecided, ""
+}
+
+// isCenter reports whether the square is one of the middle squares, a
+// single one on each axis with an odd number of squares.
+func (board Board) isCenter(square *Square) bool {
+	return (board.ranks-1)/2 <= square.row && square.row <= board.ranks/2 &&
+		(board.files-1)/2 <= square.col && square.col <= board.files/2
+}
+
+// atomic makes every capture explode: the capturing piece and all pieces
+// but pawns around the captured one leave the board. Blowing up the enemy
+// king wins, kings cannot capture and touching kings cannot give check.
+type atomic struct {
+	standard
+}
+
+func (atomic) Name() string {
+	return "Atomic"
+}
+
+func (atomic) allows(board Board, move Move, team Team) bool {
+	return !isKing(*move.piece) || !move.squareTo.hasPiece()
+}
+
+func (atomic) legal(board Board, move Move, team Team) bool {
+	after := board.afterMoveBoard(move, team)
+
+	switch {
+	case !after.hasKing(team):
+		return false
+	case !after.hasKing(getOpponentTeam(team)):
+		return true
+	default:
+		return !after.InCheck(team)
+	}
+}
+
+func (atomic) inCheck(board Board, team Team) bool {
+	if !board.hasKing(team) || !board.hasKing(getOpponentTeam(team)) || board.kingsTouch() {
+		return false
+	}
+
+	return board.isKingAttacked(team)
+}
+
+func (atomic) afterMove(board *Board, move Move, team Team, capture bool) {
+	if !capture {
+		return
+	}
+
+	center := move.squareTo
+	for row := center.row - 1; row <= center.row+1; row++ {
+		for col := center.col - 1; col <= center.col+1; col++ {
+			if !board.onBoard(row, col) {
+				continue
+			}
+
+			square := &board.squares[row][col]
+			if !square.hasPiece() || square != center && isPawn(*square.piece) {
+				continue
+			}
+			if isKing(*square.piece) {
+				board.loseCastlingRights(square.piece.team)
+			}
+			board.loseCastlingRight(square)
+			board.captured(*square.piece)
+			square.setPiece(nil)
+		}
+	}
+}
+
+func (atomic) winner(board Board, toMove Team) (Team, string) {
+	for _, team := range []Team{White, Black} {
+		if !board.hasKing(team) {
+			return getOpponentTeam(team), "King exploded"
+		}
+	}
+
+	return Undecided, ""
+}
+
+func (board Board) kingsTouch() bool {
+	white, black := board.getSquare(board.getKingPosition(White)), board.getSquare(board.getKingPosition(Black))
+	dx, dy := white.col-black.col, white.row-black.row
+
+	return dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+}
+
+// crazyhouse lets a player drop a captured piece on an empty square instead
+// of moving.
+type crazyhouse struct {
+	standard
+}
+
+func (crazyhouse) Name() string {
+	return "Crazyhouse"
+}
+
+func (crazyhouse) pockets() bool {
+	return true
+}
+
+type bughouse struct {
+	crazyhouse
+}
+
+func (bughouse) Name() string {
+	return "Bughouse"
+}
+
+// antichess is won by losing every piece or being stalemated. Captures are
+// compulsory, the king is an ordinary piece that can be taken and pawns may
+// promote to it, and there is no check or castling.
+type antichess struct {
+	standard
+}
+
+func (antichess) Name() string {
+	return "Antichess"
+}
+
+func (antichess) StartFEN() string {
+	return "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w - - 0 1"
+}
+
+func (antichess) allows(board Board, move Move, team Team) bool {
+	return move.isCapture() || !board.canCapture(team)
+}
+
+func (antichess) legal(board Board, move Move, team Team) bool {
+	return true
+}
+
+func (antichess) inCheck(board Board, team Team) bool {
+	return false
+}
+
+func (antichess) winner(board Board, toMove Team) (Team, string) {
+	for _, team := range []Team{White, Black} {
+		if len(board.getAllPiece(team)) == 0 {
+			return team, "All pieces lost"
+		}
+	}
+
+	if len(board.legalMoves(toMove)) == 0 {
+		return toMove, "Stalemate"
+	}
+
+	return Undecided, ""
+}
+
+func (antichess) promotions() []string {
+	return append([]string{"k"}, promotionLetters...)
+}
+
+func (antichess) setup(board *Board) {
+	board.loseCastlingRights(White)
+	board.loseCastlingRights(Black)
+}
+
+func (antichess) validate(board Board) error {
+	return nil
+}
+
+// canCapture reports whether any piece of the team can take.
+func (board Board) canCapture(team Team) bool {
+	for _, piece := range board.getAllPiece(team) {
+		from := &board.squares[piece.row][piece.col]
+		for _, position := range getMoves(board, piece) {
+			if board.newMove(from.piece, from, board.getSquare(position)).isCapture() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// horde pits 36 white pawns without a king against the black army. Black
+// wins by taking every white piece, White by checkmate. White pawns on the
+// first rank may move two squares.
+type horde struct {
+	standard
+}
+
+func (horde) Name() string {
+	return "Horde"
+}
+
+func (horde) StartFEN() string {
+	return "rnbqkbnr/pppppppp/8/1PP2PP1/PPPPPPPP/PPPPPPPP/PPPPPPPP/PPPPPPPP w kq - 0 1"
+}
+
+func (horde) canDoubleStep(board Board, row int, team Team) bool {
+	return row == board.pawnRow(team) || team == White && row == board.homeRow(White)
+}
+
+func (horde) winner(board Board, toMove Team) (Team, string) {
+	if len(board.getAllPiece(White)) == 0 {
+		return Black, "All pieces captured"
+	}
+
+	return Undecided, ""
+}
+
+func (horde) validate(board Board) error {
+	return validateKings(board, 0, 1)
+}
+
+// VariantConfig describes a variant played by the standard rules from its
+// own start position, which sets the size of the board and the pieces,
+// fairy pieces included.
+type VariantConfig struct {
+	Name     string
+	StartFEN string
+	// Promotions are the letters pawns promote to, the queen, rook, bishop
+	// and knight when empty.
+	Promotions []string
+	// SingleStep keeps pawns from moving two squares.
+	SingleStep bool
+}
+
+type configured struct {
+	standard
+	config VariantConfig
+}
+
+// ConfigureVariant checks the config and returns its variant, which still
+// has to be registered to be found by name.
+func ConfigureVariant(config VariantConfig) (Variant, error) {
+	if variantKey(config.Name) == "" {
+		return nil, errors.New("variant: missing name")
+	}
+	if _, _, err := ParseFEN(config.StartFEN); err != nil {
+		return nil, fmt.Errorf("variant %s: %v", config.Name, err)
+	}
+	for _, letter := range config.Promotions {
+		if letter != strings.ToLower(letter) || !isPieceSign(letter) || letter == "p" {
+			return nil, fmt.Errorf("variant %s: cannot promote to %q", config.Name, letter)
+		}
+	}
+
+	return &configured{config: config}, nil
+}
+
+func (variant *configured) Name() string {
+	return variant.config.Name
+}
+
+func (variant *configured) StartFEN() string {
+	return variant.config.StartFEN
+}
+
+func (variant *configured) promotions() []string {
+	if len(variant.config.Promotions) == 0 {
+		return promotionLetters
+	}
+
+	return variant.config.Promotions
+}
+
+func (variant *configured) canDoubleStep(board Board, row int, team Team) bool {
+	return !variant.config.SingleStep && variant.standard.canDoubleStep(board, row, team)
+}
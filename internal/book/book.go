@@ -0,0 +1,299 @@
+// Package book reads and builds opening books in the Polyglot format: a
+// file of 16-byte entries sorted by the 64-bit key of the position, each
+// holding a move and its weight. Books only cover standard chess on the 8x8
+// board, Chess960 included.
+package book
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+var ErrUnsupported = errors.New("opening books only cover standard chess on the 8x8 board")
+
+// Entry is a move of a position in the book. Move is encoded as Polyglot
+// does, castling being the king taking its own rook.
+type Entry struct {
+	Key    uint64
+	Move   uint16
+	Weight uint16
+	Learn  uint32
+}
+
+const entrySize = 16
+
+// Book is an opening book held in memory, its entries sorted by key.
+type Book struct {
+	entries []Entry
+}
+
+// Open reads the Polyglot book at the path.
+func Open(path string) (*Book, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(bufio.NewReader(file))
+}
+
+// Read reads a Polyglot book.
+func Read(r io.Reader) (*Book, error) {
+	book := &Book{}
+
+	var buf [entrySize]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.New("book is not a whole number of entries")
+		}
+
+		book.entries = append(book.entries, Entry{
+			Key:    binary.BigEndian.Uint64(buf[0:8]),
+			Move:   binary.BigEndian.Uint16(buf[8:10]),
+			Weight: binary.BigEndian.Uint16(buf[10:12]),
+			Learn:  binary.BigEndian.Uint32(buf[12:16]),
+		})
+	}
+
+	book.sort()
+	return book, nil
+}
+
+// Write writes the book in the Polyglot format.
+func (book *Book) Write(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	var buf [entrySize]byte
+	for _, entry := range book.entries {
+		binary.BigEndian.PutUint64(buf[0:8], entry.Key)
+		binary.BigEndian.PutUint16(buf[8:10], entry.Move)
+		binary.BigEndian.PutUint16(buf[10:12], entry.Weight)
+		binary.BigEndian.PutUint32(buf[12:16], entry.Learn)
+		if _, err := writer.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
+
+// Len returns the number of entries.
+func (book *Book) Len() int {
+	return len(book.entries)
+}
+
+// sort orders the entries by key and the moves of a position by weight,
+// the heaviest first.
+func (book *Book) sort() {
+	sort.SliceStable(book.entries, func(i, j int) bool {
+		a, b := book.entries[i], book.entries[j]
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Weight > b.Weight
+	})
+}
+
+// Move is a book move of a position in the form accepted by Board.Execute.
+type Move struct {
+	Command string
+	Weight  int
+}
+
+// Moves returns the book moves of the team in the position, the heaviest
+// first. Entries whose move is not legal are left out.
+func (book *Book) Moves(b *board.Board, team board.Team) ([]Move, error) {
+	key, err := Key(b, team)
+	if err != nil {
+		return nil, err
+	}
+
+	i := sort.Search(len(book.entries), func(i int) bool {
+		return book.entries[i].Key >= key
+	})
+
+	// Polyglot moves are matched with the legal moves written the same way.
+	grid := b.Grid()
+	legal := make(map[uint16]string)
+	for _, command := range b.LegalMoves(team) {
+		legal[encodeMove(grid, command)] = command
+	}
+
+	var moves []Move
+	for ; i < len(book.entries) && book.entries[i].Key == key; i++ {
+		if command, ok := legal[book.entries[i].Move]; ok {
+			moves = append(moves, Move{Command: command, Weight: int(book.entries[i].Weight)})
+		}
+	}
+
+	return moves, nil
+}
+
+// Pick chooses a book move of the team at random, each move as likely as
+// its weight. It reports false when the book has no move with a weight.
+func (book *Book) Pick(b *board.Board, team board.Team, rng *rand.Rand) (string, bool) {
+	moves, err := book.Moves(b, team)
+	if err != nil {
+		return "", false
+	}
+
+	total := 0
+	for _, move := range moves {
+		total += move.Weight
+	}
+	if total == 0 {
+		return "", false
+	}
+
+	n := rng.Intn(total)
+	for _, move := range moves {
+		if n < move.Weight {
+			return move.Command, true
+		}
+		n -= move.Weight
+	}
+
+	return "", false
+}
+
+// Key returns the Polyglot key of the position with the team to move.
+func Key(b *board.Board, team board.Team) (uint64, error) {
+	if b.Files() != 8 || b.Ranks() != 8 || b.Variant() != board.Standard {
+		return 0, ErrUnsupported
+	}
+
+	grid := b.Grid()
+	var key uint64
+
+	for row := range grid {
+		for col, sign := range grid[row] {
+			if sign == "" {
+				continue
+			}
+			kind := strings.Index("pnbrqk", strings.ToLower(sign))
+			if kind < 0 {
+				return 0, ErrUnsupported
+			}
+			// Black pieces come first, ranks count from White's side.
+			index := 2 * kind
+			if isWhite(sign) {
+				index++
+			}
+			key ^= randoms[64*index+8*(7-row)+col]
+		}
+	}
+
+	fields := strings.Fields(b.FEN(team))
+	for _, right := range castlingRights(fields[2], grid) {
+		key ^= randoms[768+right]
+	}
+
+	// The en passant file counts only when a pawn of the side to move stands
+	// next to the pawn that moved two squares.
+	if square := fields[3]; square != "-" {
+		col := int(square[0] - 'a')
+		row := 3
+		pawn := "p"
+		if team == board.Black {
+			row, pawn = 4, "P"
+		}
+		if col > 0 && grid[row][col-1] == pawn || col < 7 && grid[row][col+1] == pawn {
+			key ^= randoms[772+col]
+		}
+	}
+
+	if team == board.White {
+		key ^= randoms[780]
+	}
+
+	return key, nil
+}
+
+// castlingRights maps the castling field of a FEN to the Polyglot rights,
+// 0 and 1 for White's short and long castling, 2 and 3 for Black's. The
+// Shredder and X-FEN rook files of Chess960 are compared with the king.
+func castlingRights(field string, grid [][]string) []int {
+	var rights []int
+
+	for _, letter := range field {
+		if letter == '-' {
+			continue
+		}
+
+		white := letter >= 'A' && letter <= 'Z'
+		row, king, side := 0, "K", 2
+		if white {
+			row, king, side = 7, "k", 0
+		}
+
+		lower := strings.ToLower(string(letter))
+		switch {
+		case lower == "k":
+		case lower == "q":
+			side++
+		default:
+			kingCol := -1
+			for col, sign := range grid[row] {
+				if sign == king {
+					kingCol = col
+				}
+			}
+			if int(lower[0]-'a') < kingCol {
+				side++
+			}
+		}
+		rights = append(rights, side)
+	}
+
+	return rights
+}
+
+// encodeMove writes a command in the 16 bits of a Polyglot move: the
+// destination file and rank, the origin file and rank and the promotion,
+// from the lowest bits up. Castling is written as the king taking its rook.
+func encodeMove(grid [][]string, command string) uint16 {
+	tokens := strings.Fields(command)
+	if len(tokens) < 2 {
+		return 0
+	}
+	from, to := tokens[0], tokens[1]
+	fromCol, fromRank := square(from)
+	toCol, toRank := square(to)
+
+	if piece := grid[8-fromRank][fromCol]; strings.ToLower(piece) == "k" && (toCol-fromCol == 2 || fromCol-toCol == 2) {
+		rookCol := 0
+		if toCol > fromCol {
+			rookCol = 7
+		}
+		toCol = rookCol
+	}
+
+	promotion := 0
+	if len(tokens) == 3 {
+		promotion = strings.Index("nbrq", strings.ToLower(tokens[2])) + 1
+	}
+
+	return uint16(toCol | (toRank-1)<<3 | fromCol<<6 | (fromRank-1)<<9 | promotion<<12)
+}
+
+// square returns the column and rank of a square such as "e4".
+func square(position string) (int, int) {
+	rank, _ := strconv.Atoi(position[1:])
+	return int(position[0] - 'a'), rank
+}
+
+func isWhite(sign string) bool {
+	return sign == strings.ToLower(sign)
+}
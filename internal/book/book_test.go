@@ -0,0 +1,58 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// TestKey plays the sequences of the Polyglot book format description and
+// checks the keys it gives, by number of moves played.
+func TestKey(t *testing.T) {
+	for _, line := range []struct {
+		moves []string
+		keys  map[int]uint64
+	}{
+		{
+			[]string{"e2 e4", "d7 d5", "e4 e5", "f7 f5", "e1 e2", "e8 f7"},
+			map[int]uint64{
+				0: 0x463b96181691fc9c,
+				1: 0x823c9b50fd114196,
+				2: 0x0756b94461c50fb0,
+				3: 0x662fafb965db29d4,
+				4: 0x22a48b5a8e47ff78,
+				5: 0x652a607ca3f242c1,
+				6: 0x00fdd303c946bdd9,
+			},
+		},
+		{
+			[]string{"a2 a4", "b7 b5", "h2 h4", "b5 b4", "c2 c4", "b4 c3", "a1 a3"},
+			map[int]uint64{
+				5: 0x3c8123ea7b067637,
+				7: 0x5c3f9b829b279560,
+			},
+		},
+	} {
+		b, team, err := board.ParseFEN(board.StartFEN)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for played := 0; played <= len(line.moves); played++ {
+			if played > 0 {
+				b.Execute(line.moves[played-1], team)
+				team = team.Opponent()
+			}
+			want, ok := line.keys[played]
+			if !ok {
+				continue
+			}
+			key, err := Key(b, team)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key != want {
+				t.Errorf("key after %v = %016x, want %016x", line.moves[:played], key, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package book
+
+import (
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// BuildOptions filters the moves a book is built from.
+type BuildOptions struct {
+	// MinGames leaves out the moves played in fewer games of a position.
+	MinGames int
+	// MaxPly stops reading each game after that many plies, 0 for all.
+	MaxPly int
+}
+
+// Build makes a book from the main lines of the games. A move weighs 2 for
+// every game its side won with it and 1 for every draw, as the Polyglot
+// builder counts. Games of other variants and games that cannot be
+// replayed are skipped; the number of games used is returned.
+func Build(games []pgn.Game, options BuildOptions) (*Book, int, error) {
+	type position struct {
+		key  uint64
+		move uint16
+	}
+	type tally struct {
+		games, points int
+	}
+
+	tallies := make(map[position]*tally)
+	var order []position
+	used := 0
+
+	for _, game := range games {
+		positions, err := game.Replay()
+		if err != nil || len(positions) == 0 {
+			continue
+		}
+		if _, err := Key(positions[0].Board, positions[0].Team); err != nil {
+			continue
+		}
+		used++
+
+		for i := 1; i < len(positions); i++ {
+			if options.MaxPly > 0 && i > options.MaxPly {
+				break
+			}
+
+			before, after := positions[i-1], positions[i]
+			key, err := Key(before.Board, after.Team)
+			if err != nil {
+				break
+			}
+
+			entry := position{key: key, move: encodeMove(before.Board.Grid(), after.Command)}
+			if tallies[entry] == nil {
+				tallies[entry] = &tally{}
+				order = append(order, entry)
+			}
+			tallies[entry].games++
+			tallies[entry].points += points(game.Result, after.Team)
+		}
+	}
+
+	// Weights are scaled down to fit 16 bits.
+	most := 0
+	for _, entry := range order {
+		most = max(most, tallies[entry].points)
+	}
+	scale := 1
+	for most/scale > 0xffff {
+		scale *= 2
+	}
+
+	book := &Book{}
+	for _, entry := range order {
+		count := tallies[entry]
+		if count.games < options.MinGames {
+			continue
+		}
+		book.entries = append(book.entries, Entry{Key: entry.key, Move: entry.move, Weight: uint16(count.points / scale)})
+	}
+
+	book.sort()
+	return book, used, nil
+}
+
+// points are the points the team scored in the game, doubled.
+func points(result string, team board.Team) int {
+	switch {
+	case result == "1/2-1/2":
+		return 1
+	case result == "1-0" && team == board.White, result == "0-1" && team == board.Black:
+		return 2
+	default:
+		return 0
+	}
+}
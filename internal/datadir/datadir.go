@@ -0,0 +1,74 @@
+// Package datadir locates and writes the files the program keeps for its
+// user, under ~/.chess_on_golang.
+package datadir
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Path is ~/.chess_on_golang/<name>, or .chess_on_golang/<name> in the
+// working directory when there is no home directory.
+func Path(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+
+	return filepath.Join(home, ".chess_on_golang", name)
+}
+
+// ErrBadName is returned for names that File does not accept.
+var ErrBadName = errors.New("names are letters, digits, - and _")
+
+// CheckName reports whether File accepts the name: ASCII letters, digits,
+// '-' and '_' only, so that no two names share a file unless they differ
+// only by case and none leaves the directory.
+func CheckName(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty name: %w", ErrBadName)
+	}
+	for _, char := range name {
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-' || char == '_') {
+			return fmt.Errorf("name %q: %w", name, ErrBadName)
+		}
+	}
+
+	return nil
+}
+
+// File is the file of a user's data named after them in the directory,
+// with the extension. Names are compared without case, so the name is
+// written in lower case.
+func File(dir, name, extension string) (string, error) {
+	if err := CheckName(name); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, strings.ToLower(name)+extension), nil
+}
+
+// WriteFile writes to a temporary file first and renames it over the file,
+// so that a crash never leaves a truncated file behind.
+func WriteFile(path string, data []byte) error {
+	temporary, err := WriteTemporary(path, data)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(temporary, path)
+}
+
+// WriteTemporary writes the data next to the file, to be renamed over it.
+func WriteTemporary(path string, data []byte) (string, error) {
+	temporary := path + ".tmp"
+	if err := os.WriteFile(temporary, data, 0o644); err != nil {
+		os.Remove(temporary)
+		return "", err
+	}
+
+	return temporary, nil
+}
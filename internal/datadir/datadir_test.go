@@ -0,0 +1,50 @@
+package datadir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "record.json")
+
+	for _, text := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(text)); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != text {
+			t.Errorf("file holds %q, want %q", data, text)
+		}
+	}
+
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
+
+func TestFile(t *testing.T) {
+	for name, want := range map[string]string{
+		"alice":   "alice.json",
+		"Alice":   "alice.json",
+		"a_b":     "a_b.json",
+		"Bob-2nd": "bob-2nd.json",
+	} {
+		path, err := File("dir", name, ".json")
+		if err != nil || path != filepath.Join("dir", want) {
+			t.Errorf("File(%q) = %q, %v, want %q", name, path, err, want)
+		}
+	}
+
+	// The Kelvin sign is written k in lower case.
+	for _, name := range []string{"", "a.b", "../x", `a\b`, "a b", "\u212Aelvin"} {
+		if _, err := File("dir", name, ".json"); !errors.Is(err, ErrBadName) {
+			t.Errorf("File(%q) err = %v, want ErrBadName", name, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package eco names the opening of a game with its code of the
+// Encyclopaedia of Chess Openings. Openings are found by position, so a
+// game that reaches one by another move order is named the same. The table
+// is the lichess chess-openings collection, which is in the public domain.
+package eco
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// openings holds a header and lines of the code, the name and the position
+// written as the first four FEN fields.
+//
+//go:embed openings.tsv
+var openings string
+
+var (
+	table     map[string]Opening
+	loadTable sync.Once
+)
+
+// Opening is an entry of the table. The name may carry a variation after a
+// colon, e.g. "Sicilian Defense: Najdorf Variation".
+type Opening struct {
+	ECO  string
+	Name string
+}
+
+func (opening Opening) String() string {
+	return opening.ECO + " " + opening.Name
+}
+
+// Family returns the name without the variation.
+func (opening Opening) Family() string {
+	family, _, _ := strings.Cut(opening.Name, ": ")
+	return family
+}
+
+// Variation returns the part of the name after the colon, empty for the
+// main line of the opening.
+func (opening Opening) Variation() string {
+	_, variation, _ := strings.Cut(opening.Name, ": ")
+	return variation
+}
+
+func positions() map[string]Opening {
+	loadTable.Do(func() {
+		table = make(map[string]Opening)
+		for i, line := range strings.Split(strings.TrimSpace(openings), "\n") {
+			fields := strings.Split(line, "\t")
+			if i == 0 || len(fields) != 3 {
+				continue
+			}
+			table[fields[2]] = Opening{ECO: fields[0], Name: fields[1]}
+		}
+	})
+
+	return table
+}
+
+// Lookup returns the opening of the position with the team to move.
+func Lookup(b *board.Board, team board.Team) (Opening, bool) {
+	if b.Variant() != board.Standard || b.Chess960() {
+		return Opening{}, false
+	}
+
+	opening, ok := positions()[b.PositionKey(team)]
+	return opening, ok
+}
+
+// Classify returns the opening of the deepest position of the main line
+// that is in the table.
+func Classify(game pgn.Game) (Opening, bool) {
+	replayed, _ := game.Replay()
+
+	var found Opening
+	ok := false
+	for i, position := range replayed {
+		team := position.Team
+		if i > 0 {
+			team = team.Opponent()
+		}
+		if opening, known := Lookup(position.Board, team); known {
+			found, ok = opening, true
+		}
+	}
+
+	return found, ok
+}
+
+// Tag fills the ECO, Opening and Variation tags of the game with the
+// opening Classify finds and reports whether there is one.
+func Tag(game *pgn.Game) bool {
+	opening, ok := Classify(*game)
+	if ok {
+		opening.Tag(game)
+	}
+
+	return ok
+}
+
+// Tag fills the ECO, Opening and Variation tags of the game, removing a
+// Variation tag left from another opening.
+func (opening Opening) Tag(game *pgn.Game) {
+	game.SetTag("ECO", opening.ECO)
+	game.SetTag("Opening", opening.Family())
+	if variation := opening.Variation(); variation != "" {
+		game.SetTag("Variation", variation)
+	} else {
+		game.DeleteTag("Variation")
+	}
+}
@@ -0,0 +1,34 @@
+package eco
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+func TestClassifyTransposition(t *testing.T) {
+	// The Russian Game (Petrov) reached with 2. Nf3 Nf6 after 1. e4 e5, and through
+	// 1. Nf3 Nf6 2. e4 e5.
+	for _, text := range []string{"1. e4 e5 2. Nf3 Nf6 *", "1. Nf3 Nf6 2. e4 e5 *"} {
+		games, err := pgn.Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opening, ok := Classify(games[0])
+		if !ok || opening.ECO != "C42" {
+			t.Errorf("Classify(%s) = %v, %v, want C42", text, opening, ok)
+		}
+	}
+}
+
+func TestTagClearsVariation(t *testing.T) {
+	game := pgn.Game{Tags: []pgn.Tag{{Name: "Variation", Value: "Najdorf Variation"}}}
+	Opening{ECO: "C42", Name: "Russian Game"}.Tag(&game)
+
+	if game.Tag("ECO") != "C42" || game.Tag("Opening") != "Russian Game" {
+		t.Errorf("tags = %+v, want C42 Russian Game", game.Tags)
+	}
+	if variation := game.Tag("Variation"); variation != "" {
+		t.Errorf("Variation tag = %q, want it removed", variation)
+	}
+}
@@ -0,0 +1,394 @@
+// Package engine searches positions of a board.Board for the best move. It
+// runs an alpha-beta search with iterative deepening, resolves captures at
+// the leaves and scores positions by material and piece placement.
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+)
+
+// Mate is the score of the side that mates at once. A mate in n plies
+// scores Mate-n, being mated in n plies -(Mate-n).
+const Mate = 100000
+
+// maxPly bounds the length of a searched line, captures included.
+const maxPly = 64
+
+// maxMate bounds the plies of a mate score, tablebases giving mates longer
+// than the searched lines.
+const maxMate = 512
+
+var ErrNoMoves = errors.New("no legal moves")
+
+// Limits bounds a search.
+type Limits struct {
+	// Depth is the number of plies searched before only captures are
+	// followed.
+	Depth int
+	// Time stops the search before the next depth once it has run out,
+	// zero for no limit.
+	Time time.Duration
+	// Tablebases, if not nil, score the endgames they hold exactly.
+	Tablebases *tablebase.Set
+}
+
+// Result is the outcome of a search. Score is in centipawns for the side
+// to move.
+type Result struct {
+	Move  string
+	Score int
+	// PV is the principal variation, the moves both sides are expected to
+	// play starting with Move.
+	PV    []string
+	Depth int
+	Nodes int
+}
+
+// Search finds the best move of the team, in the form accepted by
+// Board.Execute.
+func Search(b *board.Board, team board.Team, limits Limits) (Result, error) {
+	if len(b.LegalMoves(team)) == 0 {
+		return Result{}, ErrNoMoves
+	}
+	if _, _, over := b.VariantWinner(team); over {
+		return Result{}, ErrNoMoves
+	}
+
+	var deadline time.Time
+	if limits.Time > 0 {
+		deadline = time.Now().Add(limits.Time)
+	}
+
+	// The first depth runs without the deadline, so that there is always a
+	// move to play.
+	s := searcher{tablebases: limits.Tablebases}
+	var result Result
+	for depth := 1; depth <= max(limits.Depth, 1); depth++ {
+		score, pv := s.search(b, team, depth, 0, -Mate-1, Mate+1, result.PV)
+		if s.stopped {
+			break
+		}
+
+		result = Result{Move: pv[0], Score: score, PV: pv, Depth: depth}
+		s.deadline = deadline
+		if isMate(score) || s.timeIsUp() {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	return result, nil
+}
+
+// LineSAN writes a line of moves of the team and its opponent in turn in
+// Standard Algebraic Notation. It stops at the first move that is illegal.
+func LineSAN(b *board.Board, team board.Team, line []string) []string {
+	var sans []string
+	position := b.Clone()
+	for _, move := range line {
+		san, err := position.SAN(move, team)
+		if err != nil {
+			break
+		}
+		position.Execute(move, team)
+		sans = append(sans, san)
+		team = team.Opponent()
+	}
+
+	return sans
+}
+
+// FormatScore writes a score as pawns, e.g. "+0.35", or as a mate in moves,
+// "#3" for the side to move and "#-3" against it.
+func FormatScore(score int) string {
+	if !isMate(score) {
+		return fmt.Sprintf("%+.2f", float64(score)/100)
+	}
+
+	moves, _ := MateIn(score)
+	return fmt.Sprintf("#%d", moves)
+}
+
+// MateIn returns the number of moves to mate a score stands for, negative
+// when the side to move gets mated.
+func MateIn(score int) (int, bool) {
+	switch {
+	case !isMate(score):
+		return 0, false
+	case score > 0:
+		return (Mate - score + 1) / 2, true
+	default:
+		return -(Mate + score + 1) / 2, true
+	}
+}
+
+func isMate(score int) bool {
+	return score > Mate-maxMate || score < -Mate+maxMate
+}
+
+type searcher struct {
+	deadline   time.Time
+	nodes      int
+	stopped    bool
+	tablebases *tablebase.Set
+}
+
+func (s *searcher) timeIsUp() bool {
+	return !s.deadline.IsZero() && time.Now().After(s.deadline)
+}
+
+// search returns the score of the position for the team and the line that
+// leads to it, starting with the previous principal variation.
+func (s *searcher) search(b *board.Board, team board.Team, depth, ply, alpha, beta int, previous []string) (int, []string) {
+	s.nodes++
+	if s.nodes%256 == 0 && s.timeIsUp() {
+		s.stopped = true
+	}
+	if s.stopped {
+		return 0, nil
+	}
+
+	if score, over := terminal(b, team, ply); over {
+		return score, nil
+	}
+	// The root is searched for a move to play.
+	if ply > 0 {
+		if score, ok := s.probe(b, team, ply); ok {
+			return score, nil
+		}
+	}
+	if depth <= 0 || ply >= maxPly {
+		return s.quiesce(b, team, ply, alpha, beta), nil
+	}
+
+	moves := b.LegalMoves(team)
+	if len(moves) == 0 {
+		if b.InCheck(team) {
+			return -Mate + ply, nil
+		}
+		return 0, nil
+	}
+
+	var pv []string
+	for _, move := range order(b, moves, first(previous)) {
+		child := b.Clone()
+		child.Execute(move, team)
+
+		score, line := s.search(child, team.Opponent(), depth-1, ply+1, -beta, -alpha, rest(previous, move))
+		score = -score
+		if s.stopped {
+			return 0, nil
+		}
+
+		if score > alpha || pv == nil {
+			pv = append([]string{move}, line...)
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return alpha, pv
+}
+
+// quiesce follows captures and promotions until the position is quiet, so
+// that the evaluation is not taken in the middle of an exchange. In check
+// every move is tried.
+func (s *searcher) quiesce(b *board.Board, team board.Team, ply, alpha, beta int) int {
+	s.nodes++
+
+	if score, over := terminal(b, team, ply); over {
+		return score
+	}
+	if score, ok := s.probe(b, team, ply); ok {
+		return score
+	}
+
+	moves := b.LegalMoves(team)
+	inCheck := b.InCheck(team)
+	if len(moves) == 0 {
+		if inCheck {
+			return -Mate + ply
+		}
+		return 0
+	}
+
+	if !inCheck {
+		standPat := Evaluate(b, team)
+		if standPat >= beta || ply >= maxPly {
+			return standPat
+		}
+		alpha = max(alpha, standPat)
+	}
+
+	grid := b.Grid()
+	for _, move := range order(b, moves, "") {
+		if !inCheck && !isNoisy(grid, move) {
+			continue
+		}
+
+		child := b.Clone()
+		child.Execute(move, team)
+		score := -s.quiesce(child, team.Opponent(), ply+1, -beta, -alpha)
+
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	return alpha
+}
+
+// terminal scores a position the variant has decided.
+func terminal(b *board.Board, team board.Team, ply int) (int, bool) {
+	winner, _, over := b.VariantWinner(team)
+	switch {
+	case !over:
+		return 0, false
+	case winner == team:
+		return Mate - ply, true
+	case winner == board.Undecided:
+		return 0, true
+	default:
+		return -Mate + ply, true
+	}
+}
+
+// probe scores the position from the tablebases.
+func (s *searcher) probe(b *board.Board, team board.Team, ply int) (int, bool) {
+	if s.tablebases == nil {
+		return 0, false
+	}
+
+	result, err := s.tablebases.Probe(b, team)
+	if err != nil {
+		return 0, false
+	}
+
+	switch result.WDL {
+	case tablebase.Win:
+		return Mate - ply - result.DTM, true
+	case tablebase.Loss:
+		return -Mate + ply + result.DTM, true
+	default:
+		return 0, true
+	}
+}
+
+func first(line []string) string {
+	if len(line) == 0 {
+		return ""
+	}
+
+	return line[0]
+}
+
+// rest continues the previous principal variation below its first move.
+func rest(line []string, move string) []string {
+	if len(line) == 0 || line[0] != move {
+		return nil
+	}
+
+	return line[1:]
+}
+
+// order puts the move of the previous iteration first, then captures of
+// the most valuable pieces by the least valuable ones and promotions, then
+// the moves that bring a piece nearest to the centre.
+func order(b *board.Board, moves []string, best string) []string {
+	grid := b.Grid()
+	keys := make(map[string]int, len(moves))
+	for _, move := range moves {
+		from, to, promotion := parseMove(move)
+		piece, victim := pieceAt(grid, from), pieceAt(grid, to)
+		switch {
+		case move == best:
+			keys[move] = 1 << 20
+		case victim != "" && !sameTeam(victim, piece) || promotion != "":
+			keys[move] = 1<<16 + 10*value(victim) - value(piece) + value(promotion)
+		case from != "":
+			keys[move] = squareCentrality(grid, to) - squareCentrality(grid, from)
+		}
+	}
+
+	ordered := append([]string(nil), moves...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return keys[ordered[i]] > keys[ordered[j]]
+	})
+
+	return ordered
+}
+
+// isNoisy reports whether the move takes a piece or promotes a pawn.
+func isNoisy(grid [][]string, move string) bool {
+	from, to, promotion := parseMove(move)
+	if promotion != "" {
+		return true
+	}
+	if from == "" {
+		return false
+	}
+
+	piece, target := pieceAt(grid, from), pieceAt(grid, to)
+	if target != "" {
+		return !sameTeam(piece, target)
+	}
+
+	// A pawn moving to an empty square of another file takes en passant.
+	return strings.ToLower(piece) == "p" && from[0] != to[0]
+}
+
+// parseMove splits a command into its squares and promotion. from is empty
+// for a drop.
+func parseMove(move string) (from, to, promotion string) {
+	if _, position, ok := strings.Cut(move, "@"); ok {
+		return "", position, ""
+	}
+
+	tokens := strings.Fields(move)
+	if len(tokens) == 3 {
+		promotion = tokens[2]
+	}
+
+	return tokens[0], tokens[1], promotion
+}
+
+// pieceAt returns the sign on the square of the grid, row 0 being the last
+// rank.
+func pieceAt(grid [][]string, position string) string {
+	if position == "" {
+		return ""
+	}
+
+	rank, err := strconv.Atoi(position[1:])
+	if err != nil || rank < 1 || rank > len(grid) {
+		return ""
+	}
+
+	return grid[len(grid)-rank][position[0]-'a']
+}
+
+func squareCentrality(grid [][]string, position string) int {
+	rank, _ := strconv.Atoi(position[1:])
+	return centrality(len(grid)-rank, int(position[0]-'a'), len(grid[0]), len(grid))
+}
+
+// sameTeam reports whether two signs are of the same team, lowercase being
+// White.
+func sameTeam(a, b string) bool {
+	return a != "" && b != "" && white(a) == white(b)
+}
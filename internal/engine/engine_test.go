@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func TestSearchFindsMateInOne(t *testing.T) {
+	for _, test := range []struct {
+		fen, move string
+	}{
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", "a1 a8"},
+		{"r6k/8/8/8/8/8/5PPP/6K1 b - - 0 1", "a8 a1"},
+		{"r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - 0 1", "f3 f7"},
+	} {
+		b, team, err := board.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := Search(b, team, Limits{Depth: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Move != test.move {
+			t.Errorf("%s: best move %s, want the mate %s", test.fen, result.Move, test.move)
+		}
+		if moves, mate := MateIn(result.Score); !mate || moves != 1 {
+			t.Errorf("%s: score %s, want #1", test.fen, FormatScore(result.Score))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// values are the pieces in centipawns by lowercase letter, a fairy piece
+// missing here counts as a minor piece.
+var values = map[string]int{
+	"p": 100, "n": 320, "b": 330, "r": 500, "q": 900, "k": 0,
+	"a": 825, "c": 875, "z": 1250, "l": 260, "g": 250,
+}
+
+const (
+	// endgameMaterial is the value of the pieces on the board, pawns and
+	// kings left out, below which the king should come to the centre.
+	endgameMaterial = 1300
+	// checkBonus rewards each check given in Three-check.
+	checkBonus = 150
+)
+
+func value(sign string) int {
+	if v, ok := values[strings.ToLower(sign)]; ok {
+		return v
+	}
+
+	return 300
+}
+
+// Evaluate scores the position in centipawns for the team: material,
+// pieces near the centre and advanced pawns. It works on boards of any
+// size. In Antichess losing material is good and only material counts.
+func Evaluate(b *board.Board, team board.Team) int {
+	grid := b.Grid()
+	files, ranks := b.Files(), b.Ranks()
+
+	pieces := 0
+	for _, row := range grid {
+		for _, sign := range row {
+			if letter := strings.ToLower(sign); letter != "" && letter != "p" && letter != "k" {
+				pieces += value(sign)
+			}
+		}
+	}
+	endgame := pieces < endgameMaterial
+
+	score := 0
+	for row := range grid {
+		for col, sign := range grid[row] {
+			if sign == "" {
+				continue
+			}
+
+			piece := value(sign)
+			if b.Variant() != board.Antichess {
+				piece += placement(strings.ToLower(sign), white(sign), row, col, files, ranks, endgame)
+			}
+
+			if white(sign) == (team == board.White) {
+				score += piece
+			} else {
+				score -= piece
+			}
+		}
+	}
+
+	if b.Variant() == board.Crazyhouse || b.Variant() == board.Bughouse {
+		score += pocket(b, team) - pocket(b, team.Opponent())
+	}
+	score += checkBonus * (b.Checks(team) - b.Checks(team.Opponent()))
+
+	if b.Variant() == board.Antichess {
+		return -score
+	}
+	return score
+}
+
+// placement is the bonus of a piece for its square.
+func placement(letter string, isWhite bool, row, col, files, ranks int, endgame bool) int {
+	center := centrality(row, col, files, ranks)
+
+	switch letter {
+	case "p":
+		// Rows from the home row of the pawns, which is one in front of the
+		// pieces.
+		advance := ranks - 2 - row
+		if !isWhite {
+			advance = row - 1
+		}
+		advance = max(advance, 0)
+		return 5*advance + 2*advance*advance + files - 1 - abs(2*col-files+1)
+	case "k":
+		if endgame {
+			return 3 * center
+		}
+		return -2 * center
+	case "n":
+		return 3 * center
+	case "b":
+		return 2 * center
+	default:
+		return center
+	}
+}
+
+// centrality counts the half squares from the edges to the square, 0 in
+// the corner.
+func centrality(row, col, files, ranks int) int {
+	return files - 1 - abs(2*col-files+1) + ranks - 1 - abs(2*row-ranks+1)
+}
+
+func pocket(b *board.Board, team board.Team) int {
+	total := 0
+	for _, letter := range b.Pocket(team) {
+		total += value(letter)
+	}
+
+	return total
+}
+
+func white(sign string) bool {
+	return sign == strings.ToLower(sign)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,85 @@
+package game
+
+import (
+	"errors"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+var ErrUnknownBoard = errors.New("board must be 0 or 1")
+
+// BughouseMatch is a match of two teams of two on two boards. The partners
+// play opposite colours: White on board 0 and Black on board 1 are one team.
+// Every piece a player captures goes to the pocket of the partner, and the
+// match ends as soon as one of the games does.
+type BughouseMatch struct {
+	games [2]*ChessGame
+}
+
+// NewBughouse creates both games from the initial position.
+func NewBughouse() *BughouseMatch {
+	bughouse := &BughouseMatch{}
+	for i := range bughouse.games {
+		chessGame, _ := NewFromFEN(board.StartFEN)
+		chessGame.SetVariant(board.Bughouse)
+		bughouse.games[i] = chessGame
+	}
+
+	return bughouse
+}
+
+// Game returns the game on board 0 or 1, e.g. to attach a clock. Moves must
+// be played through BughouseMatch.Play.
+func (bughouse *BughouseMatch) Game(index int) *ChessGame {
+	if index < 0 || index >= len(bughouse.games) {
+		return nil
+	}
+
+	return bughouse.games[index]
+}
+
+// Play plays the move on the board and passes the captured piece to the
+// partner.
+func (bughouse *BughouseMatch) Play(index int, command string) error {
+	chessGame := bughouse.Game(index)
+	if chessGame == nil {
+		return ErrUnknownBoard
+	}
+	if _, over := bughouse.Result(); over {
+		return ErrGameOver
+	}
+
+	team := chessGame.Turn()
+	if err := chessGame.Play(command); err != nil {
+		return err
+	}
+
+	partner := bughouse.games[1-index]
+	chessGame.board.GivePocket(team, partner.board)
+	partner.notify()
+
+	if result, over := chessGame.Result(); over {
+		// The partner of the winner plays the other colour.
+		if result.Winner != board.Undecided {
+			result.Winner = result.Winner.Opponent()
+		}
+		partner.SetResult(result)
+	}
+
+	return nil
+}
+
+// Result returns the result of board 0; board 1 has the same one with the
+// colours swapped.
+func (bughouse *BughouseMatch) Result() (Result, bool) {
+	for i, chessGame := range bughouse.games {
+		if result, over := chessGame.Result(); over {
+			if i == 1 && result.Winner != board.Undecided {
+				result.Winner = result.Winner.Opponent()
+			}
+			return result, true
+		}
+	}
+
+	return Result{}, false
+}
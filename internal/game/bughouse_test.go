@@ -0,0 +1,56 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func TestBughouseCaptureGoesToPartner(t *testing.T) {
+	match := NewBughouse()
+	for _, move := range []string{"e2 e4", "d7 d5", "e4 d5"} {
+		if err := match.Play(0, move); err != nil {
+			t.Fatalf("board 0, %s: %v", move, err)
+		}
+	}
+
+	// White took a pawn on board 0, its partner plays Black on board 1.
+	if pocket := match.Game(0).Board().Pocket(board.White); len(pocket) != 0 {
+		t.Errorf("board 0 pocket of White = %v, want it handed over", pocket)
+	}
+	partner := match.Game(1)
+	if pocket := partner.Board().Pocket(board.Black); !reflect.DeepEqual(pocket, []string{"p"}) {
+		t.Fatalf("board 1 pocket of Black = %v, want [p]", pocket)
+	}
+
+	if err := match.Play(1, "e2 e4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := match.Play(1, "P@e5"); err != nil {
+		t.Fatalf("dropping the pawn on board 1: %v", err)
+	}
+	if pocket := partner.Board().Pocket(board.Black); len(pocket) != 0 {
+		t.Errorf("board 1 pocket of Black after the drop = %v", pocket)
+	}
+}
+
+func TestBughouseEndsBothBoards(t *testing.T) {
+	match := NewBughouse()
+	if err := match.Play(2, "e2 e4"); !errors.Is(err, ErrUnknownBoard) {
+		t.Errorf("playing on board 2: err = %v, want ErrUnknownBoard", err)
+	}
+
+	// Black resigns on board 1, so its partner, White on board 0, loses.
+	if err := match.Game(1).Resign(board.Black); err != nil {
+		t.Fatal(err)
+	}
+	result, over := match.Result()
+	if !over || result.Winner != board.Black {
+		t.Errorf("match result = %+v, %v, want Black winning on board 0", result, over)
+	}
+	if err := match.Play(0, "e2 e4"); !errors.Is(err, ErrGameOver) {
+		t.Errorf("playing on after the match ended: err = %v, want ErrGameOver", err)
+	}
+}
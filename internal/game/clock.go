@@ -0,0 +1,104 @@
+package game
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// TimeControl is the base time of each player and the increment added after
+// every move.
+type TimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+}
+
+// Clock is a chess clock. Only the side to move has its time running.
+type Clock struct {
+	control   TimeControl
+	remaining map[board.Team]time.Duration
+	running   board.Team
+	since     time.Time
+	now       func() time.Time
+}
+
+func NewClock(control TimeControl) *Clock {
+	return &Clock{
+		control: control,
+		remaining: map[board.Team]time.Duration{
+			board.White: control.Base,
+			board.Black: control.Base,
+		},
+		running: board.Undecided,
+		now:     time.Now,
+	}
+}
+
+func (clock *Clock) Control() TimeControl {
+	return clock.control
+}
+
+// Start runs the clock of the team, stopping the other one.
+func (clock *Clock) Start(team board.Team) {
+	clock.Stop()
+	clock.running = team
+	clock.since = clock.now()
+}
+
+// Stop stops whichever clock is running.
+func (clock *Clock) Stop() {
+	if clock.running == board.Undecided {
+		return
+	}
+
+	clock.remaining[clock.running] -= clock.now().Sub(clock.since)
+	clock.running = board.Undecided
+}
+
+// Punch ends the turn of the team: it gets its increment and the opponent's
+// clock starts.
+func (clock *Clock) Punch(team board.Team) {
+	clock.Stop()
+	clock.remaining[team] += clock.control.Increment
+	clock.Start(team.Opponent())
+}
+
+// Running returns the team whose clock is running, or Undecided.
+func (clock *Clock) Running() board.Team {
+	return clock.running
+}
+
+func (clock *Clock) Remaining(team board.Team) time.Duration {
+	remaining := clock.remaining[team]
+	if team == clock.running {
+		remaining -= clock.now().Sub(clock.since)
+	}
+
+	return remaining
+}
+
+// SetRemaining overrides the time of the team, e.g. when a game is resumed.
+func (clock *Clock) SetRemaining(team board.Team, remaining time.Duration) {
+	clock.remaining[team] = remaining
+	if team == clock.running {
+		clock.since = clock.now()
+	}
+}
+
+// Flagged reports the team whose time ran out, if any.
+func (clock *Clock) Flagged() (board.Team, bool) {
+	if clock.running != board.Undecided && clock.Remaining(clock.running) <= 0 {
+		return clock.running, true
+	}
+
+	return board.Undecided, false
+}
+
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/book"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+)
+
+// ComputerLimits bounds the search of a computer player.
+var ComputerLimits = engine.Limits{Depth: 5, Time: 3 * time.Second}
+
+// Computer plays one side: from the opening book while the position is in
+// it, then with the engine.
+type Computer struct {
+	Team   board.Team
+	Limits engine.Limits
+	// Book may be nil.
+	Book *book.Book
+	rng  *rand.Rand
+}
+
+// NewComputer creates a computer player of the team searching with
+// ComputerLimits. opening may be nil.
+func NewComputer(team board.Team, opening *book.Book) *Computer {
+	return &Computer{
+		Team:   team,
+		Limits: ComputerLimits,
+		Book:   opening,
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Move chooses the move of the computer in the position.
+func (computer *Computer) Move(b *board.Board) (string, error) {
+	if computer.Book != nil {
+		if move, ok := computer.Book.Pick(b, computer.Team, computer.rng); ok {
+			return move, nil
+		}
+	}
+
+	result, err := engine.Search(b, computer.Team, computer.Limits)
+	return result.Move, err
+}
+
+// SetComputer lets the computer play its side in the interactive loop.
+func (game *ChessGame) SetComputer(computer *Computer) {
+	game.computer = computer
+}
+
+// playComputer plays the move of the computer, whose turn it is.
+func (game *ChessGame) playComputer() bool {
+	move, err := game.computer.Move(game.board)
+	if err != nil {
+		fmt.Println(err)
+		return true
+	}
+
+	return game.execute(move)
+}
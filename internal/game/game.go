@@ -3,11 +3,15 @@ package game
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	chessongolang "github.com/DmitriyKolesnikM8O/chess_on_golang"
 	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/eco"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
 	"github.com/DmitriyKolesnikM8O/chess_on_golang/pkg/utils"
 )
 
@@ -16,7 +20,7 @@ func New() ChessGame {
 		board:       board.NewBoard(),
 		movesCount:  0,
 		currentTeam: board.Undecided,
-		reader:      *bufio.NewReader(os.Stdin),
+		reader:      bufio.NewReader(os.Stdin),
 	}
 }
 
@@ -32,82 +36,215 @@ func (game *ChessGame) SetupBoard(path string) utils.TestCase {
 }
 
 func (game ChessGame) PrintGameStatus() {
-	fmt.Println(game.board.String())
+	game.printGameStatus()
+}
+
+// SetRenderer makes the interactive loop print the board with the renderer
+// instead of Board.String.
+func (game *ChessGame) SetRenderer(renderer render.Renderer, view View) {
+	game.renderer = renderer
+	game.view = view
 }
 
 func (game *ChessGame) Start() {
+	game.SetupPlaybook()
+	game.Continue()
+}
+
+// SetupPlaybook places the pieces of the playbook at chessongolang.PATH,
+// White moves first.
+func (game *ChessGame) SetupPlaybook() {
 	game.SetupBoard(chessongolang.PATH)
+	game.currentTeam = board.White
+	game.startFEN = game.board.FEN(game.currentTeam)
+}
 
+// Continue runs the interactive loop from the current position, for games
+// created by NewFromFEN or restored from storage.
+func (game *ChessGame) Continue() {
+	if game.reader == nil {
+		game.reader = bufio.NewReader(os.Stdin)
+	}
+
+	game.notify()
 	game.PrintGameStatus()
 
 	for {
-		game.changeTurn(true)
+		if game.computer != nil && game.currentTeam == game.computer.Team {
+			if game.playComputer() {
+				return
+			}
+			continue
+		}
+
 		game.printAvailableMovesInCheck()
-		input := game.promtInput(game.reader)
+		game.printDrawStatus()
+		input, ok := game.promtInput(game.reader)
+		if !ok {
+			return
+		}
+
 		end := game.execute(input)
 		if end {
 			return
 		}
-
 	}
 }
 
+// execute plays a move or one of the commands resign, draw (to offer one),
+// accept, decline, claim, hint and threats.
 func (game *ChessGame) execute(command string) bool {
-	defer func() {
-		if err := recover(); err != nil {
-			fmt.Println(err)
-			game.changeTurn(false)
-		}
-	}()
+	team := game.currentTeam
 
-	checkmate := game.board.Execute(command, game.currentTeam)
+	var err error
+	switch strings.ToLower(strings.TrimSpace(command)) {
+	case "hint":
+		game.printSearch("Hint", game.board, team, game.Hint)
+		return false
+	case "threats":
+		game.printSearch("Threat", game.board.NullMove(), team.Opponent(), game.Threats)
+		return false
+	case "resign":
+		err = game.Resign(team)
+	case "draw":
+		if err = game.OfferDraw(team); err == nil {
+			fmt.Println(getTeamName(team), "offers a draw, the offer lapses after their next move.")
+			return false
+		}
+	case "accept":
+		err = game.AcceptDraw(team)
+	case "decline":
+		if err = game.DeclineDraw(team); err == nil {
+			fmt.Println(getTeamName(team), "declines the draw.")
+			return false
+		}
+	case "claim":
+		err = game.ClaimDraw(team)
+	default:
+		err = game.Play(command)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return game.result != nil
+	}
 
-	if checkmate {
-		game.endGameWithWinner(getTeamName(game.currentTeam), "Checkmate", command)
-		return true
+	result, over := game.Result()
+	if !over {
+		game.printAction(team, command)
+		game.printGameStatus()
+		return false
 	}
 
-	if game.IsTie() {
-		game.endGameByTie(command)
-		return true
+	if result.Winner == board.Undecided {
+		game.endGameByTie(team, command, result.Termination)
+	} else {
+		game.endGameWithWinner(getTeamName(result.Winner), result.Termination, team, command)
 	}
 
-	game.printAction(command)
-	game.printGameStatus()
-	return false
+	return true
 }
 
-func (game ChessGame) endGameByTie(lastCommand string) {
-	game.printAction(lastCommand)
+func (game ChessGame) endGameByTie(team board.Team, lastCommand string, reason string) {
+	game.printAction(team, lastCommand)
 	game.printGameStatus()
-	fmt.Println("Tie game.  Too many moves.")
+	fmt.Println("Tie game. ", reason)
 }
 
 func (game ChessGame) IsTie() bool {
 	return game.movesCount >= board.MovesLimitCount
 }
 
-func (game ChessGame) endGameWithWinner(winnerPlayer string, reason interface{}, lastCommand string) {
-	game.printAction(lastCommand)
+func (game ChessGame) endGameWithWinner(winnerPlayer string, reason interface{}, team board.Team, lastCommand string) {
+	game.printAction(team, lastCommand)
 	game.printGameStatus()
 	fmt.Println()
 	fmt.Println(winnerPlayer, "player wins. ", reason)
 }
 
 func (game ChessGame) printGameStatus() {
-	fmt.Println(game.board.String())
+	defer game.printOpening()
+	defer game.printVariantStatus()
+
+	if game.renderer == nil {
+		fmt.Println(game.board.String())
+		return
+	}
+
+	options := render.Options{
+		Perspective: game.view.Perspective,
+		Coordinates: game.view.Coordinates,
+	}
+	if options.Perspective == board.Undecided {
+		options.Perspective = game.currentTeam
+	}
+	if game.view.LastMove {
+		options.Highlight = render.LastMove(game.moves)
+	}
+
+	fmt.Println(game.renderer.Render(game.board, options))
 }
 
-func (game ChessGame) printAction(action string) {
-	fmt.Println(getTeamName(game.currentTeam), " player action: ", action)
+func (game ChessGame) printVariantStatus() {
+	switch game.board.Variant() {
+	case board.ThreeCheck:
+		fmt.Printf("Checks given: WHITE %d, BLACK %d\n", game.board.Checks(board.White), game.board.Checks(board.Black))
+	case board.Crazyhouse, board.Bughouse:
+		fmt.Printf("Pockets: WHITE %v, BLACK %v (drop with e.g. N@f3)\n", game.board.Pocket(board.White), game.board.Pocket(board.Black))
+	}
 }
 
-func (game ChessGame) promtInput(reader bufio.Reader) string {
+// printOpening names the opening when the position is in the ECO table.
+func (game ChessGame) printOpening() {
+	if opening, ok := eco.Lookup(game.board, game.currentTeam); ok {
+		fmt.Println("Opening:", opening)
+	}
+}
+
+// printSearch prints the best move of the team on the board found by the
+// search, its evaluation for the team and the line expected to follow.
+func (game *ChessGame) printSearch(label string, position *board.Board, team board.Team, search func() (engine.Result, error)) {
+	result, err := search()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	line := engine.LineSAN(position, team, result.PV)
+	fmt.Printf("%s: %s %s (depth %d), line: %s\n", label, line[0], engine.FormatScore(result.Score), result.Depth, strings.Join(line, " "))
+}
+
+// printDrawStatus tells the side to move about a pending draw offer and a
+// draw it may claim.
+func (game ChessGame) printDrawStatus() {
+	if game.drawOffer == game.currentTeam.Opponent() {
+		fmt.Println(getTeamName(game.drawOffer), "offers a draw: type accept or decline, or play a move.")
+	}
+	if termination, ok := game.DrawClaim(); ok {
+		fmt.Printf("You may claim a draw (%s): type claim.\n", strings.ToLower(termination))
+	}
+}
+
+func (game ChessGame) printAction(team board.Team, action string) {
+	fmt.Println(getTeamName(team), " player action: ", action)
+}
+
+// promtInput returns false once the input is closed.
+func (game ChessGame) promtInput(reader *bufio.Reader) (string, bool) {
+	if game.clock != nil {
+		fmt.Printf("[%s %s | %s %s] ",
+			"WHITE", formatClock(game.clock.Remaining(board.White)),
+			"BLACK", formatClock(game.clock.Remaining(board.Black)))
+	}
 	fmt.Print(getTeamName(game.currentTeam), "> ")
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimRight(input, "\n")
 
-	return input
+	input, err := reader.ReadString('\n')
+	if err == io.EOF && input == "" {
+		fmt.Println()
+		return "", false
+	}
+	input = strings.TrimRight(input, "\r\n")
+
+	return input, true
 }
 
 func (game *ChessGame) changeTurn(next bool) {
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"errors"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+)
+
+var ErrInCheck = errors.New("you are in check, the threat is on your king")
+
+// HintLimits bounds the search behind Hint and Threats.
+var HintLimits = engine.Limits{Depth: 5, Time: 2 * time.Second}
+
+// Hint searches the best move for the side to move. Hints and threats are
+// counted for the side that asks for them.
+func (game *ChessGame) Hint() (engine.Result, error) {
+	if game.result != nil {
+		return engine.Result{}, ErrGameOver
+	}
+
+	result, err := engine.Search(game.board, game.currentTeam, HintLimits)
+	if err != nil {
+		return engine.Result{}, err
+	}
+
+	game.countHint()
+	return result, nil
+}
+
+// Threats searches the move the opponent would play if it were its turn.
+func (game *ChessGame) Threats() (engine.Result, error) {
+	if game.result != nil {
+		return engine.Result{}, ErrGameOver
+	}
+	if game.board.InCheck(game.currentTeam) {
+		return engine.Result{}, ErrInCheck
+	}
+
+	result, err := engine.Search(game.board.NullMove(), game.currentTeam.Opponent(), HintLimits)
+	if err != nil {
+		return engine.Result{}, err
+	}
+
+	game.countHint()
+	return result, nil
+}
+
+// Hints returns the number of hints and threats the team asked for.
+func (game ChessGame) Hints(team board.Team) int {
+	return game.hints[team]
+}
+
+// SetHints restores the number of hints of the team, e.g. from storage.
+func (game *ChessGame) SetHints(team board.Team, count int) {
+	if game.hints == nil {
+		game.hints = make(map[board.Team]int)
+	}
+	game.hints[team] = count
+}
+
+func (game *ChessGame) countHint() {
+	game.SetHints(game.currentTeam, game.hints[game.currentTeam]+1)
+	game.notify()
+}
@@ -4,11 +4,70 @@ import (
 	"bufio"
 
 	. "github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
 )
 
 type ChessGame struct {
 	board       *Board
 	movesCount  int
 	currentTeam Team
-	reader      bufio.Reader
+	reader      *bufio.Reader
+	startFEN    string
+	history     []*Board
+	moves       []string
+	result      *Result
+	drawOffer   Team
+	// drawOfferPly is the number of moves played when the offer was made.
+	drawOfferPly int
+	// hints counts the hints and threats each team asked for.
+	hints    map[Team]int
+	computer *Computer
+	clock    *Clock
+	onChange func(*ChessGame)
+	renderer render.Renderer
+	view     View
 }
+
+// View configures how the interactive loop prints the board. A Perspective
+// of Undecided shows the board from the side to move.
+type View struct {
+	Perspective Team
+	Coordinates bool
+	LastMove    bool
+}
+
+// Result describes how a finished game ended. Winner is Undecided for draws.
+type Result struct {
+	Winner      Team
+	Termination string
+}
+
+// Score returns the result in PGN notation: "1-0", "0-1" or "1/2-1/2".
+func (result Result) Score() string {
+	switch result.Winner {
+	case White:
+		return "1-0"
+	case Black:
+		return "0-1"
+	default:
+		return "1/2-1/2"
+	}
+}
+
+const (
+	TerminationCheckmate   = "Checkmate"
+	TerminationStalemate   = "Stalemate"
+	TerminationMovesLimit  = "Too many moves"
+	TerminationResignation = "Resignation"
+	TerminationAgreement   = "Draw by agreement"
+	TerminationRepetition  = "Threefold repetition"
+	TerminationFiftyMoves  = "Fifty-move rule"
+	TerminationTimeForfeit = "Time forfeit"
+	// TerminationAdjudication ends a game decided by tablebases or by the
+	// scores of engines before it is over.
+	TerminationAdjudication = "Adjudication"
+	TerminationInsufficient = "Insufficient material"
+	// TerminationRulesInfraction ends a game lost by an illegal move or an
+	// engine that failed to move.
+	TerminationRulesInfraction = "Rules infraction"
+)
@@ -0,0 +1,80 @@
+package game
+
+import (
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/eco"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// PGN returns the game with its moves in SAN. The Variant tag names the
+// variant of non-standard games, FEN and SetUp the start position when it
+// is not the initial one.
+func (game ChessGame) PGN() pgn.Game {
+	record := pgn.Game{Result: "*"}
+	record.SetTag("Date", time.Now().Format("2006.01.02"))
+
+	variant := game.board.Variant().Name()
+	if variant == board.Standard.Name() && game.board.Chess960() {
+		variant = "Chess960"
+	}
+	if variant != board.Standard.Name() {
+		record.SetTag("Variant", variant)
+	}
+
+	if game.startFEN != "" && game.startFEN != game.board.Variant().StartFEN() {
+		record.SetTag("SetUp", "1")
+		record.SetTag("FEN", game.startFEN)
+	}
+
+	team := game.firstTeam()
+	for i, command := range game.moves {
+		san, err := game.history[i].SAN(command, team)
+		if err != nil {
+			san = command
+		}
+		record.Moves = append(record.Moves, pgn.Move{SAN: san})
+		team = team.Opponent()
+	}
+
+	if opening, ok := game.Opening(); ok {
+		opening.Tag(&record)
+	}
+
+	if result, over := game.Result(); over {
+		record.Result = result.Score()
+		record.SetTag("Termination", result.Termination)
+	}
+	record.SetTag("Result", record.Result)
+
+	return record
+}
+
+// Opening returns the opening of the deepest position of the game that is
+// in the ECO table.
+func (game ChessGame) Opening() (eco.Opening, bool) {
+	team := game.currentTeam
+	if opening, ok := eco.Lookup(game.board, team); ok {
+		return opening, true
+	}
+
+	for i := len(game.history) - 1; i >= 0; i-- {
+		team = team.Opponent()
+		if opening, ok := eco.Lookup(game.history[i], team); ok {
+			return opening, true
+		}
+	}
+
+	return eco.Opening{}, false
+}
+
+// firstTeam returns the side that made the first move.
+func (game ChessGame) firstTeam() board.Team {
+	team := game.currentTeam
+	if len(game.moves)%2 == 1 {
+		team = team.Opponent()
+	}
+
+	return team
+}
@@ -0,0 +1,395 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+var (
+	ErrGameOver      = errors.New("game is over")
+	ErrNothingToUndo = errors.New("no move to undo")
+	ErrNoDrawOffer   = errors.New("no draw offer from the opponent")
+	ErrNoDrawClaim   = errors.New("no draw to claim: the position has not occurred three times and there were fewer than fifty moves without a capture or pawn move")
+	ErrNotYourTurn   = errors.New("not your turn")
+	ErrUnknownTeam   = errors.New("team must be white or black")
+	ErrGameStarted   = errors.New("game has already started")
+)
+
+// IllegalMoveError is returned by Play when the board rejects the move.
+type IllegalMoveError struct {
+	Move   string
+	Reason string
+}
+
+func (err IllegalMoveError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Move, err.Reason)
+}
+
+// NewFromFEN creates a game driven through Play instead of the interactive
+// Start loop. An empty fen starts from the initial position.
+func NewFromFEN(fen string) (*ChessGame, error) {
+	return NewVariant(fen, board.Standard)
+}
+
+// NewVariant is NewFromFEN for a game of the variant. An empty fen starts
+// from the initial position of the variant.
+func NewVariant(fen string, variant board.Variant) (*ChessGame, error) {
+	if fen == "" {
+		fen = variant.StartFEN()
+	}
+
+	b, team, err := board.ParseVariantFEN(fen, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChessGame{
+		board:       b,
+		currentTeam: team,
+		startFEN:    fen,
+	}, nil
+}
+
+// NewChess960 creates a Fischer Random game from the start position with
+// the given number, 0 to 959, or a random one when the number is negative.
+// Castlings are played as the king taking its own rook, e.g. "b1 a1".
+func NewChess960(index int) (*ChessGame, error) {
+	var fen string
+	if index < 0 {
+		fen, _ = board.RandomChess960FEN()
+	} else {
+		var err error
+		if fen, err = board.Chess960FEN(index); err != nil {
+			return nil, err
+		}
+	}
+
+	b, team, err := board.ParseFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	b.SetChess960(true)
+
+	// Shredder-FEN keeps the game Chess960 when it is restored from startFEN.
+	return &ChessGame{
+		board:       b,
+		currentTeam: team,
+		startFEN:    b.ShredderFEN(team),
+	}, nil
+}
+
+// Play executes the move of the side to move and passes the turn.
+func (game *ChessGame) Play(command string) (err error) {
+	if game.result != nil || game.CheckTime() {
+		return ErrGameOver
+	}
+
+	snapshot := game.board.Clone()
+	defer func() {
+		if r := recover(); r != nil {
+			game.board = snapshot
+			err = IllegalMoveError{Move: command, Reason: fmt.Sprint(r)}
+		}
+	}()
+
+	checkmate := game.board.Execute(command, game.currentTeam)
+
+	// An offer lapses with the next move of the side that made it.
+	if game.drawOffer == game.currentTeam && len(game.moves) > game.drawOfferPly {
+		game.drawOffer = board.Undecided
+	}
+	game.history = append(game.history, snapshot)
+	game.moves = append(game.moves, command)
+
+	opponent := game.currentTeam.Opponent()
+	winner, reason, variantWin := game.board.VariantWinner(opponent)
+	switch {
+	case variantWin:
+		game.result = &Result{Winner: winner, Termination: reason}
+	case checkmate:
+		game.result = &Result{Winner: game.currentTeam, Termination: TerminationCheckmate}
+	case game.board.InStalemate(opponent):
+		game.result = &Result{Winner: board.Undecided, Termination: TerminationStalemate}
+	case game.movesCount+1 >= board.MovesLimitCount:
+		game.result = &Result{Winner: board.Undecided, Termination: TerminationMovesLimit}
+	}
+
+	if game.clock != nil {
+		game.clock.Punch(game.currentTeam)
+		if game.result != nil {
+			game.clock.Stop()
+		}
+	}
+
+	game.changeTurn(true)
+	game.notify()
+	return nil
+}
+
+// SetVariant changes the rules of the game, which is only possible before
+// the first move. Use NewVariant for variants with their own start
+// position or kings.
+func (game *ChessGame) SetVariant(variant board.Variant) error {
+	if len(game.moves) > 0 {
+		return ErrGameStarted
+	}
+
+	game.board.SetVariant(variant)
+	return nil
+}
+
+func (game ChessGame) Variant() board.Variant {
+	return game.board.Variant()
+}
+
+// SetClock attaches a clock to the game. It starts running with the first move.
+func (game *ChessGame) SetClock(clock *Clock) {
+	game.clock = clock
+}
+
+func (game ChessGame) Clock() *Clock {
+	return game.clock
+}
+
+// CheckTime ends the game if the side to move ran out of time and reports
+// whether it did.
+func (game *ChessGame) CheckTime() bool {
+	if game.clock == nil || game.result != nil {
+		return false
+	}
+
+	team, flagged := game.clock.Flagged()
+	if !flagged {
+		return false
+	}
+
+	game.clock.Stop()
+	game.result = &Result{Winner: team.Opponent(), Termination: TerminationTimeForfeit}
+	game.notify()
+	return true
+}
+
+// Undo takes back the last move. Games ended by resignation or agreement
+// cannot be taken back.
+func (game *ChessGame) Undo() error {
+	if len(game.history) == 0 {
+		return ErrNothingToUndo
+	}
+	if game.result != nil && (game.result.Termination == TerminationResignation || game.result.Termination == TerminationAgreement) {
+		return ErrGameOver
+	}
+
+	last := len(game.history) - 1
+	game.board = game.history[last]
+	game.history = game.history[:last]
+	game.moves = game.moves[:last]
+	game.result = nil
+	game.drawOffer = board.Undecided
+	game.changeTurn(false)
+	if game.clock != nil {
+		// The clock only runs once the first move was made.
+		if len(game.history) > 0 {
+			game.clock.Start(game.currentTeam)
+		} else {
+			game.clock.Stop()
+		}
+	}
+
+	game.notify()
+	return nil
+}
+
+func (game *ChessGame) Resign(team board.Team) error {
+	if team != board.White && team != board.Black {
+		return ErrUnknownTeam
+	}
+	if game.result != nil {
+		return ErrGameOver
+	}
+
+	game.result = &Result{Winner: team.Opponent(), Termination: TerminationResignation}
+	game.stopClock()
+	game.notify()
+	return nil
+}
+
+// OfferDraw records a draw offer. It stays open until the opponent accepts
+// or declines it, or until the offering side has made its next move.
+func (game *ChessGame) OfferDraw(team board.Team) error {
+	if team != board.White && team != board.Black {
+		return ErrUnknownTeam
+	}
+	if game.result != nil {
+		return ErrGameOver
+	}
+
+	game.drawOffer = team
+	game.drawOfferPly = len(game.moves)
+	game.notify()
+	return nil
+}
+
+func (game *ChessGame) AcceptDraw(team board.Team) error {
+	if team != board.White && team != board.Black {
+		return ErrUnknownTeam
+	}
+	if game.result != nil {
+		return ErrGameOver
+	}
+	if game.drawOffer != team.Opponent() {
+		return ErrNoDrawOffer
+	}
+
+	game.result = &Result{Winner: board.Undecided, Termination: TerminationAgreement}
+	game.drawOffer = board.Undecided
+	game.stopClock()
+	game.notify()
+	return nil
+}
+
+func (game *ChessGame) DeclineDraw(team board.Team) error {
+	if team != board.White && team != board.Black {
+		return ErrUnknownTeam
+	}
+	if game.result != nil {
+		return ErrGameOver
+	}
+	if game.drawOffer != team.Opponent() {
+		return ErrNoDrawOffer
+	}
+
+	game.drawOffer = board.Undecided
+	game.notify()
+	return nil
+}
+
+// ClaimDraw ends the game in a draw when the side to move claims a
+// threefold repetition or the fifty-move rule.
+func (game *ChessGame) ClaimDraw(team board.Team) error {
+	if team != board.White && team != board.Black {
+		return ErrUnknownTeam
+	}
+	if game.result != nil {
+		return ErrGameOver
+	}
+	if team != game.currentTeam {
+		return ErrNotYourTurn
+	}
+
+	termination, ok := game.DrawClaim()
+	if !ok {
+		return ErrNoDrawClaim
+	}
+
+	game.result = &Result{Winner: board.Undecided, Termination: termination}
+	game.drawOffer = board.Undecided
+	game.stopClock()
+	game.notify()
+	return nil
+}
+
+// DrawClaim returns the rule the side to move may claim a draw by, if any.
+func (game ChessGame) DrawClaim() (string, bool) {
+	switch {
+	case game.Repetitions() >= 3:
+		return TerminationRepetition, true
+	case game.board.HalfmoveClock() >= 100:
+		return TerminationFiftyMoves, true
+	default:
+		return "", false
+	}
+}
+
+// Repetitions returns how many times the current position has occurred
+// with the same side to move, this time included.
+func (game ChessGame) Repetitions() int {
+	key := game.board.PositionKey(game.currentTeam)
+
+	count := 1
+	team := game.currentTeam
+	for i := len(game.history) - 1; i >= 0; i-- {
+		team = team.Opponent()
+		if team == game.currentTeam && game.history[i].PositionKey(team) == key {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (game ChessGame) Board() *board.Board {
+	return game.board
+}
+
+func (game ChessGame) Turn() board.Team {
+	return game.currentTeam
+}
+
+func (game ChessGame) Moves() []string {
+	return append([]string{}, game.moves...)
+}
+
+func (game ChessGame) DrawOffer() board.Team {
+	return game.drawOffer
+}
+
+// Result returns the result of the game and whether the game is over.
+func (game ChessGame) Result() (Result, bool) {
+	if game.result == nil {
+		return Result{}, false
+	}
+
+	return *game.result, true
+}
+
+func (game ChessGame) FEN() string {
+	return game.board.FEN(game.currentTeam)
+}
+
+// LegalMoves returns the moves available to the side to move, or none once
+// the game is over.
+func (game ChessGame) LegalMoves() []string {
+	if game.result != nil {
+		return []string{}
+	}
+
+	moves := game.board.LegalMoves(game.currentTeam)
+	if moves == nil {
+		return []string{}
+	}
+
+	return moves
+}
+
+func (game *ChessGame) stopClock() {
+	if game.clock != nil {
+		game.clock.Stop()
+	}
+}
+
+// OnChange registers a function called whenever the game changes, e.g. to
+// persist it after every move. The interactive loop also calls it once when
+// it starts.
+func (game *ChessGame) OnChange(onChange func(*ChessGame)) {
+	game.onChange = onChange
+}
+
+func (game *ChessGame) notify() {
+	if game.onChange != nil {
+		game.onChange(game)
+	}
+}
+
+// StartFEN returns the position the game started from.
+func (game ChessGame) StartFEN() string {
+	return game.startFEN
+}
+
+// SetResult ends the game with a result that cannot be derived from the
+// moves, e.g. a resignation of a game restored from storage.
+func (game *ChessGame) SetResult(result Result) {
+	game.result = &result
+	game.stopClock()
+}
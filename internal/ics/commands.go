@@ -0,0 +1,659 @@
+package ics
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/rating"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+const helpText = `Commands:
+  who                      list logged in players
+  seek [min [inc]]         look for a game, 0 minutes for no clock
+  seeks / unseek           list seeks / withdraw yours
+  play <seek>              accept a seek
+  match <player> [min [inc]]  challenge a player
+  accept|decline <player>  answer a challenge
+  games                    list running games
+  observe|unobserve <game> watch a game
+  e2 e4                    play a move
+  e7 e8 n                  promote, to a queen without the letter
+  board / moves            show the board / the moves
+  resign / draw            resign / offer or accept a draw
+  style ascii|unicode|ansi choose how boards are drawn
+  ratings [player]         show the ratings of a player
+  top [category]           leaderboard of bullet, blitz, rapid or classical
+  tell <player> <text>     private message
+  say <text>               message your opponent
+  shout <text>             message everybody
+  quit                     log out
+`
+
+var movePattern = regexp.MustCompile(`^[a-h][1-8] [a-h][1-8]( [qrbnQRBN])?$`)
+
+func (server *Server) dispatch(s *session, line string) {
+	if movePattern.MatchString(line) {
+		server.move(s, line)
+		return
+	}
+
+	fields := strings.Fields(line)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "help":
+		s.send(helpText)
+	case "who":
+		server.who(s)
+	case "seek":
+		server.seek(s, args)
+	case "seeks":
+		server.listSeeks(s)
+	case "unseek":
+		server.removeSeeks(s)
+		s.send("Your seeks were removed.\n")
+	case "play":
+		server.play(s, args)
+	case "match":
+		server.challenge(s, args)
+	case "accept":
+		server.accept(s, args)
+	case "decline":
+		server.decline(s, args)
+	case "games":
+		server.listGames(s)
+	case "observe":
+		server.observe(s, args, true)
+	case "unobserve":
+		server.observe(s, args, false)
+	case "board":
+		server.showBoard(s)
+	case "moves":
+		server.showMoves(s)
+	case "resign":
+		server.resign(s)
+	case "draw":
+		server.draw(s)
+	case "style":
+		server.style(s, args)
+	case "ratings":
+		server.showRatings(s, args)
+	case "top":
+		server.showLeaderboard(s, args)
+	case "tell":
+		server.tell(s, args)
+	case "say":
+		server.say(s, args)
+	case "shout":
+		server.shout(s, args)
+	default:
+		s.send(fmt.Sprintf("%s: command not found. Type \"help\".\n", name))
+	}
+}
+
+func (server *Server) who(s *session) {
+	var names []string
+	for _, other := range server.sessions {
+		name := other.name
+		if other.match != nil {
+			name += fmt.Sprintf(" (playing game %d)", other.match.id)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.send(fmt.Sprintf("%d players logged in:\n  %s\n", len(names), strings.Join(names, "\n  ")))
+}
+
+func (server *Server) seek(s *session, args []string) {
+	if s.match != nil {
+		s.send("You are already playing.\n")
+		return
+	}
+
+	control, ok := parseTimeControl(args)
+	if !ok {
+		s.send("Usage: seek [minutes [increment]]\n")
+		return
+	}
+
+	for _, other := range server.seeks {
+		if other.owner != s && other.control == control {
+			server.start(other.owner, s, control)
+			return
+		}
+	}
+
+	server.nextSeek++
+	server.seeks = append(server.seeks, &seek{id: server.nextSeek, owner: s, control: control})
+	server.broadcast(fmt.Sprintf("%s seeks %s (\"play %d\" to respond)\n", s.name, formatControl(control), server.nextSeek))
+}
+
+func (server *Server) listSeeks(s *session) {
+	if len(server.seeks) == 0 {
+		s.send("No seeks.\n")
+		return
+	}
+
+	for _, sk := range server.seeks {
+		s.send(fmt.Sprintf("%3d %-16s %s\n", sk.id, sk.owner.name, formatControl(sk.control)))
+	}
+}
+
+func (server *Server) removeSeeks(s *session) {
+	seeks := server.seeks[:0]
+	for _, sk := range server.seeks {
+		if sk.owner != s {
+			seeks = append(seeks, sk)
+		}
+	}
+	server.seeks = seeks
+}
+
+func (server *Server) play(s *session, args []string) {
+	if s.match != nil {
+		s.send("You are already playing.\n")
+		return
+	}
+	if len(args) != 1 {
+		s.send("Usage: play <seek>\n")
+		return
+	}
+
+	id, _ := strconv.Atoi(args[0])
+	for _, sk := range server.seeks {
+		if sk.id == id && sk.owner != s {
+			server.start(sk.owner, s, sk.control)
+			return
+		}
+	}
+
+	s.send("No such seek.\n")
+}
+
+func (server *Server) challenge(s *session, args []string) {
+	if len(args) == 0 {
+		s.send("Usage: match <player> [minutes [increment]]\n")
+		return
+	}
+
+	opponent, ok := server.sessions[key(args[0])]
+	if !ok || opponent == s {
+		s.send("No such player.\n")
+		return
+	}
+	if s.match != nil || opponent.match != nil {
+		s.send("One of you is already playing.\n")
+		return
+	}
+
+	control, ok := parseTimeControl(args[1:])
+	if !ok {
+		s.send("Usage: match <player> [minutes [increment]]\n")
+		return
+	}
+
+	if opponent.challenges == nil {
+		opponent.challenges = make(map[string]game.TimeControl)
+	}
+	opponent.challenges[key(s.name)] = control
+
+	opponent.send(fmt.Sprintf("\n%s challenges you to a %s game (\"accept %s\" or \"decline %s\").\n", s.name, formatControl(control), s.name, s.name))
+	s.send(fmt.Sprintf("Challenge sent to %s.\n", opponent.name))
+}
+
+func (server *Server) accept(s *session, args []string) {
+	if len(args) != 1 {
+		s.send("Usage: accept <player>\n")
+		return
+	}
+
+	control, ok := s.challenges[key(args[0])]
+	challenger := server.sessions[key(args[0])]
+	if !ok || challenger == nil {
+		s.send("No challenge from " + args[0] + ".\n")
+		return
+	}
+	delete(s.challenges, key(args[0]))
+
+	if s.match != nil || challenger.match != nil {
+		s.send("One of you is already playing.\n")
+		return
+	}
+
+	server.start(challenger, s, control)
+}
+
+func (server *Server) decline(s *session, args []string) {
+	if len(args) != 1 {
+		s.send("Usage: decline <player>\n")
+		return
+	}
+
+	if _, ok := s.challenges[key(args[0])]; !ok {
+		s.send("No challenge from " + args[0] + ".\n")
+		return
+	}
+	delete(s.challenges, key(args[0]))
+
+	if challenger := server.sessions[key(args[0])]; challenger != nil {
+		challenger.send(fmt.Sprintf("\n%s declines your challenge.\n", s.name))
+	}
+	s.send("Challenge declined.\n")
+}
+
+// start creates a game between two sessions with random colours.
+func (server *Server) start(a, b *session, control game.TimeControl) {
+	server.removeSeeks(a)
+	server.removeSeeks(b)
+
+	if rand.Intn(2) == 0 {
+		a, b = b, a
+	}
+
+	chessGame, _ := game.NewFromFEN("")
+	if control.Base > 0 {
+		chessGame.SetClock(game.NewClock(control))
+	}
+
+	server.nextGame++
+	m := &match{
+		id:        server.nextGame,
+		white:     a,
+		black:     b,
+		game:      chessGame,
+		observers: make(map[*session]bool),
+	}
+	server.games[m.id] = m
+
+	a.match, a.team = m, board.White
+	b.match, b.team = m, board.Black
+
+	header := fmt.Sprintf("\nGame %d: %s (white) vs %s (black), %s.\n", m.id, a.name, b.name, formatControl(control))
+	for _, player := range []*session{a, b} {
+		player.send(header)
+		player.send(server.render(m, player))
+	}
+}
+
+func (server *Server) move(s *session, command string) {
+	m := s.match
+	if m == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+	if m.game.Turn() != s.team {
+		s.send("It is not your move.\n")
+		return
+	}
+
+	if err := m.game.Play(command); err != nil {
+		s.send(err.Error() + "\n")
+		// The move may have come after the flag fell.
+		server.finishIfOver(m)
+		return
+	}
+
+	header := fmt.Sprintf("\n{Game %d} %s moves %s\n", m.id, s.name, command)
+	for _, viewer := range m.audience() {
+		viewer.send(header + server.render(m, viewer))
+	}
+
+	server.finishIfOver(m)
+}
+
+func (server *Server) resign(s *session) {
+	if s.match == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+
+	m := s.match
+	m.game.Resign(s.team)
+	server.finishIfOver(m)
+}
+
+func (server *Server) draw(s *session) {
+	m := s.match
+	if m == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+
+	if m.game.DrawOffer() == s.team.Opponent() {
+		m.game.AcceptDraw(s.team)
+		server.finishIfOver(m)
+		return
+	}
+
+	if err := m.game.OfferDraw(s.team); err != nil {
+		s.send(err.Error() + "\n")
+		return
+	}
+
+	m.opponent(s).send(fmt.Sprintf("\n%s offers a draw (\"draw\" to accept).\n", s.name))
+	s.send("Draw offer sent.\n")
+}
+
+// abandon forfeits the game of a player who disconnected.
+func (server *Server) abandon(s *session) {
+	m := s.match
+	m.game.Resign(s.team)
+	m.opponent(s).send(fmt.Sprintf("\n%s has disconnected.\n", s.name))
+	server.finishIfOver(m)
+}
+
+// checkClocks flags the games whose time ran out and finishes every game
+// that is over.
+func (server *Server) checkClocks() {
+	for _, m := range server.games {
+		m.game.CheckTime()
+		server.finishIfOver(m)
+	}
+}
+
+func (server *Server) finishIfOver(m *match) {
+	result, over := m.game.Result()
+	if !over {
+		return
+	}
+
+	text := fmt.Sprintf("\n{Game %d %s vs %s} %s\n", m.id, m.white.name, m.black.name, describeResult(result))
+	for _, viewer := range m.audience() {
+		viewer.send(text)
+	}
+
+	if text := server.rate(m); text != "" {
+		for _, viewer := range m.audience() {
+			viewer.send(text)
+		}
+	}
+
+	m.white.match, m.black.match = nil, nil
+	delete(server.games, m.id)
+}
+
+// rate updates the ratings of the players of a finished game and describes
+// the changes.
+func (server *Server) rate(m *match) string {
+	if server.ratings == nil {
+		return ""
+	}
+
+	category := rating.CategoryOfGame(m.game)
+	before := make(map[string]string)
+	for _, player := range []*session{m.white, m.black} {
+		if rated, err := server.ratings.Player(player.name); err == nil {
+			before[player.name] = describeRating(rated.Rating(category))
+		}
+	}
+
+	white, black, err := server.ratings.Rate(m.white.name, m.black.name, m.game)
+	if errors.Is(err, storage.ErrUnrated) {
+		return ""
+	}
+	if err != nil {
+		return "Ratings were not updated: " + err.Error() + "\n"
+	}
+
+	var changes []string
+	for _, player := range []*rating.Player{white, black} {
+		changes = append(changes, fmt.Sprintf("%s %s -> %s", player.Name, before[player.Name], describeRating(player.Rating(category))))
+	}
+	return fmt.Sprintf("Ratings (%s): %s\n", category, strings.Join(changes, ", "))
+}
+
+func describeRating(rated *rating.Rating) string {
+	return rating.Format(rated.Glicko.Rating, rated.Provisional())
+}
+
+func (server *Server) showRatings(s *session, args []string) {
+	if len(args) > 1 {
+		s.send("Usage: ratings [player]\n")
+		return
+	}
+	if server.ratings == nil {
+		s.send("Games on this server are not rated.\n")
+		return
+	}
+
+	name := s.name
+	if len(args) == 1 {
+		name = args[0]
+	}
+	player, err := server.ratings.Player(name)
+	if err != nil {
+		s.send(err.Error() + "\n")
+		return
+	}
+
+	s.send(player.Summary())
+}
+
+func (server *Server) showLeaderboard(s *session, args []string) {
+	if len(args) > 1 {
+		s.send("Usage: top [bullet|blitz|rapid|classical]\n")
+		return
+	}
+	if server.ratings == nil {
+		s.send("Games on this server are not rated.\n")
+		return
+	}
+
+	category := rating.Blitz
+	if len(args) == 1 {
+		var err error
+		if category, err = rating.ParseCategory(args[0]); err != nil {
+			s.send(err.Error() + "\n")
+			return
+		}
+	}
+	players, err := server.ratings.Players()
+	if err != nil {
+		s.send(err.Error() + "\n")
+		return
+	}
+
+	s.send(fmt.Sprintf("Top %s players:\n%s", category, rating.Table(rating.Leaderboard(players, category))))
+}
+
+func (server *Server) listGames(s *session) {
+	if len(server.games) == 0 {
+		s.send("No games in progress.\n")
+		return
+	}
+
+	var ids []int
+	for id := range server.games {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		m := server.games[id]
+		s.send(fmt.Sprintf("%3d %-16s %-16s %d moves\n", id, m.white.name, m.black.name, len(m.game.Moves())))
+	}
+}
+
+func (server *Server) observe(s *session, args []string, start bool) {
+	if len(args) != 1 {
+		s.send("Usage: observe <game>\n")
+		return
+	}
+
+	id, _ := strconv.Atoi(args[0])
+	m, ok := server.games[id]
+	if !ok {
+		s.send("No such game.\n")
+		return
+	}
+
+	if !start {
+		delete(m.observers, s)
+		s.send(fmt.Sprintf("You stop observing game %d.\n", id))
+		return
+	}
+
+	m.observers[s] = true
+	s.send(fmt.Sprintf("You observe game %d: %s vs %s.\n", id, m.white.name, m.black.name))
+	s.send(server.render(m, s))
+}
+
+func (server *Server) showBoard(s *session) {
+	if s.match == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+
+	s.send(server.render(s.match, s))
+}
+
+func (server *Server) showMoves(s *session) {
+	if s.match == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+
+	var buffer strings.Builder
+	for i, move := range s.match.game.Moves() {
+		if i%2 == 0 {
+			buffer.WriteString(fmt.Sprintf("%3d. %-7s", i/2+1, move))
+		} else {
+			buffer.WriteString(move + "\n")
+		}
+	}
+
+	s.send(strings.TrimRight(buffer.String(), "\n") + "\n")
+}
+
+func (server *Server) tell(s *session, args []string) {
+	if len(args) < 2 {
+		s.send("Usage: tell <player> <text>\n")
+		return
+	}
+
+	to, ok := server.sessions[key(args[0])]
+	if !ok {
+		s.send("No such player.\n")
+		return
+	}
+
+	to.send(fmt.Sprintf("\n%s tells you: %s\n", s.name, strings.Join(args[1:], " ")))
+	s.send("(told " + to.name + ")\n")
+}
+
+func (server *Server) say(s *session, args []string) {
+	if s.match == nil {
+		s.send("You are not playing.\n")
+		return
+	}
+
+	s.match.opponent(s).send(fmt.Sprintf("\n%s says: %s\n", s.name, strings.Join(args, " ")))
+}
+
+func (server *Server) shout(s *session, args []string) {
+	server.broadcast(fmt.Sprintf("\n%s shouts: %s\n", s.name, strings.Join(args, " ")))
+}
+
+func (server *Server) broadcast(text string) {
+	for _, s := range server.sessions {
+		s.send(text)
+	}
+}
+
+func (server *Server) style(s *session, args []string) {
+	if len(args) != 1 {
+		s.send("Usage: style " + strings.Join(render.Names(), "|") + "\n")
+		return
+	}
+
+	renderer, err := render.New(args[0])
+	if err != nil {
+		s.send(err.Error() + "\n")
+		return
+	}
+
+	s.renderer = renderer
+	s.send("Boards are drawn as " + strings.ToLower(args[0]) + ".\n")
+}
+
+// render draws the board of the match as the viewer sees it.
+func (server *Server) render(m *match, viewer *session) string {
+	text := viewer.renderer.Render(m.game.Board(), render.Options{
+		Perspective: m.perspective(viewer),
+		Coordinates: true,
+		Highlight:   render.LastMove(m.game.Moves()),
+	})
+
+	if clock := m.game.Clock(); clock != nil {
+		text += fmt.Sprintf("Clock: %s %s, %s %s\n",
+			m.white.name, formatDuration(clock.Remaining(board.White)),
+			m.black.name, formatDuration(clock.Remaining(board.Black)))
+	}
+
+	return text + fmt.Sprintf("%s (%s) to move.\n", m.player(m.game.Turn()).name, m.game.Turn())
+}
+
+func describeResult(result game.Result) string {
+	switch result.Winner {
+	case board.White:
+		return "White wins by " + strings.ToLower(result.Termination) + " 1-0"
+	case board.Black:
+		return "Black wins by " + strings.ToLower(result.Termination) + " 0-1"
+	default:
+		return result.Termination + " 1/2-1/2"
+	}
+}
+
+// parseTimeControl reads "[minutes [increment]]", 5 minutes without increment
+// by default.
+func parseTimeControl(args []string) (game.TimeControl, bool) {
+	minutes, increment := 5, 0
+
+	if len(args) > 2 {
+		return game.TimeControl{}, false
+	}
+
+	var err error
+	if len(args) > 0 {
+		if minutes, err = strconv.Atoi(args[0]); err != nil || minutes < 0 {
+			return game.TimeControl{}, false
+		}
+	}
+	if len(args) > 1 {
+		if increment, err = strconv.Atoi(args[1]); err != nil || increment < 0 {
+			return game.TimeControl{}, false
+		}
+	}
+
+	return game.TimeControl{
+		Base:      time.Duration(minutes) * time.Minute,
+		Increment: time.Duration(increment) * time.Second,
+	}, true
+}
+
+func formatControl(control game.TimeControl) string {
+	if control.Base == 0 {
+		return "untimed"
+	}
+
+	return fmt.Sprintf("%d %d", int(control.Base.Minutes()), int(control.Increment.Seconds()))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
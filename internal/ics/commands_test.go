@@ -0,0 +1,104 @@
+package ics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+)
+
+// newMatch starts a game between two sessions without connections and
+// returns it, the position being set from the FEN.
+func newMatch(t *testing.T, server *Server, fen string, control game.TimeControl) *match {
+	t.Helper()
+
+	a := &session{name: "ann", out: make(chan string, outputQueue), renderer: render.ASCII{}}
+	b := &session{name: "bob", out: make(chan string, outputQueue), renderer: render.ASCII{}}
+	server.start(a, b, control)
+
+	m := a.match
+	chessGame, err := game.NewFromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if control.Base > 0 {
+		chessGame.SetClock(game.NewClock(control))
+	}
+	m.game = chessGame
+
+	return m
+}
+
+// output drains what was sent to the session.
+func output(s *session) string {
+	var text strings.Builder
+	for {
+		select {
+		case line := <-s.out:
+			text.WriteString(line)
+		default:
+			return text.String()
+		}
+	}
+}
+
+func TestMovePattern(t *testing.T) {
+	for line, want := range map[string]bool{
+		"e2 e4":   true,
+		"e7 e8 q": true,
+		"b2 b1 N": true,
+		"e7 e8 k": false,
+		"e7 e8q":  false,
+		"e2e4":    false,
+		"e2 e9":   false,
+	} {
+		if got := movePattern.MatchString(line); got != want {
+			t.Errorf("movePattern matches %q = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	server := NewServer()
+	m := newMatch(t, server, "8/1P6/8/8/8/8/k7/4K3 w - - 0 1", game.TimeControl{})
+
+	server.dispatch(m.white, "b7 b8 n")
+	if fen := m.game.FEN(); !strings.HasPrefix(fen, "1N6/8/") {
+		t.Errorf("after b7 b8 n the position is %s, want a knight on b8", fen)
+	}
+}
+
+func TestMoveAfterFlag(t *testing.T) {
+	server := NewServer()
+	m := newMatch(t, server, "", game.TimeControl{Base: 50 * time.Millisecond})
+	white, black := m.white, m.black
+
+	server.dispatch(white, "e2 e4")
+	time.Sleep(100 * time.Millisecond)
+	output(white)
+	server.dispatch(black, "e7 e5")
+
+	if text := output(white); !strings.Contains(text, "White wins by time forfeit") {
+		t.Errorf("white was told %q, want the time forfeit", text)
+	}
+	if len(server.games) != 0 || white.match != nil || black.match != nil {
+		t.Error("the game was not finished")
+	}
+}
+
+func TestCheckClocksFinishesEndedGames(t *testing.T) {
+	server := NewServer()
+	m := newMatch(t, server, "", game.TimeControl{Base: 50 * time.Millisecond})
+
+	server.dispatch(m.white, "e2 e4")
+	time.Sleep(100 * time.Millisecond)
+	// The flag was seen by somebody else, the game is over but not finished.
+	m.game.CheckTime()
+
+	server.checkClocks()
+	if len(server.games) != 0 {
+		t.Error("checkClocks left a game that is over")
+	}
+}
@@ -0,0 +1,193 @@
+// Package ics is a small chess server in the spirit of the classic Internet
+// Chess Servers: players connect with nc or telnet, meet in a lobby through
+// seeks and challenges and play or observe games as plain text.
+//
+// Every connection is served by its own goroutine, while all shared state is
+// owned by the manager goroutine. Sessions hand their commands over to it as
+// closures, so the state needs no locks.
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+const (
+	outputQueue   = 256
+	clockInterval = time.Second
+	maxNameLength = 16
+)
+
+type Server struct {
+	requests chan func()
+	sessions map[string]*session
+	seeks    []*seek
+	games    map[int]*match
+	nextSeek int
+	nextGame int
+	// ratings, when set, rates the finished games.
+	ratings *storage.RatingStore
+}
+
+func NewServer() *Server {
+	return &Server{
+		requests: make(chan func()),
+		sessions: make(map[string]*session),
+		games:    make(map[int]*match),
+	}
+}
+
+// SetRatings makes the games of the server rated, the ratings being kept in
+// the store. It must be called before serving.
+func (server *Server) SetRatings(ratings *storage.RatingStore) {
+	server.ratings = ratings
+}
+
+// ListenAndServe accepts connections on the TCP address until the listener
+// fails.
+func (server *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(listener)
+}
+
+func (server *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+	go server.manage()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.handle(conn)
+	}
+}
+
+// manage is the manager goroutine: it runs the requests of the sessions one
+// at a time and checks the clocks of running games.
+func (server *Server) manage() {
+	ticker := time.NewTicker(clockInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case request := <-server.requests:
+			request()
+		case <-ticker.C:
+			server.checkClocks()
+		}
+	}
+}
+
+// do runs the request on the manager goroutine and waits for it.
+func (server *Server) do(request func()) {
+	done := make(chan struct{})
+	server.requests <- func() {
+		request()
+		close(done)
+	}
+	<-done
+}
+
+func (server *Server) handle(conn net.Conn) {
+	s := &session{conn: conn, out: make(chan string, outputQueue), renderer: render.Unicode{}}
+	go s.writeLoop()
+	defer close(s.out)
+
+	scanner := bufio.NewScanner(conn)
+	if !server.login(s, scanner) {
+		return
+	}
+	defer server.do(func() { server.logout(s) })
+
+	s.prompt()
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			s.send("Goodbye.\n")
+			return
+		}
+		if line != "" {
+			server.do(func() { server.dispatch(s, line) })
+		}
+		s.prompt()
+	}
+}
+
+func (server *Server) login(s *session, scanner *bufio.Scanner) bool {
+	s.send("Welcome to chess_on_golang. Type \"help\" once logged in.\n")
+
+	for {
+		s.send("login: ")
+		if !scanner.Scan() {
+			return false
+		}
+
+		name := strings.TrimSpace(scanner.Text())
+		if !validName(name) {
+			s.send(fmt.Sprintf("Names are 1 to %d letters or digits.\n", maxNameLength))
+			continue
+		}
+
+		var ok bool
+		server.do(func() {
+			if _, taken := server.sessions[key(name)]; !taken {
+				s.name = name
+				server.sessions[key(name)] = s
+				ok = true
+			}
+		})
+
+		if ok {
+			s.send(fmt.Sprintf("Hello %s.\n", name))
+			return true
+		}
+		s.send(name + " is already logged in.\n")
+	}
+}
+
+func (server *Server) logout(s *session) {
+	if s.match != nil {
+		server.abandon(s)
+	}
+	for _, g := range server.games {
+		delete(g.observers, s)
+	}
+	server.removeSeeks(s)
+	for _, other := range server.sessions {
+		delete(other.challenges, key(s.name))
+	}
+	delete(server.sessions, key(s.name))
+}
+
+// key is the name a player is found by in the sessions and challenges.
+// Names are compared without case, as the ratings compare them, so that
+// "Alice" and "alice" are one player.
+func key(name string) string {
+	return strings.ToLower(name)
+}
+
+func validName(name string) bool {
+	if name == "" || len(name) > maxNameLength {
+		return false
+	}
+
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,61 @@
+package ics
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// connect logs in to the server under the name and returns the connection
+// and the answer to the login.
+func connect(t *testing.T, addr, name string) (net.Conn, string) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	reader := bufio.NewReader(conn)
+	readUntil(t, reader, "login: ")
+	conn.Write([]byte(name + "\n"))
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn, answer
+}
+
+func readUntil(t *testing.T, reader *bufio.Reader, text string) {
+	t.Helper()
+
+	var read strings.Builder
+	for !strings.HasSuffix(read.String(), text) {
+		b, err := reader.ReadByte()
+		if err != nil {
+			t.Fatalf("waiting for %q after %q: %v", text, read.String(), err)
+		}
+		read.WriteByte(b)
+	}
+}
+
+func TestLoginNamesIgnoreCase(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go NewServer().Serve(listener)
+
+	if _, answer := connect(t, listener.Addr().String(), "Alice"); answer != "Hello Alice.\n" {
+		t.Fatalf("login as Alice: %q", answer)
+	}
+	if _, answer := connect(t, listener.Addr().String(), "alice"); answer != "alice is already logged in.\n" {
+		t.Errorf("login as alice while Alice is logged in: %q", answer)
+	}
+}
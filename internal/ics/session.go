@@ -0,0 +1,94 @@
+package ics
+
+import (
+	"net"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/render"
+)
+
+type session struct {
+	conn net.Conn
+	name string
+	out  chan string
+
+	// The fields below belong to the manager goroutine.
+	match      *match
+	team       board.Team
+	challenges map[string]game.TimeControl
+	renderer   render.Renderer
+}
+
+// send queues text for the connection. A client that stops reading loses
+// output rather than stalling the manager.
+func (s *session) send(text string) {
+	select {
+	case s.out <- text:
+	default:
+	}
+}
+
+func (s *session) prompt() {
+	s.send("ics% ")
+}
+
+func (s *session) writeLoop() {
+	for text := range s.out {
+		if _, err := s.conn.Write([]byte(text)); err != nil {
+			break
+		}
+	}
+
+	s.conn.Close()
+}
+
+type seek struct {
+	id      int
+	owner   *session
+	control game.TimeControl
+}
+
+type match struct {
+	id        int
+	white     *session
+	black     *session
+	game      *game.ChessGame
+	observers map[*session]bool
+}
+
+func (m *match) player(team board.Team) *session {
+	if team == board.White {
+		return m.white
+	}
+
+	return m.black
+}
+
+func (m *match) opponent(s *session) *session {
+	if s == m.white {
+		return m.black
+	}
+
+	return m.white
+}
+
+// perspective returns the side a session sees at the bottom: its own
+// colour for players and White for observers.
+func (m *match) perspective(s *session) board.Team {
+	if s == m.black {
+		return board.Black
+	}
+
+	return board.White
+}
+
+// audience returns both players followed by the observers.
+func (m *match) audience() []*session {
+	sessions := []*session{m.white, m.black}
+	for observer := range m.observers {
+		sessions = append(sessions, observer)
+	}
+
+	return sessions
+}
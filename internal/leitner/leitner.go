@@ -0,0 +1,37 @@
+// Package leitner schedules reviews with the Leitner system: cards move up
+// a box each time they are passed, back to the first when failed, and the
+// higher the box, the longer the wait before the next review.
+package leitner
+
+import "time"
+
+// Schedule holds the wait before the next review of a card in each box.
+type Schedule []time.Duration
+
+// Card is the place of an item in the schedule.
+type Card struct {
+	Box int       `json:"box"`
+	Due time.Time `json:"due"`
+}
+
+// Pass moves the card to the next box, due after the wait of that box. A
+// card in the last box stays there.
+func (schedule Schedule) Pass(card Card, now time.Time) Card {
+	box := min(card.Box+1, len(schedule)-1)
+	return Card{Box: box, Due: now.Add(schedule[box])}
+}
+
+// Fail sends a card back to the first box.
+func (schedule Schedule) Fail(now time.Time) Card {
+	return Card{Box: 0, Due: now.Add(schedule[0])}
+}
+
+// Last reports whether the card is in the last box.
+func (schedule Schedule) Last(card Card) bool {
+	return card.Box >= len(schedule)-1
+}
+
+// IsDue reports whether the card is due for review.
+func (card Card) IsDue(now time.Time) bool {
+	return !card.Due.After(now)
+}
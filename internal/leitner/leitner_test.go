@@ -0,0 +1,30 @@
+package leitner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	schedule := Schedule{time.Minute, time.Hour, 24 * time.Hour}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	card := schedule.Fail(now)
+	if card.Box != 0 || !card.Due.Equal(now.Add(time.Minute)) || card.IsDue(now) {
+		t.Fatalf("failed card = %+v, want the first box due in a minute", card)
+	}
+
+	for _, want := range []Card{
+		{Box: 1, Due: now.Add(time.Hour)},
+		{Box: 2, Due: now.Add(24 * time.Hour)},
+		{Box: 2, Due: now.Add(24 * time.Hour)},
+	} {
+		card = schedule.Pass(card, now)
+		if card != want {
+			t.Fatalf("passed card = %+v, want %+v", card, want)
+		}
+	}
+	if !schedule.Last(card) || !card.IsDue(card.Due) {
+		t.Errorf("card %+v should be in the last box and due at %v", card, card.Due)
+	}
+}
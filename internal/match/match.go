@@ -0,0 +1,343 @@
+// Package match plays engine matches: games between two engines, the
+// internal one or external UCI engines, played side by side by a pool of
+// workers. Each opening is played twice with the colours swapped, and the
+// results give the Elo difference of the engines and, optionally, an SPRT
+// that stops the match once it is decided.
+package match
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+)
+
+// Config describes a match.
+type Config struct {
+	Engines [2]Spec
+	Games   int
+	// Concurrency is the number of games played at the same time.
+	Concurrency int
+	// Depth and MoveTime bound the searches of the engines whose spec sets
+	// neither.
+	Depth    int
+	MoveTime time.Duration
+	// Openings are played in turn, from the initial position when there
+	// are none.
+	Openings     []Opening
+	Adjudication Adjudication
+	// Tablebases, if not nil, adjudicate the endgames they hold and help
+	// the internal engine.
+	Tablebases *tablebase.Set
+	// SPRT, if not nil, stops the match once it passes or fails.
+	SPRT  *SPRT
+	Event string
+}
+
+// Adjudication ends games the engines agree on before they are over. Scores
+// are in centipawns and a zero count disables a rule.
+type Adjudication struct {
+	// A side wins when both engines gave it at least ResignScore for
+	// ResignMoves moves each in a row.
+	ResignScore int
+	ResignMoves int
+	// The game is drawn when, from move DrawAfter on, both engines scored
+	// it within DrawScore for DrawMoves moves each in a row.
+	DrawScore int
+	DrawMoves int
+	DrawAfter int
+}
+
+// Game is a game of the match once it is over.
+type Game struct {
+	Round int
+	// White is the engine playing white, 0 for the first.
+	White  int
+	Record pgn.Game
+	Result game.Result
+}
+
+// Run plays the games and calls onGame as each ends, with the score of the
+// first engine so far. An error of onGame or an engine that cannot be
+// started stops the match, the games being played finishing first.
+func Run(config Config, onGame func(Game, Score) error) (Score, error) {
+	if len(config.Openings) == 0 {
+		config.Openings = []Opening{{FEN: board.StartFEN}}
+	}
+	names := config.names()
+
+	type outcome struct {
+		game Game
+		err  error
+	}
+	var stop atomic.Bool
+	rounds := make(chan int)
+	outcomes := make(chan outcome)
+
+	var workers sync.WaitGroup
+	for range max(config.Concurrency, 1) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker := worker{config: &config, names: names}
+			defer worker.close()
+			for round := range rounds {
+				played, err := worker.play(round)
+				outcomes <- outcome{played, err}
+			}
+		}()
+	}
+	go func() {
+		for round := 1; round <= config.Games && !stop.Load(); round++ {
+			rounds <- round
+		}
+		close(rounds)
+	}()
+	go func() {
+		workers.Wait()
+		close(outcomes)
+	}()
+
+	var score Score
+	var firstErr error
+	for outcome := range outcomes {
+		err := outcome.err
+		if err == nil {
+			score.add(outcome.game)
+			if onGame != nil {
+				err = onGame(outcome.game, score)
+			}
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err != nil || config.SPRT != nil && config.SPRT.Decide(score) != Continue {
+			stop.Store(true)
+		}
+	}
+
+	return score, firstErr
+}
+
+// names labels the engines, numbering them when they would be alike.
+func (config Config) names() [2]string {
+	names := [2]string{config.Engines[0].label(), config.Engines[1].label()}
+	if names[0] == names[1] {
+		names[0] += " 1"
+		names[1] += " 2"
+	}
+
+	return names
+}
+
+func (score *Score) add(played Game) {
+	switch played.Result.Winner {
+	case board.Undecided:
+		score.Draws++
+	case board.White:
+		if played.White == 0 {
+			score.Wins++
+		} else {
+			score.Losses++
+		}
+	default:
+		if played.White == 0 {
+			score.Losses++
+		} else {
+			score.Wins++
+		}
+	}
+}
+
+// worker plays games one after the other with its own engines, which are
+// started with its first game and again after failing.
+type worker struct {
+	config  *Config
+	names   [2]string
+	players [2]player
+}
+
+// play plays a round, the odd ones with the first engine as white.
+func (worker *worker) play(round int) (Game, error) {
+	config := worker.config
+	pair := (round - 1) / 2
+	opening := config.Openings[pair%len(config.Openings)]
+
+	chessGame, err := game.NewFromFEN(opening.FEN)
+	if err != nil {
+		return Game{}, fmt.Errorf("opening %d: %w", pair%len(config.Openings)+1, err)
+	}
+	for _, command := range opening.Moves {
+		if err := chessGame.Play(command); err != nil {
+			return Game{}, fmt.Errorf("opening %d: %w", pair%len(config.Openings)+1, err)
+		}
+	}
+
+	white := (round - 1) % 2
+	engines := map[board.Team]int{board.White: white, board.Black: 1 - white}
+	for index := range worker.players {
+		if worker.players[index] == nil {
+			if worker.players[index], err = start(config.Engines[index], config); err != nil {
+				return Game{}, fmt.Errorf("%s: %w", worker.names[index], err)
+			}
+		}
+		if err := worker.players[index].newGame(chessGame.Board().Chess960()); err != nil {
+			return Game{}, fmt.Errorf("%s: %w", worker.names[index], err)
+		}
+	}
+
+	var scores []*int
+	for {
+		if _, over := chessGame.Result(); over {
+			break
+		}
+		if result, ok := worker.adjudicate(chessGame, scores); ok {
+			chessGame.SetResult(result)
+			break
+		}
+
+		team := chessGame.Turn()
+		index := engines[team]
+		answer, err := worker.players[index].move(chessGame)
+		if err == nil {
+			err = chessGame.Play(answer.command)
+		}
+		if err != nil {
+			// The engine is started again for the next game.
+			worker.players[index].close()
+			worker.players[index] = nil
+			chessGame.SetResult(game.Result{Winner: team.Opponent(), Termination: game.TerminationRulesInfraction})
+			break
+		}
+
+		var whiteScore *int
+		if answer.hasScore {
+			score := answer.score
+			if team == board.Black {
+				score = -score
+			}
+			whiteScore = &score
+		}
+		scores = append(scores, whiteScore)
+	}
+
+	result, _ := chessGame.Result()
+	record := chessGame.PGN()
+	event := config.Event
+	if event == "" {
+		event = "Engine match"
+	}
+	record.SetTag("Event", event)
+	record.SetTag("Round", strconv.Itoa(round))
+	record.SetTag("White", worker.names[white])
+	record.SetTag("Black", worker.names[1-white])
+
+	return Game{Round: round, White: white, Record: record, Result: result}, nil
+}
+
+// adjudicate ends the game by a draw claim, insufficient material, the
+// tablebases or the scores of the engines, white's view of the moves
+// played by the engines so far, nil where an engine gave none.
+func (worker *worker) adjudicate(chessGame *game.ChessGame, scores []*int) (game.Result, bool) {
+	if termination, ok := chessGame.DrawClaim(); ok {
+		return game.Result{Winner: board.Undecided, Termination: termination}, true
+	}
+
+	b, team := chessGame.Board(), chessGame.Turn()
+	if insufficient(b) {
+		return game.Result{Winner: board.Undecided, Termination: game.TerminationInsufficient}, true
+	}
+	if worker.config.Tablebases != nil {
+		if probe, err := worker.config.Tablebases.Probe(b, team); err == nil {
+			winner := board.Undecided
+			switch probe.WDL {
+			case tablebase.Win:
+				winner = team
+			case tablebase.Loss:
+				winner = team.Opponent()
+			}
+			return game.Result{Winner: winner, Termination: game.TerminationAdjudication}, true
+		}
+	}
+
+	rules := worker.config.Adjudication
+	if rules.ResignMoves > 0 {
+		if last, ok := lastScores(scores, 2*rules.ResignMoves); ok {
+			switch {
+			case all(last, func(score int) bool { return score >= rules.ResignScore }):
+				return game.Result{Winner: board.White, Termination: game.TerminationAdjudication}, true
+			case all(last, func(score int) bool { return score <= -rules.ResignScore }):
+				return game.Result{Winner: board.Black, Termination: game.TerminationAdjudication}, true
+			}
+		}
+	}
+	if rules.DrawMoves > 0 && len(chessGame.Moves())/2 >= rules.DrawAfter {
+		last, ok := lastScores(scores, 2*rules.DrawMoves)
+		if ok && all(last, func(score int) bool { return -rules.DrawScore <= score && score <= rules.DrawScore }) {
+			return game.Result{Winner: board.Undecided, Termination: game.TerminationAdjudication}, true
+		}
+	}
+
+	return game.Result{}, false
+}
+
+// lastScores returns the last count scores when there are that many and
+// all were given.
+func lastScores(scores []*int, count int) ([]int, bool) {
+	if len(scores) < count {
+		return nil, false
+	}
+
+	var last []int
+	for _, score := range scores[len(scores)-count:] {
+		if score == nil {
+			return nil, false
+		}
+		last = append(last, *score)
+	}
+
+	return last, true
+}
+
+func all(scores []int, test func(int) bool) bool {
+	for _, score := range scores {
+		if !test(score) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// insufficient reports whether neither side can mate: only the kings are
+// left, with at most a bishop or a knight.
+func insufficient(b *board.Board) bool {
+	minors := 0
+	for _, row := range b.Grid() {
+		for _, sign := range row {
+			switch sign {
+			case "", "k", "K":
+			case "b", "B", "n", "N":
+				minors++
+			default:
+				return false
+			}
+		}
+	}
+
+	return minors <= 1
+}
+
+func (worker *worker) close() {
+	for _, player := range worker.players {
+		if player != nil {
+			player.close()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package match
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// Opening is where a pair of games starts: a position and the moves played
+// from it before the engines take over. Chess960 positions are told by
+// their castling rights.
+type Opening struct {
+	FEN   string
+	Moves []string
+}
+
+// LoadOpenings reads the openings of an EPD file, one position a line, or
+// of a PGN file, the main line of each game.
+func LoadOpenings(path string) ([]Opening, error) {
+	var openings []Opening
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".epd") {
+		openings, err = readEPD(path)
+	} else {
+		openings, err = readPGN(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(openings) == 0 {
+		return nil, fmt.Errorf("%s: no openings", path)
+	}
+
+	return openings, nil
+}
+
+// readEPD takes the four position fields of each line, the operations
+// that follow being ignored.
+func readEPD(path string) ([]Opening, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var openings []Opening
+	scanner := bufio.NewScanner(file)
+	for number := 1; scanner.Scan(); number++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: not an EPD position", number)
+		}
+
+		fen := strings.Join(fields[:4], " ") + " 0 1"
+		if _, _, err := board.ParseFEN(fen); err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		openings = append(openings, Opening{FEN: fen})
+	}
+
+	return openings, scanner.Err()
+}
+
+func readPGN(path string) ([]Opening, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	games, err := pgn.Parse(string(text))
+	if err != nil {
+		return nil, err
+	}
+
+	var openings []Opening
+	for i, record := range games {
+		b, team, err := record.Start()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		if b.Variant().Name() != board.Standard.Name() {
+			return nil, fmt.Errorf("game %d: only standard chess and Chess960 are played", i+1)
+		}
+
+		// Shredder-FEN keeps a Chess960 opening Chess960.
+		opening := Opening{FEN: b.FEN(team)}
+		if b.Chess960() {
+			opening.FEN = b.ShredderFEN(team)
+		}
+		for _, move := range record.Moves {
+			command, err := b.ParseSAN(move.SAN, team)
+			if err != nil {
+				return nil, fmt.Errorf("game %d: %s: %w", i+1, move.SAN, err)
+			}
+			b.Execute(command, team)
+			opening.Moves = append(opening.Moves, command)
+			team = team.Opponent()
+		}
+		openings = append(openings, opening)
+	}
+
+	return openings, nil
+}
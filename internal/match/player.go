@@ -0,0 +1,182 @@
+package match
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/uci"
+)
+
+// Spec configures an engine of the match.
+type Spec struct {
+	Name string
+	// Command runs an external UCI engine, its path and arguments. The
+	// internal engine plays when it is empty.
+	Command  []string
+	Depth    int
+	MoveTime time.Duration
+	// Options are set on an external engine before the first game.
+	Options map[string]string
+}
+
+// ParseSpec reads a spec written as comma-separated settings: "internal" or
+// "cmd=PATH ARGS" first, then name=, depth=, movetime= and option.NAME=,
+// e.g. "cmd=/usr/bin/stockfish,movetime=100ms,option.Hash=16".
+func ParseSpec(text string) (Spec, error) {
+	var spec Spec
+	fields := strings.Split(text, ",")
+	if fields[0] != "internal" {
+		command, ok := strings.CutPrefix(fields[0], "cmd=")
+		if !ok || strings.TrimSpace(command) == "" {
+			return Spec{}, fmt.Errorf("engine %q: start with internal or cmd=PATH", text)
+		}
+		spec.Command = strings.Fields(command)
+	}
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Spec{}, fmt.Errorf("engine %q: %q is not key=value", text, field)
+		}
+
+		var err error
+		switch {
+		case key == "name":
+			spec.Name = value
+		case key == "depth":
+			spec.Depth, err = strconv.Atoi(value)
+		case key == "movetime":
+			spec.MoveTime, err = time.ParseDuration(value)
+		case strings.HasPrefix(key, "option."):
+			if spec.Options == nil {
+				spec.Options = make(map[string]string)
+			}
+			spec.Options[strings.TrimPrefix(key, "option.")] = value
+		default:
+			err = fmt.Errorf("unknown setting %s", key)
+		}
+		if err != nil {
+			return Spec{}, fmt.Errorf("engine %q: %w", text, err)
+		}
+	}
+
+	return spec, nil
+}
+
+// label is the name of the engine, by default "internal" or the base name
+// of the command.
+func (spec Spec) label() string {
+	switch {
+	case spec.Name != "":
+		return spec.Name
+	case len(spec.Command) == 0:
+		return "internal"
+	default:
+		return filepath.Base(spec.Command[0])
+	}
+}
+
+// move is the answer of a player. Score is for the side to move.
+type move struct {
+	command  string
+	score    int
+	hasScore bool
+}
+
+// player plays the moves of an engine in the games of a worker.
+type player interface {
+	newGame(chess960 bool) error
+	move(chessGame *game.ChessGame) (move, error)
+	close() error
+}
+
+// start runs the engine of the spec, which searches to the depth and for
+// the move time of the spec, or of the config when the spec has neither.
+func start(spec Spec, config *Config) (player, error) {
+	depth, moveTime := spec.Depth, spec.MoveTime
+	if depth == 0 && moveTime == 0 {
+		depth, moveTime = config.Depth, config.MoveTime
+	}
+
+	if len(spec.Command) == 0 {
+		limits := engine.Limits{Depth: depth, Time: moveTime, Tablebases: config.Tablebases}
+		if limits.Depth == 0 {
+			limits.Depth = maxDepth
+		}
+		return internalPlayer{limits: limits}, nil
+	}
+
+	external, err := uci.Start(spec.Command)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range spec.Options {
+		if err := external.SetOption(name, value); err != nil {
+			external.Close()
+			return nil, err
+		}
+	}
+
+	return &uciPlayer{engine: external, limits: uci.Limits{Depth: depth, MoveTime: moveTime}}, nil
+}
+
+// maxDepth is the depth of the internal engine when only the time bounds
+// its search.
+const maxDepth = 64
+
+type internalPlayer struct {
+	limits engine.Limits
+}
+
+func (internalPlayer) newGame(bool) error {
+	return nil
+}
+
+func (player internalPlayer) move(chessGame *game.ChessGame) (move, error) {
+	result, err := engine.Search(chessGame.Board(), chessGame.Turn(), player.limits)
+	if err != nil {
+		return move{}, err
+	}
+
+	return move{command: result.Move, score: result.Score, hasScore: true}, nil
+}
+
+func (internalPlayer) close() error {
+	return nil
+}
+
+type uciPlayer struct {
+	engine   *uci.Engine
+	limits   uci.Limits
+	chess960 bool
+}
+
+// newGame switches the engine to Chess960 and back as the games require.
+func (player *uciPlayer) newGame(chess960 bool) error {
+	if chess960 != player.chess960 {
+		if err := player.engine.SetOption(uci.Chess960Option, strconv.FormatBool(chess960)); err != nil {
+			return err
+		}
+		player.chess960 = chess960
+	}
+
+	return player.engine.NewGame()
+}
+
+func (player *uciPlayer) move(chessGame *game.ChessGame) (move, error) {
+	result, err := player.engine.Go(chessGame.StartFEN(), chessGame.Moves(), player.limits)
+	if err != nil {
+		return move{}, err
+	}
+
+	return move{command: result.Move, score: result.Score, hasScore: result.HasScore}, nil
+}
+
+func (player *uciPlayer) close() error {
+	return player.engine.Close()
+}
@@ -0,0 +1,144 @@
+package match
+
+import (
+	"fmt"
+	"math"
+)
+
+// Score counts the results of the first engine of the match.
+type Score struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+func (score Score) Games() int {
+	return score.Wins + score.Draws + score.Losses
+}
+
+// Ratio is the share of the points the first engine scored.
+func (score Score) Ratio() float64 {
+	if score.Games() == 0 {
+		return 0.5
+	}
+
+	return (float64(score.Wins) + float64(score.Draws)/2) / float64(score.Games())
+}
+
+// variance is the variance of the points of a game around the ratio.
+func (score Score) variance() float64 {
+	if score.Games() == 0 {
+		return 0
+	}
+
+	ratio := score.Ratio()
+	sum := float64(score.Wins)*math.Pow(1-ratio, 2) +
+		float64(score.Draws)*math.Pow(0.5-ratio, 2) +
+		float64(score.Losses)*math.Pow(ratio, 2)
+	return sum / float64(score.Games())
+}
+
+// Elo returns the Elo difference of the first engine over the second and
+// the margin of its 95% confidence interval.
+func (score Score) Elo() (float64, float64) {
+	ratio := score.Ratio()
+	if score.Games() == 0 {
+		return 0, 0
+	}
+
+	deviation := 1.96 * math.Sqrt(score.variance()/float64(score.Games()))
+	return elo(ratio), (elo(ratio+deviation) - elo(ratio-deviation)) / 2
+}
+
+// LOS is the likelihood of superiority, the chance that the first engine is
+// the stronger one.
+func (score Score) LOS() float64 {
+	decisive := float64(score.Wins + score.Losses)
+	if decisive == 0 {
+		return 0.5
+	}
+
+	return 0.5 * (1 + math.Erf(float64(score.Wins-score.Losses)/math.Sqrt(2*decisive)))
+}
+
+func (score Score) String() string {
+	difference, margin := score.Elo()
+	return fmt.Sprintf("+%d =%d -%d (%.1f%%), Elo %+.1f ± %.1f, LOS %.1f%%",
+		score.Wins, score.Draws, score.Losses, 100*score.Ratio(), difference, margin, 100*score.LOS())
+}
+
+// elo turns a score ratio into an Elo difference with the logistic model,
+// a ratio of 0 or 1 being held at a 1 in 1000 edge.
+func elo(ratio float64) float64 {
+	ratio = math.Min(math.Max(ratio, 0.001), 0.999)
+	return 400 * math.Log10(ratio/(1-ratio))
+}
+
+// ratio is the expected score of the stronger side for an Elo difference.
+func ratio(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// SPRT is a sequential probability ratio test of the hypotheses that the
+// first engine is Elo0 stronger than the second, H0, against Elo1, H1,
+// with the error rates Alpha and Beta.
+type SPRT struct {
+	Elo0  float64
+	Elo1  float64
+	Alpha float64
+	Beta  float64
+}
+
+// Decision is the state of an SPRT.
+type Decision int
+
+const (
+	Continue Decision = iota
+	// Passed accepts H1.
+	Passed
+	// Failed accepts H0.
+	Failed
+)
+
+func (decision Decision) String() string {
+	switch decision {
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	default:
+		return "running"
+	}
+}
+
+// Bounds returns the log-likelihood ratios at which the test fails and
+// passes.
+func (sprt SPRT) Bounds() (float64, float64) {
+	return math.Log(sprt.Beta / (1 - sprt.Alpha)), math.Log((1 - sprt.Beta) / sprt.Alpha)
+}
+
+// LLR is the log-likelihood ratio of the hypotheses for the score, with the
+// points of a game taken as normally distributed.
+func (sprt SPRT) LLR(score Score) float64 {
+	variance := score.variance()
+	if variance == 0 {
+		return 0
+	}
+
+	ratio0, ratio1 := ratio(sprt.Elo0), ratio(sprt.Elo1)
+	games := float64(score.Games())
+	return games * (ratio1 - ratio0) * (2*score.Ratio() - ratio0 - ratio1) / (2 * variance)
+}
+
+// Decide compares the log-likelihood ratio of the score with the bounds.
+func (sprt SPRT) Decide(score Score) Decision {
+	lower, upper := sprt.Bounds()
+	switch llr := sprt.LLR(score); {
+	case llr >= upper:
+		return Passed
+	case llr <= lower:
+		return Failed
+	default:
+		return Continue
+	}
+}
@@ -0,0 +1,55 @@
+package match
+
+import (
+	"math"
+	"testing"
+)
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 0.001
+}
+
+func TestElo(t *testing.T) {
+	score := Score{Wins: 60, Losses: 40}
+	difference, margin := score.Elo()
+	if !near(difference, 70.4365) || !near(margin, 70.5725) {
+		t.Errorf("Elo = %.4f ± %.4f, want 70.4365 ± 70.5725", difference, margin)
+	}
+	if los := score.LOS(); !near(los, 0.97725) {
+		t.Errorf("LOS = %.5f, want 0.97725", los)
+	}
+
+	if difference, margin := (Score{Wins: 10, Draws: 20, Losses: 10}).Elo(); difference != 0 || margin <= 0 {
+		t.Errorf("even score: Elo = %.1f ± %.1f, want 0 with a margin", difference, margin)
+	}
+	// A perfect score is held at the 1 in 1000 edge.
+	if difference, _ := (Score{Wins: 10}).Elo(); !near(difference, 1199.8262) {
+		t.Errorf("perfect score: Elo = %.4f, want 1199.8262", difference)
+	}
+}
+
+func TestSPRT(t *testing.T) {
+	sprt := SPRT{Elo0: 0, Elo1: 5, Alpha: 0.05, Beta: 0.05}
+
+	lower, upper := sprt.Bounds()
+	if !near(lower, -math.Log(19)) || !near(upper, math.Log(19)) {
+		t.Errorf("Bounds = %.4f, %.4f, want ±ln 19", lower, upper)
+	}
+	if llr := sprt.LLR(Score{Wins: 60, Losses: 40}); !near(llr, 0.28901) {
+		t.Errorf("LLR = %.5f, want 0.28901", llr)
+	}
+
+	for _, test := range []struct {
+		score Score
+		want  Decision
+	}{
+		{Score{Wins: 60, Losses: 40}, Continue},
+		{Score{Wins: 600, Draws: 200, Losses: 400}, Passed},
+		{Score{Wins: 400, Draws: 200, Losses: 600}, Failed},
+		{Score{Draws: 10}, Continue},
+	} {
+		if got := sprt.Decide(test.score); got != test.want {
+			t.Errorf("Decide(%+v) = %v, want %v", test.score, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package pgn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var results = map[string]bool{"1-0": true, "0-1": true, "1/2-1/2": true, "*": true}
+
+// Parse reads every game of a PGN text.
+func Parse(text string) ([]Game, error) {
+	p := &parser{text: text}
+	var games []Game
+
+	for {
+		p.skipSpace()
+		if p.done() {
+			return games, nil
+		}
+
+		game, err := p.game()
+		if err != nil {
+			return games, fmt.Errorf("game %d: %w", len(games)+1, err)
+		}
+		games = append(games, game)
+	}
+}
+
+// ParseOne reads the first game of a PGN text.
+func ParseOne(text string) (Game, error) {
+	games, err := Parse(text)
+	if err != nil {
+		return Game{}, err
+	}
+	if len(games) == 0 {
+		return Game{}, fmt.Errorf("no game found")
+	}
+
+	return games[0], nil
+}
+
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *parser) peek() byte {
+	return p.text[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for !p.done() {
+		switch c := p.peek(); {
+		case c == ';':
+			p.until('\n')
+		case c == '%' && (p.pos == 0 || p.text[p.pos-1] == '\n'):
+			p.until('\n')
+		case unicode.IsSpace(rune(c)):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// until returns the text up to the byte and moves past it.
+func (p *parser) until(end byte) (string, bool) {
+	i := strings.IndexByte(p.text[p.pos:], end)
+	if i < 0 {
+		s := p.text[p.pos:]
+		p.pos = len(p.text)
+		return s, false
+	}
+
+	s := p.text[p.pos : p.pos+i]
+	p.pos += i + 1
+	return s, true
+}
+
+func (p *parser) game() (Game, error) {
+	var game Game
+
+	for p.skipSpace(); !p.done() && p.peek() == '['; p.skipSpace() {
+		tag, err := p.tag()
+		if err != nil {
+			return game, err
+		}
+		game.Tags = append(game.Tags, tag)
+	}
+
+	moves, result, comment, err := p.moves(0)
+	if err != nil {
+		return game, err
+	}
+
+	game.Moves, game.Result, game.Comment = moves, result, comment
+	if game.Result == "" {
+		game.Result = game.Tag("Result")
+	}
+
+	return game, nil
+}
+
+func (p *parser) tag() (Tag, error) {
+	p.pos++
+	line, ok := p.until(']')
+	if !ok {
+		return Tag{}, fmt.Errorf("unterminated tag")
+	}
+
+	name, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return Tag{}, fmt.Errorf("malformed tag %q", line)
+	}
+
+	value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	return Tag{Name: name, Value: strings.ReplaceAll(value, `\\`, `\`)}, nil
+}
+
+// moves reads a movetext until the result or, for a variation, the closing
+// parenthesis.
+func (p *parser) moves(depth int) ([]Move, string, string, error) {
+	var moves []Move
+	var comment string
+
+	for p.skipSpace(); !p.done(); p.skipSpace() {
+		switch c := p.peek(); {
+		case c == '{':
+			p.pos++
+			text, ok := p.until('}')
+			if !ok {
+				return nil, "", "", fmt.Errorf("unterminated comment")
+			}
+			text = strings.Join(strings.Fields(text), " ")
+			if len(moves) == 0 {
+				comment = joinComment(comment, text)
+			} else {
+				moves[len(moves)-1].Comment = joinComment(moves[len(moves)-1].Comment, text)
+			}
+
+		case c == '(':
+			p.pos++
+			if len(moves) == 0 {
+				return nil, "", "", fmt.Errorf("variation before any move")
+			}
+			variation, _, comment, err := p.moves(depth + 1)
+			if err != nil {
+				return nil, "", "", err
+			}
+			last := &moves[len(moves)-1]
+			// A variation holding only a comment has no move to keep it
+			// before, it is kept after the move instead.
+			if len(variation) > 0 {
+				variation[0].Before = comment
+			} else {
+				last.Comment = joinComment(last.Comment, comment)
+			}
+			last.Variations = append(last.Variations, variation)
+
+		case c == ')':
+			p.pos++
+			if depth == 0 {
+				return nil, "", "", fmt.Errorf("unexpected )")
+			}
+			return moves, "", comment, nil
+
+		case c == '[' && depth == 0:
+			return moves, "", comment, nil
+
+		case c == '$':
+			p.pos++
+			nag, err := strconv.Atoi(p.token())
+			if err != nil || len(moves) == 0 {
+				return nil, "", "", fmt.Errorf("malformed annotation")
+			}
+			moves[len(moves)-1].NAGs = append(moves[len(moves)-1].NAGs, nag)
+
+		default:
+			token := p.token()
+			if token == "" {
+				return nil, "", "", fmt.Errorf("unexpected %q", c)
+			}
+
+			if results[token] {
+				if depth > 0 {
+					continue
+				}
+				return moves, token, comment, nil
+			}
+
+			san, number := stripNumber(token)
+			if san == "" {
+				if !number {
+					return nil, "", "", fmt.Errorf("unexpected %q", token)
+				}
+				continue
+			}
+
+			san, nag := splitSuffix(san)
+			move := Move{SAN: san}
+			if nag != 0 {
+				move.NAGs = []int{nag}
+			}
+			moves = append(moves, move)
+		}
+	}
+
+	if depth > 0 {
+		return nil, "", "", fmt.Errorf("unterminated variation")
+	}
+
+	return moves, "", comment, nil
+}
+
+func (p *parser) token() string {
+	start := p.pos
+	for !p.done() && !unicode.IsSpace(rune(p.peek())) && !strings.ContainsRune("{}()[];$", rune(p.peek())) {
+		p.pos++
+	}
+
+	return p.text[start:p.pos]
+}
+
+// stripNumber removes a leading move number such as "12." or "12...".
+func stripNumber(token string) (string, bool) {
+	i := 0
+	for i < len(token) && token[i] >= '0' && token[i] <= '9' {
+		i++
+	}
+	if i == 0 || i == len(token) || token[i] != '.' {
+		return token, false
+	}
+
+	return strings.TrimLeft(token[i:], "."), true
+}
+
+var suffixes = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// splitSuffix turns move suffix annotations into their NAG.
+func splitSuffix(san string) (string, int) {
+	trimmed := strings.TrimRight(san, "!?")
+
+	return trimmed, suffixes[san[len(trimmed):]]
+}
+
+func joinComment(comment, text string) string {
+	if comment == "" {
+		return text
+	}
+
+	return comment + " " + text
+}
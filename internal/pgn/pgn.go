@@ -0,0 +1,153 @@
+// Package pgn reads and writes games in Portable Game Notation.
+package pgn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// Tag is a PGN header pair such as [Event "Casual game"].
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Move is one half-move of the movetext with what was written around it.
+type Move struct {
+	SAN     string
+	Comment string
+	// Before is a comment written before the move, as at the start of a
+	// variation.
+	Before     string
+	NAGs       []int
+	Variations [][]Move
+}
+
+// Game is a parsed PGN game. Moves is the main line.
+type Game struct {
+	Tags   []Tag
+	Moves  []Move
+	Result string
+	// Comment is written before the first move.
+	Comment string
+}
+
+// Tag returns the value of the named tag or an empty string.
+func (game Game) Tag(name string) string {
+	for _, tag := range game.Tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+
+	return ""
+}
+
+// SetTag replaces the value of the named tag or appends it.
+func (game *Game) SetTag(name, value string) {
+	for i, tag := range game.Tags {
+		if tag.Name == name {
+			game.Tags[i].Value = value
+			return
+		}
+	}
+
+	game.Tags = append(game.Tags, Tag{Name: name, Value: value})
+}
+
+// DeleteTag removes the named tag.
+func (game *Game) DeleteTag(name string) {
+	for i, tag := range game.Tags {
+		if tag.Name == name {
+			game.Tags = append(game.Tags[:i], game.Tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// Position is the board after a ply together with the move that led to it.
+type Position struct {
+	Board *board.Board
+	Team  board.Team
+	// Command is the move as accepted by board.Execute, empty for the start.
+	Command string
+	SAN     string
+	// Number is the full move number of the move.
+	Number int
+}
+
+// Replay plays the main line from the start position, or from the FEN tag,
+// and returns every position, the initial one first.
+func (game Game) Replay() ([]Position, error) {
+	current, team, err := game.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	number := fullmoveNumber(game.Tag("FEN"))
+	positions := []Position{{Board: current.Clone(), Team: team}}
+
+	for _, move := range game.Moves {
+		command, err := current.ParseSAN(move.SAN, team)
+		if err != nil {
+			return positions, fmt.Errorf("move %d: %w", number, err)
+		}
+
+		san, _ := current.SAN(command, team)
+		current.Execute(command, team)
+
+		positions = append(positions, Position{Board: current.Clone(), Team: team, Command: command, SAN: san, Number: number})
+		if team == board.Black {
+			number++
+		}
+		team = team.Opponent()
+	}
+
+	return positions, nil
+}
+
+// Start returns the position the game starts from, given by the FEN and
+// Variant tags, and the side to move.
+func (game Game) Start() (*board.Board, board.Team, error) {
+	variant, chess960, err := game.variant()
+	if err != nil {
+		return nil, board.Undecided, err
+	}
+
+	fen := game.Tag("FEN")
+	if fen == "" {
+		fen = variant.StartFEN()
+	}
+
+	start, team, err := board.ParseVariantFEN(fen, variant)
+	if err != nil {
+		return nil, board.Undecided, err
+	}
+	start.SetChess960(chess960)
+
+	return start, team, nil
+}
+
+// variant reads the Variant tag, which may also name Chess960.
+func (game Game) variant() (board.Variant, bool, error) {
+	name := game.Tag("Variant")
+	switch strings.ToLower(strings.ReplaceAll(name, " ", "")) {
+	case "chess960", "fischerandom", "fischerrandom":
+		return board.Standard, true, nil
+	}
+
+	variant, err := board.ParseVariant(name)
+	return variant, false, err
+}
+
+func fullmoveNumber(fen string) int {
+	fields := strings.Fields(fen)
+	number := 1
+	if len(fields) == 6 {
+		fmt.Sscan(fields[5], &number)
+	}
+
+	return number
+}
@@ -0,0 +1,127 @@
+package pgn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const annotated = `[Event "Club championship"]
+[Site "?"]
+[Date "2024.03.01"]
+[Round "1"]
+[White "Ann"]
+[Black "Bob"]
+[Result "1-0"]
+[Annotator "Coach"]
+
+{Opening comment} 1. e4 e5 2. Nf3 $1 {Develops} (2. f4 {The gambit} 2... exf4
+(2... d5)) 2... Nc6 (2... d6 3. d4) 3. Bb5 () ({Or} 3. Nc3) 3... a6 4. Ba4 1-0
+`
+
+func TestRoundTrip(t *testing.T) {
+	games, err := Parse(annotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("parsed %d games, want 1", len(games))
+	}
+
+	game := games[0]
+	if got := game.String(); got != annotated {
+		t.Errorf("written game differs:\n%s\nwant:\n%s", got, annotated)
+	}
+
+	again, err := Parse(game.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again[0], game) {
+		t.Errorf("reparsed game = %+v, want %+v", again[0], game)
+	}
+}
+
+func TestVariationComment(t *testing.T) {
+	game, err := ParseOne("1. e4 e5 ({Petrov} 1... Nf6 2. Nf3) 2. Nf3 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variation := game.Moves[1].Variations[0]
+	if variation[0].Before != "Petrov" || variation[0].SAN != "Nf6" {
+		t.Errorf("variation starts with %+v, want Nf6 after the comment Petrov", variation[0])
+	}
+	if game.Moves[1].Comment != "" {
+		t.Errorf("comment %q moved onto the main line", game.Moves[1].Comment)
+	}
+	if got, want := game.String(), "1. e4 e5 ({Petrov} 1... Nf6 2. Nf3) 2. Nf3 *\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("written movetext = %q, want %q", got, want)
+	}
+}
+
+func TestEmptyVariation(t *testing.T) {
+	game := &Game{Moves: []Move{{SAN: "e4", Variations: [][]Move{nil}}}, Result: "*"}
+	if got, want := game.String(), "1. e4 () *\n"; !strings.HasSuffix(got, want) {
+		t.Errorf("written movetext = %q, want %q", got, want)
+	}
+}
+
+func TestTags(t *testing.T) {
+	game := &Game{}
+	game.SetTag("Opening", "Sicilian")
+	game.SetTag("Variation", "Najdorf")
+	game.SetTag("Opening", "Sicilian defence")
+	game.DeleteTag("Variation")
+	game.DeleteTag("ECO")
+
+	want := []Tag{{Name: "Opening", Value: "Sicilian defence"}}
+	if !reflect.DeepEqual(game.Tags, want) {
+		t.Errorf("tags = %+v, want %+v", game.Tags, want)
+	}
+	if got := game.Tag("Variation"); got != "" {
+		t.Errorf("deleted tag Variation = %q", got)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	game, err := ParseOne("1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Bxc6 dxc6 5. O-O *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	positions, err := game.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 10 || positions[0].Command != "" {
+		t.Fatalf("%d positions starting with %q, want the start and 9 moves", len(positions), positions[0].Command)
+	}
+	var commands []string
+	for _, position := range positions[1:] {
+		commands = append(commands, position.Command)
+	}
+	want := []string{"e2 e4", "e7 e5", "g1 f3", "b8 c6", "f1 b5", "a7 a6", "b5 c6", "d7 c6", "e1 g1"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %v, want %v", commands, want)
+	}
+
+	game.Moves[2].SAN = "Nf6"
+	if _, err := game.Replay(); err == nil {
+		t.Error("replaying an illegal move succeeded")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"(1. e4) *",
+		"1. e4 (1. d4 *",
+		"1. e4 ) *",
+		`[Event "unterminated`,
+		"1. e4 {no end",
+	} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", text)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package pgn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sevenTags are written first and in this order, as the standard asks.
+var sevenTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// String writes the game in export format.
+func (game Game) String() string {
+	var sb strings.Builder
+
+	result := game.Result
+	if result == "" {
+		result = "*"
+	}
+
+	for _, name := range sevenTags {
+		value := game.Tag(name)
+		if name == "Result" {
+			value = result
+		} else if value == "" {
+			value = "?"
+		}
+		writeTag(&sb, name, value)
+	}
+	for _, tag := range game.Tags {
+		if !contains(sevenTags, tag.Name) {
+			writeTag(&sb, tag.Name, tag.Value)
+		}
+	}
+	sb.WriteString("\n")
+
+	var tokens []string
+	if game.Comment != "" {
+		tokens = append(tokens, "{"+game.Comment+"}")
+	}
+	tokens = appendMoves(tokens, game.Moves, startPly(game.Tag("FEN")))
+	tokens = append(tokens, result)
+
+	sb.WriteString(wrap(tokens, 79))
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+func writeTag(sb *strings.Builder, name, value string) {
+	value = strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`)
+	fmt.Fprintf(sb, "[%s \"%s\"]\n", name, value)
+}
+
+// appendMoves writes moves starting at the ply, counted from 0 for White's
+// first move.
+func appendMoves(tokens []string, moves []Move, ply int) []string {
+	for i, move := range moves {
+		if move.Before != "" {
+			tokens = append(tokens, "{"+move.Before+"}")
+		}
+
+		number := ply/2 + 1
+		if ply%2 == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		} else if i == 0 || move.Before != "" || moves[i-1].Comment != "" || len(moves[i-1].Variations) > 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+
+		tokens = append(tokens, move.SAN)
+		for _, nag := range move.NAGs {
+			tokens = append(tokens, fmt.Sprintf("$%d", nag))
+		}
+		if move.Comment != "" {
+			tokens = append(tokens, "{"+move.Comment+"}")
+		}
+		for _, variation := range move.Variations {
+			inner := appendMoves(nil, variation, ply)
+			if len(inner) == 0 {
+				tokens = append(tokens, "()")
+				continue
+			}
+			inner[0] = "(" + inner[0]
+			inner[len(inner)-1] += ")"
+			tokens = append(tokens, inner...)
+		}
+
+		ply++
+	}
+
+	return tokens
+}
+
+// startPly is the ply of the first move of a game starting from the FEN.
+func startPly(fen string) int {
+	ply := (fullmoveNumber(fen) - 1) * 2
+	if fields := strings.Fields(fen); len(fields) > 1 && fields[1] == "b" {
+		ply++
+	}
+
+	return ply
+}
+
+func wrap(tokens []string, width int) string {
+	var sb strings.Builder
+	line := 0
+
+	for _, token := range tokens {
+		if line > 0 && line+1+len(token) > width {
+			sb.WriteString("\n")
+			line = 0
+		} else if line > 0 {
+			sb.WriteString(" ")
+			line++
+		}
+		sb.WriteString(token)
+		line += len(token)
+	}
+
+	return sb.String()
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,177 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/leitner"
+)
+
+const (
+	// InitialRating is the puzzle rating of a new player.
+	InitialRating = 1500
+	// provisional is the number of rated puzzles during which the rating
+	// moves twice as fast.
+	provisional = 20
+)
+
+// schedule shows a failed puzzle again ever later while it is solved, and
+// no more once it is solved in the last box.
+var schedule = leitner.Schedule{
+	10 * time.Minute,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// Profile is the training record of a player.
+type Profile struct {
+	User     string  `json:"user"`
+	Rating   float64 `json:"rating"`
+	Rated    int     `json:"rated"`
+	Attempts int     `json:"attempts"`
+	Solved   int     `json:"solved"`
+	// Done holds the puzzles solved for good, which are not shown again.
+	Done map[string]bool `json:"done,omitempty"`
+	// Reviews holds the failed puzzles by id.
+	Reviews map[string]leitner.Card `json:"reviews,omitempty"`
+}
+
+func NewProfile(user string) *Profile {
+	return &Profile{
+		User:    user,
+		Rating:  InitialRating,
+		Done:    make(map[string]bool),
+		Reviews: make(map[string]leitner.Card),
+	}
+}
+
+// SuccessRate is the share of attempts solved, 0 before the first one.
+func (profile *Profile) SuccessRate() float64 {
+	if profile.Attempts == 0 {
+		return 0
+	}
+
+	return float64(profile.Solved) / float64(profile.Attempts)
+}
+
+// Record counts an attempt at the puzzle. The first attempt at a puzzle
+// changes the rating as an Elo game against the puzzle's rating; a failed
+// puzzle is scheduled for review.
+func (profile *Profile) Record(puzzle Puzzle, solved bool, now time.Time) {
+	profile.Attempts++
+	if solved {
+		profile.Solved++
+	}
+
+	review, reviewed := profile.Reviews[puzzle.ID]
+	if !reviewed && !profile.Done[puzzle.ID] {
+		k := 20.0
+		if profile.Rated < provisional {
+			k = 40
+		}
+		expected := 1 / (1 + math.Pow(10, (float64(puzzle.Rating)-profile.Rating)/400))
+		score := 0.0
+		if solved {
+			score = 1
+		}
+		profile.Rating += k * (score - expected)
+		profile.Rated++
+	}
+
+	switch {
+	case !solved:
+		profile.Reviews[puzzle.ID] = schedule.Fail(now)
+	case !reviewed || schedule.Last(review):
+		delete(profile.Reviews, puzzle.ID)
+		profile.Done[puzzle.ID] = true
+	default:
+		profile.Reviews[puzzle.ID] = schedule.Pass(review, now)
+	}
+}
+
+// Next picks the puzzle to play: the review that has been due longest,
+// otherwise the new puzzle whose rating is nearest the player's.
+func (profile *Profile) Next(puzzles []Puzzle, now time.Time) (Puzzle, bool) {
+	next, found := Puzzle{}, false
+	var due time.Time
+	for _, puzzle := range puzzles {
+		review, ok := profile.Reviews[puzzle.ID]
+		if ok && review.IsDue(now) && (!found || review.Due.Before(due)) {
+			next, found, due = puzzle, true, review.Due
+		}
+	}
+	if found {
+		return next, true
+	}
+
+	distance := math.Inf(1)
+	for _, puzzle := range puzzles {
+		if _, ok := profile.Reviews[puzzle.ID]; ok || profile.Done[puzzle.ID] {
+			continue
+		}
+		if d := math.Abs(float64(puzzle.Rating) - profile.Rating); d < distance {
+			next, found, distance = puzzle, true, d
+		}
+	}
+
+	return next, found
+}
+
+// DefaultDir is ~/.chess_on_golang/puzzles.
+func DefaultDir() string {
+	return datadir.Path("puzzles")
+}
+
+// LoadProfile reads the profile of the user from the directory, a new one
+// when there is none.
+func LoadProfile(dir, user string) (*Profile, error) {
+	path, err := datadir.File(dir, user, ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewProfile(user), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile := NewProfile(user)
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, err
+	}
+	if profile.Done == nil {
+		profile.Done = make(map[string]bool)
+	}
+	if profile.Reviews == nil {
+		profile.Reviews = make(map[string]leitner.Card)
+	}
+
+	return profile, nil
+}
+
+// Save writes the profile to the directory.
+func (profile *Profile) Save(dir string) error {
+	path, err := datadir.File(dir, profile.User, ".json")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return datadir.WriteFile(path, data)
+}
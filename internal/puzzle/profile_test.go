@@ -0,0 +1,77 @@
+package puzzle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordAndNext(t *testing.T) {
+	profile := NewProfile("ann")
+	puzzles := []Puzzle{
+		{ID: "easy", Rating: 1200},
+		{ID: "fair", Rating: 1450},
+		{ID: "hard", Rating: 2000},
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	next, ok := profile.Next(puzzles, now)
+	if !ok || next.ID != "fair" {
+		t.Fatalf("first puzzle = %q, want the one rated nearest 1500", next.ID)
+	}
+
+	profile.Record(next, false, now)
+	if profile.Rating >= InitialRating || profile.Rated != 1 {
+		t.Errorf("after a failure: rating %.1f, %d rated, want it lower after one rated puzzle", profile.Rating, profile.Rated)
+	}
+	if next, _ := profile.Next(puzzles, now); next.ID != "easy" {
+		t.Errorf("before the review is due the next puzzle is %q, want easy", next.ID)
+	}
+
+	// The failed puzzle comes back when due, then ever later while it is
+	// solved, and is done after the last box.
+	rating := profile.Rating
+	for box := 0; box < len(schedule); box++ {
+		now = now.Add(schedule[profile.Reviews["fair"].Box])
+		next, _ := profile.Next(puzzles, now)
+		if next.ID != "fair" {
+			t.Fatalf("review %d: next puzzle is %q, want the due review", box, next.ID)
+		}
+		profile.Record(next, true, now)
+	}
+	if !profile.Done["fair"] || len(profile.Reviews) != 0 {
+		t.Errorf("after the last box: done %v, reviews %v", profile.Done, profile.Reviews)
+	}
+	if profile.Rating != rating || profile.Rated != 1 {
+		t.Errorf("reviews changed the rating to %.1f", profile.Rating)
+	}
+	if profile.Attempts != 1+len(schedule) || profile.Solved != len(schedule) {
+		t.Errorf("attempts %d, solved %d", profile.Attempts, profile.Solved)
+	}
+
+	profile.Record(puzzles[0], true, now)
+	if next, _ := profile.Next(puzzles, now); next.ID != "hard" {
+		t.Errorf("next puzzle = %q, want the only one left", next.ID)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	profile := NewProfile("Ann")
+	profile.Record(Puzzle{ID: "x", Rating: 1500}, false, time.Now())
+	if err := profile.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Names are compared without case.
+	loaded, err := LoadProfile(dir, "ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Rating != profile.Rating || loaded.Attempts != 1 || len(loaded.Reviews) != 1 {
+		t.Errorf("loaded profile %+v, want %+v", loaded, profile)
+	}
+
+	if _, err := LoadProfile(dir, "../ann"); err == nil {
+		t.Error("loaded a profile from outside the directory")
+	}
+}
@@ -0,0 +1,276 @@
+// Package puzzle trains tactics: puzzles are read from CSV or EPD files,
+// played move by move against the board, and a local profile keeps the
+// player's puzzle rating, success rate and the failed puzzles due again.
+package puzzle
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/uci"
+)
+
+// DefaultRating is given to puzzles without one.
+const DefaultRating = 1500
+
+// Puzzle is a position and the line that solves it.
+type Puzzle struct {
+	ID string
+	// FEN is the position with the solver to move.
+	FEN string
+	// Solution holds the solver's moves and the replies in turn, in the
+	// form accepted by Board.Execute.
+	Solution []string
+	Rating   int
+	Themes   []string
+	// LastMove is the opponent's move that led to the position in SAN,
+	// empty when the file does not give it.
+	LastMove string
+}
+
+// Start returns the position of the puzzle and the solver.
+func (puzzle Puzzle) Start() (*board.Board, board.Team, error) {
+	return board.ParseFEN(puzzle.FEN)
+}
+
+// Load reads a puzzle file, EPD when the extension is .epd and CSV
+// otherwise.
+func Load(path string) ([]Puzzle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var puzzles []Puzzle
+	if strings.EqualFold(filepath.Ext(path), ".epd") {
+		puzzles, err = ReadEPD(file)
+	} else {
+		puzzles, err = ReadCSV(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return puzzles, nil
+}
+
+// ReadCSV reads puzzles in the columns of the lichess puzzle database: id,
+// FEN, moves, then optionally rating, rating deviation, popularity, plays
+// and themes. The moves are in UCI notation, e.g. e7e8q, and the first one
+// is the opponent's move the puzzle starts after. A header line starting
+// with PuzzleId is skipped.
+func ReadCSV(r io.Reader) ([]Puzzle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var puzzles []Puzzle
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "PuzzleId") {
+			continue
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected id, FEN and moves", line)
+		}
+
+		puzzle, err := fromCSV(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		puzzles = append(puzzles, puzzle)
+	}
+
+	return puzzles, nil
+}
+
+func fromCSV(record []string) (Puzzle, error) {
+	b, team, err := board.ParseFEN(record[1])
+	if err != nil {
+		return Puzzle{}, err
+	}
+
+	moves := strings.Fields(record[2])
+	if len(moves) < 2 {
+		return Puzzle{}, errors.New("expected the opponent's move and a solution")
+	}
+
+	setup, err := legalMove(b, team, uci.Command(moves[0]))
+	if err != nil {
+		return Puzzle{}, err
+	}
+	puzzle := Puzzle{ID: record[0], Rating: DefaultRating}
+	puzzle.LastMove, _ = b.SAN(setup, team)
+	b.Execute(setup, team)
+	puzzle.FEN = b.FEN(team.Opponent())
+
+	for _, move := range moves[1:] {
+		puzzle.Solution = append(puzzle.Solution, uci.Command(move))
+	}
+	if len(record) > 3 && record[3] != "" {
+		if puzzle.Rating, err = strconv.Atoi(record[3]); err != nil {
+			return Puzzle{}, fmt.Errorf("bad rating %q", record[3])
+		}
+	}
+	if len(record) > 7 {
+		puzzle.Themes = strings.Fields(record[7])
+	}
+
+	return puzzle, puzzle.check()
+}
+
+// ReadEPD reads puzzles from EPD lines: the first four FEN fields, then
+// operations ended by semicolons. The solution is the pv operation or the
+// first move of bm, in SAN; id names the puzzle.
+func ReadEPD(r io.Reader) ([]Puzzle, error) {
+	scanner := bufio.NewScanner(r)
+
+	var puzzles []Puzzle
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		puzzle, err := fromEPD(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if puzzle.ID == "" {
+			puzzle.ID = strconv.Itoa(line)
+		}
+		puzzles = append(puzzles, puzzle)
+	}
+
+	return puzzles, scanner.Err()
+}
+
+func fromEPD(text string) (Puzzle, error) {
+	fields := strings.SplitN(text, " ", 5)
+	if len(fields) < 5 {
+		return Puzzle{}, errors.New("expected four FEN fields and operations")
+	}
+
+	b, team, err := board.ParseFEN(strings.Join(fields[:4], " ") + " 0 1")
+	if err != nil {
+		return Puzzle{}, err
+	}
+	puzzle := Puzzle{FEN: b.FEN(team), Rating: DefaultRating}
+
+	var line []string
+	for opcode, operands := range operations(fields[4]) {
+		switch opcode {
+		case "id":
+			puzzle.ID = strings.Join(operands, " ")
+		case "pv":
+			line = operands
+		case "bm":
+			if line == nil && len(operands) > 0 {
+				line = operands[:1]
+			}
+		}
+	}
+	if len(line) == 0 {
+		return Puzzle{}, errors.New("no bm or pv operation")
+	}
+
+	position := b.Clone()
+	for _, san := range line {
+		command, err := position.ParseSAN(san, team)
+		if err != nil {
+			return Puzzle{}, err
+		}
+		position.Execute(command, team)
+		puzzle.Solution = append(puzzle.Solution, command)
+		team = team.Opponent()
+	}
+
+	return puzzle, nil
+}
+
+// operations splits the operations of an EPD line into their opcode and
+// operands, quoted operands kept whole.
+func operations(text string) map[string][]string {
+	found := make(map[string][]string)
+
+	var tokens []string
+	var token strings.Builder
+	quoted := false
+	flush := func() {
+		if token.Len() > 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+	for _, char := range text {
+		switch {
+		case char == '"':
+			quoted = !quoted
+		case quoted:
+			token.WriteRune(char)
+		case char == ';':
+			flush()
+			if len(tokens) > 0 {
+				found[tokens[0]] = tokens[1:]
+			}
+			tokens = nil
+		case char == ' ' || char == '\t':
+			flush()
+		default:
+			token.WriteRune(char)
+		}
+	}
+	flush()
+	if len(tokens) > 0 {
+		found[tokens[0]] = tokens[1:]
+	}
+
+	return found
+}
+
+// check replays the solution, so that a broken puzzle is found when it is
+// read.
+func (puzzle Puzzle) check() error {
+	b, team, err := puzzle.Start()
+	if err != nil {
+		return err
+	}
+
+	for i, move := range puzzle.Solution {
+		command, err := legalMove(b, team, move)
+		if err != nil {
+			return err
+		}
+		puzzle.Solution[i] = command
+		b.Execute(command, team)
+		team = team.Opponent()
+	}
+
+	return nil
+}
+
+// legalMove returns the legal move of the team written as the command,
+// whatever the case of its promotion letter.
+func legalMove(b *board.Board, team board.Team, command string) (string, error) {
+	for _, move := range b.LegalMoves(team) {
+		if strings.EqualFold(move, command) {
+			return move, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: illegal move", command)
+}
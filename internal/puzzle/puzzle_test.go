@@ -0,0 +1,100 @@
+package puzzle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func TestReadCSV(t *testing.T) {
+	puzzles, err := ReadCSV(strings.NewReader(`PuzzleId,FEN,Moves,Rating,RatingDeviation,Popularity,NbPlays,Themes,GameUrl,OpeningTags
+00sHx,q3k1nr/1pp1nQpp/3p4/1P2p3/4P3/B1PP1b2/B5PP/5K2 b k - 0 17,e8d7 a2e6 d7d8 f7f8,1760,80,83,72,mate mateIn2 middlegame short,https://lichess.org/yyznGmXs/black#34,
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(puzzles) != 1 {
+		t.Fatalf("read %d puzzles, want 1", len(puzzles))
+	}
+
+	// The first move is the opponent's, the puzzle starts after it.
+	puzzle := puzzles[0]
+	want := Puzzle{
+		ID:       "00sHx",
+		FEN:      "q5nr/1ppknQpp/3p4/1P2p3/4P3/B1PP1b2/B5PP/5K2 w - - 1 18",
+		Solution: []string{"a2 e6", "d7 d8", "f7 f8"},
+		Rating:   1760,
+		Themes:   []string{"mate", "mateIn2", "middlegame", "short"},
+		LastMove: "Kd7",
+	}
+	if !reflect.DeepEqual(puzzle, want) {
+		t.Errorf("puzzle = %+v, want %+v", puzzle, want)
+	}
+
+	if _, err := ReadCSV(strings.NewReader("x,4k3/8/8/8/8/8/8/4K3 w - - 0 1,e1e3 e8e7\n")); err == nil {
+		t.Error("read a puzzle whose first move is illegal")
+	}
+}
+
+func TestReadEPD(t *testing.T) {
+	puzzles, err := ReadEPD(strings.NewReader(`# comments and blank lines are skipped
+
+kbK5/pp6/1P6/8/8/8/8/R7 w - - bm Ra6; pv Ra6 bxa6 b7#; id "Morphy, mate in 2";
+r1bqkbnr/pppp1ppp/2n5/4p3/2B1P3/5Q2/PPPP1PPP/RNB1K1NR w KQkq - bm Qxf7# Bxf7+;
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(puzzles) != 2 {
+		t.Fatalf("read %d puzzles, want 2", len(puzzles))
+	}
+
+	for i, want := range []struct {
+		id       string
+		solution []string
+	}{
+		{"Morphy, mate in 2", []string{"a1 a6", "b7 a6", "b6 b7"}},
+		{"4", []string{"f3 f7"}},
+	} {
+		if puzzles[i].ID != want.id || !reflect.DeepEqual(puzzles[i].Solution, want.solution) {
+			t.Errorf("puzzle %d = %q %v, want %q %v", i, puzzles[i].ID, puzzles[i].Solution, want.id, want.solution)
+		}
+	}
+
+	for _, line := range []string{
+		"6k1/5ppp/8/8/8/8/8/R5K1 w - - id \"no solution\";",
+		"6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Rb8 Ra9;",
+		"6k1/5ppp/8/8/8/8/8/R5K1 w -",
+	} {
+		if _, err := ReadEPD(strings.NewReader(line)); err == nil {
+			t.Errorf("ReadEPD(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	b, team, err := board.ParseFEN("6k1/5ppp/8/8/8/8/8/R3R1K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainer := &Trainer{}
+
+	for _, test := range []struct {
+		command  string
+		solution []string
+		want     bool
+	}{
+		{"a1 a8", []string{"a1 a8"}, true},
+		// Another mate is as good on the last move.
+		{"e1 e8", []string{"a1 a8"}, true},
+		{"a1 a7", []string{"a1 a8"}, false},
+		// But not earlier, the line must be followed there.
+		{"e1 e8", []string{"a1 a7", "h7 h6", "a7 a8"}, false},
+	} {
+		if got := trainer.correct(b, team, test.command, test.solution, 0); got != test.want {
+			t.Errorf("correct(%s) against %v = %v, want %v", test.command, test.solution, got, test.want)
+		}
+	}
+}
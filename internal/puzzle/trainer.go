@@ -0,0 +1,140 @@
+package puzzle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/uci"
+)
+
+// Trainer plays puzzles with a player typing moves, in SAN, as commands
+// such as "e2 e4" or in UCI, the opponent's replies being played for
+// them. "skip" gives up a puzzle and "quit" ends the session.
+type Trainer struct {
+	Profile *Profile
+	Puzzles []Puzzle
+	reader  *bufio.Reader
+	out     io.Writer
+}
+
+func NewTrainer(profile *Profile, puzzles []Puzzle, in io.Reader, out io.Writer) *Trainer {
+	return &Trainer{Profile: profile, Puzzles: puzzles, reader: bufio.NewReader(in), out: out}
+}
+
+// Run plays up to count puzzles, all of them when count is zero, until
+// none is left or the player quits. onRecord is called after each one,
+// e.g. to save the profile.
+func (trainer *Trainer) Run(count int, onRecord func(*Profile) error) error {
+	for played := 0; count == 0 || played < count; played++ {
+		puzzle, ok := trainer.Profile.Next(trainer.Puzzles, time.Now())
+		if !ok {
+			fmt.Fprintln(trainer.out, "No puzzle left for now.")
+			return nil
+		}
+
+		solved, quit, err := trainer.Play(puzzle)
+		if err != nil {
+			return fmt.Errorf("puzzle %s: %w", puzzle.ID, err)
+		}
+		if quit {
+			return nil
+		}
+
+		before := trainer.Profile.Rating
+		trainer.Profile.Record(puzzle, solved, time.Now())
+		fmt.Fprintf(trainer.out, "Rating %.0f (%+.0f), solved %d of %d (%.0f%%)\n\n",
+			trainer.Profile.Rating, trainer.Profile.Rating-before,
+			trainer.Profile.Solved, trainer.Profile.Attempts, 100*trainer.Profile.SuccessRate())
+
+		if onRecord != nil {
+			if err := onRecord(trainer.Profile); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Play plays one puzzle. quit is true when the player ends the session or
+// the input is closed.
+func (trainer *Trainer) Play(puzzle Puzzle) (solved, quit bool, err error) {
+	b, team, err := puzzle.Start()
+	if err != nil {
+		return false, false, err
+	}
+	start, solver := b.Clone(), team
+
+	fmt.Fprintf(trainer.out, "Puzzle %s, rated %d.", puzzle.ID, puzzle.Rating)
+	if puzzle.LastMove != "" {
+		fmt.Fprintf(trainer.out, " %s played %s.", team.Opponent(), puzzle.LastMove)
+	}
+	fmt.Fprintf(trainer.out, " Find the best move for %s.\n%s\n", team, b.String())
+
+	for i := 0; i < len(puzzle.Solution); i += 2 {
+		command, quit, gaveUp := trainer.readMove(b, team)
+		if quit {
+			return false, true, nil
+		}
+		if gaveUp || !trainer.correct(b, team, command, puzzle.Solution, i) {
+			line := engine.LineSAN(start, solver, puzzle.Solution)
+			fmt.Fprintln(trainer.out, "Wrong. The solution was", strings.Join(line, " "))
+			return false, false, nil
+		}
+
+		b.Execute(command, team)
+		if i+1 == len(puzzle.Solution) {
+			break
+		}
+
+		reply := puzzle.Solution[i+1]
+		san, _ := b.SAN(reply, team.Opponent())
+		b.Execute(reply, team.Opponent())
+		fmt.Fprintf(trainer.out, "Correct. %s replies %s.\n%s\n", team.Opponent(), san, b.String())
+	}
+
+	fmt.Fprintln(trainer.out, "Solved!")
+	return true, false, nil
+}
+
+// correct reports whether the move is the one of the solution at i. A
+// different move that mates is as good at the end of the line.
+func (trainer *Trainer) correct(b *board.Board, team board.Team, command string, solution []string, i int) bool {
+	if command == solution[i] {
+		return true
+	}
+
+	return i == len(solution)-1 && b.Clone().Execute(command, team)
+}
+
+// readMove asks for a move until a legal one is typed.
+func (trainer *Trainer) readMove(b *board.Board, team board.Team) (command string, quit, gaveUp bool) {
+	for {
+		fmt.Fprintf(trainer.out, "%s> ", team)
+		input, err := trainer.reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err == io.EOF && input == "" {
+			fmt.Fprintln(trainer.out)
+			return "", true, false
+		}
+
+		switch strings.ToLower(input) {
+		case "":
+			continue
+		case "quit":
+			return "", true, false
+		case "skip":
+			return "", false, true
+		}
+
+		if command, err := uci.ParseMove(b, team, input); err == nil {
+			return command, false, false
+		}
+		fmt.Fprintln(trainer.out, "Illegal move, try again, or type skip or quit.")
+	}
+}
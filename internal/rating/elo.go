@@ -0,0 +1,35 @@
+package rating
+
+import "math"
+
+const (
+	// InitialElo is the Elo rating of a new player.
+	InitialElo = 1500
+	// provisionalGames is the number of games during which a rating is
+	// provisional.
+	provisionalGames = 20
+)
+
+// ExpectedScore is the score the Elo rating predicts against the opponent.
+func ExpectedScore(rating, opponent float64) float64 {
+	return 1 / (1 + math.Pow(10, (opponent-rating)/400))
+}
+
+// UpdateElo returns the rating after a game with the score, 1, 0.5 or 0,
+// against the opponent.
+func UpdateElo(rating, opponent, score, k float64) float64 {
+	return rating + k*(score-ExpectedScore(rating, opponent))
+}
+
+// kFactor follows FIDE: 40 while the rating is provisional, 10 from 2400
+// and 20 otherwise.
+func (rating *Rating) kFactor() float64 {
+	switch {
+	case rating.Games < provisionalGames:
+		return 40
+	case rating.Elo >= 2400:
+		return 10
+	default:
+		return 20
+	}
+}
@@ -0,0 +1,59 @@
+package rating
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format writes a rating as a whole number, with a "?" while it is
+// provisional.
+func Format(value float64, provisional bool) string {
+	text := fmt.Sprintf("%.0f", value)
+	if provisional {
+		text += "?"
+	}
+
+	return text
+}
+
+// Summary writes a line per category the player has played in, e.g.
+// "blitz      Glicko 1662? ± 290  Elo 1540?  3 games +2 =0 -1".
+func (player *Player) Summary() string {
+	var text strings.Builder
+	for _, category := range Categories {
+		rating, ok := player.Ratings[category]
+		if !ok {
+			continue
+		}
+		provisional := rating.Provisional()
+		fmt.Fprintf(&text, "%-10s Glicko %-5s ± %3.0f  Elo %-5s  %d games +%d =%d -%d\n",
+			category, Format(rating.Glicko.Rating, provisional), rating.Glicko.Deviation,
+			Format(rating.Elo, provisional), rating.Games, rating.Wins, rating.Draws, rating.Losses)
+	}
+	if text.Len() == 0 {
+		return player.Name + " has no rated games.\n"
+	}
+
+	return text.String()
+}
+
+// Table writes a leaderboard with a line per player.
+func Table(standings []Standing) string {
+	if len(standings) == 0 {
+		return "No established ratings yet.\n"
+	}
+
+	width := len("Name")
+	for _, standing := range standings {
+		width = max(width, len(standing.Name))
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%3s  %-*s  %6s  %3s  %5s  %5s\n", "#", width, "Name", "Glicko", "RD", "Elo", "Games")
+	for _, standing := range standings {
+		fmt.Fprintf(&text, "%3d  %-*s  %6.0f  %3.0f  %5.0f  %5d\n",
+			standing.Rank, width, standing.Name, standing.Rating, standing.Deviation, standing.Elo, standing.Games)
+	}
+
+	return text.String()
+}
@@ -0,0 +1,125 @@
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// InitialGlicko, InitialDeviation and InitialVolatility are the
+	// Glicko-2 rating of a new player.
+	InitialGlicko     = 1500
+	InitialDeviation  = 350
+	InitialVolatility = 0.06
+	// ProvisionalDeviation is the deviation above which a Glicko-2 rating
+	// is provisional.
+	ProvisionalDeviation = 110
+	// RatingPeriod is the time over which the deviation of an idle player
+	// grows by the volatility.
+	RatingPeriod = 24 * time.Hour
+
+	// tau constrains how fast the volatility changes.
+	tau = 0.5
+	// scale converts ratings to the Glicko-2 scale.
+	scale       = 173.7178
+	convergence = 0.000001
+)
+
+// Glicko is a Glicko-2 rating. Every game is rated as a rating period of
+// its own, as on online servers.
+type Glicko struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+func NewGlicko() Glicko {
+	return Glicko{Rating: InitialGlicko, Deviation: InitialDeviation, Volatility: InitialVolatility}
+}
+
+// At returns the rating at the time now for a player whose last game was at
+// last: the deviation grows with the rating periods since, up to that of a
+// new player.
+func (glicko Glicko) At(last, now time.Time) Glicko {
+	if last.IsZero() || !now.After(last) {
+		return glicko
+	}
+
+	periods := float64(now.Sub(last)) / float64(RatingPeriod)
+	phi := glicko.Deviation / scale
+	phi = math.Sqrt(phi*phi + periods*glicko.Volatility*glicko.Volatility)
+	glicko.Deviation = math.Min(phi*scale, InitialDeviation)
+	return glicko
+}
+
+// Update returns the rating after a game with the score, 1, 0.5 or 0,
+// against the opponent.
+func (glicko Glicko) Update(opponent Glicko, score float64) Glicko {
+	return glicko.period([]outcome{{opponent, score}})
+}
+
+// outcome is a game of a rating period.
+type outcome struct {
+	opponent Glicko
+	score    float64
+}
+
+// period returns the rating after the games of a rating period, by the
+// steps of Glickman's "Example of the Glicko-2 system".
+func (glicko Glicko) period(outcomes []outcome) Glicko {
+	mu, phi := (glicko.Rating-InitialGlicko)/scale, glicko.Deviation/scale
+
+	var information, improvement float64
+	for _, game := range outcomes {
+		opponentMu, opponentPhi := (game.opponent.Rating-InitialGlicko)/scale, game.opponent.Deviation/scale
+		g := 1 / math.Sqrt(1+3*opponentPhi*opponentPhi/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-g*(mu-opponentMu)))
+		information += g * g * expected * (1 - expected)
+		improvement += g * (game.score - expected)
+	}
+	v := 1 / information
+	delta := v * improvement
+
+	sigma := volatility(phi, glicko.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu += phi * phi * improvement
+
+	return Glicko{Rating: mu*scale + InitialGlicko, Deviation: phi * scale, Volatility: sigma}
+}
+
+// volatility finds the new volatility by the Illinois algorithm.
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	low := a
+	var high float64
+	if delta*delta > phi*phi+v {
+		high = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		high = a - k*tau
+	}
+
+	fLow, fHigh := f(low), f(high)
+	for math.Abs(high-low) > convergence {
+		next := low + (low-high)*fLow/(fHigh-fLow)
+		fNext := f(next)
+		if fNext*fHigh <= 0 {
+			low, fLow = high, fHigh
+		} else {
+			fLow /= 2
+		}
+		high, fHigh = next, fNext
+	}
+
+	return math.Exp(low / 2)
+}
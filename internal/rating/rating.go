@@ -0,0 +1,225 @@
+// Package rating rates the players of the server. Every player has an Elo
+// and a Glicko-2 rating for each time control, both updated after every
+// rated game, with a history of the changes.
+package rating
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+// Category is the kind of time control a game is rated in.
+type Category string
+
+const (
+	Bullet    Category = "bullet"
+	Blitz     Category = "blitz"
+	Rapid     Category = "rapid"
+	Classical Category = "classical"
+)
+
+// Categories lists the categories from the fastest.
+var Categories = []Category{Bullet, Blitz, Rapid, Classical}
+
+func ParseCategory(name string) (Category, error) {
+	for _, category := range Categories {
+		if strings.EqualFold(name, string(category)) {
+			return category, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown rating category %q", name)
+}
+
+// CategoryOf classifies a time control by the estimated length of the game
+// for each player, the base time plus 40 increments: under 3 minutes is
+// bullet, under 8 blitz, under 25 rapid and anything longer classical. A
+// game without a clock is classical.
+func CategoryOf(control game.TimeControl) Category {
+	if control.Base <= 0 {
+		return Classical
+	}
+
+	switch estimated := control.Base + 40*control.Increment; {
+	case estimated < 3*time.Minute:
+		return Bullet
+	case estimated < 8*time.Minute:
+		return Blitz
+	case estimated < 25*time.Minute:
+		return Rapid
+	default:
+		return Classical
+	}
+}
+
+// CategoryOfGame classifies a game by its clock.
+func CategoryOfGame(chessGame *game.ChessGame) Category {
+	if clock := chessGame.Clock(); clock != nil {
+		return CategoryOf(clock.Control())
+	}
+
+	return Classical
+}
+
+// Rated reports whether a game counts for the ratings: it must be standard
+// chess from the initial position, as variants, Chess960 and set up
+// positions have no categories of their own, it must be over and both
+// players must have moved, so that aborted games are left out.
+func Rated(chessGame *game.ChessGame) bool {
+	if chessGame.Variant() != board.Standard || chessGame.StartFEN() != board.StartFEN {
+		return false
+	}
+
+	_, over := chessGame.Result()
+	return over && len(chessGame.Moves()) >= 2
+}
+
+// Rating is the standing of a player in a category.
+type Rating struct {
+	Elo    float64 `json:"elo"`
+	Glicko Glicko  `json:"glicko"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+	// LastPlayed is the end of the last rated game, from which the
+	// Glicko-2 deviation grows again.
+	LastPlayed time.Time `json:"lastPlayed"`
+	History    []Change  `json:"history,omitempty"`
+}
+
+// Change records a rated game and the ratings after it.
+type Change struct {
+	Time     time.Time `json:"time"`
+	Opponent string    `json:"opponent"`
+	// Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+	Score  float64 `json:"score"`
+	Elo    float64 `json:"elo"`
+	Glicko float64 `json:"glicko"`
+	// Deviation is the Glicko-2 rating deviation.
+	Deviation float64 `json:"deviation"`
+}
+
+func NewRating() *Rating {
+	return &Rating{Elo: InitialElo, Glicko: NewGlicko()}
+}
+
+// Provisional reports whether the rating is not yet established: the
+// player has fewer than provisionalGames games, during which Elo moves
+// faster, or a Glicko-2 deviation above ProvisionalDeviation.
+func (rating *Rating) Provisional() bool {
+	return rating.Games < provisionalGames || rating.Glicko.Deviation > ProvisionalDeviation
+}
+
+// Player holds the ratings of a player by category.
+type Player struct {
+	Name    string               `json:"name"`
+	Ratings map[Category]*Rating `json:"ratings"`
+}
+
+func NewPlayer(name string) *Player {
+	return &Player{Name: name, Ratings: make(map[Category]*Rating)}
+}
+
+// Rating returns the rating of the player in the category, a new one when
+// the player has not played in it.
+func (player *Player) Rating(category Category) *Rating {
+	if player.Ratings == nil {
+		player.Ratings = make(map[Category]*Rating)
+	}
+	if player.Ratings[category] == nil {
+		player.Ratings[category] = NewRating()
+	}
+
+	return player.Ratings[category]
+}
+
+// Rate updates the ratings of both players in the category after a game
+// they finished at the time. Both updates use the ratings from before the
+// game.
+func Rate(white, black *Player, category Category, result game.Result, at time.Time) {
+	score := 0.5
+	switch result.Winner {
+	case board.White:
+		score = 1
+	case board.Black:
+		score = 0
+	}
+
+	whiteRating, blackRating := white.Rating(category), black.Rating(category)
+	whiteGlicko := whiteRating.Glicko.At(whiteRating.LastPlayed, at)
+	blackGlicko := blackRating.Glicko.At(blackRating.LastPlayed, at)
+	whiteElo, blackElo := whiteRating.Elo, blackRating.Elo
+
+	whiteRating.update(black.Name, score, blackElo, whiteGlicko.Update(blackGlicko, score), at)
+	blackRating.update(white.Name, 1-score, whiteElo, blackGlicko.Update(whiteGlicko, 1-score), at)
+}
+
+func (rating *Rating) update(opponent string, score, opponentElo float64, glicko Glicko, at time.Time) {
+	rating.Elo = UpdateElo(rating.Elo, opponentElo, score, rating.kFactor())
+	rating.Glicko = glicko
+	rating.Games++
+	switch score {
+	case 1:
+		rating.Wins++
+	case 0:
+		rating.Losses++
+	default:
+		rating.Draws++
+	}
+	rating.LastPlayed = at
+	rating.History = append(rating.History, Change{
+		Time:      at,
+		Opponent:  opponent,
+		Score:     score,
+		Elo:       rating.Elo,
+		Glicko:    glicko.Rating,
+		Deviation: glicko.Deviation,
+	})
+}
+
+// Standing is a line of a leaderboard.
+type Standing struct {
+	Rank      int     `json:"rank"`
+	Name      string  `json:"name"`
+	Rating    float64 `json:"rating"`
+	Deviation float64 `json:"deviation"`
+	Elo       float64 `json:"elo"`
+	Games     int     `json:"games"`
+}
+
+// Leaderboard ranks the players with an established rating in the
+// category by their Glicko-2 rating.
+func Leaderboard(players []*Player, category Category) []Standing {
+	var standings []Standing
+	for _, player := range players {
+		rating, ok := player.Ratings[category]
+		if !ok || rating.Provisional() {
+			continue
+		}
+		standings = append(standings, Standing{
+			Name:      player.Name,
+			Rating:    rating.Glicko.Rating,
+			Deviation: rating.Glicko.Deviation,
+			Elo:       rating.Elo,
+			Games:     rating.Games,
+		})
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if standings[i].Rating != standings[j].Rating {
+			return standings[i].Rating > standings[j].Rating
+		}
+		return standings[i].Name < standings[j].Name
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+
+	return standings
+}
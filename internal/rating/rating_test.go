@@ -0,0 +1,99 @@
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+// TestGlickmanExample reproduces the example of Glickman's "Example of the
+// Glicko-2 system": a player rated 1500 with a deviation of 200 beats a
+// 1400 and loses to a 1550 and a 1700 in a rating period.
+func TestGlickmanExample(t *testing.T) {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	got := player.period([]outcome{
+		{Glicko{Rating: 1400, Deviation: 30}, 1},
+		{Glicko{Rating: 1550, Deviation: 100}, 0},
+		{Glicko{Rating: 1700, Deviation: 300}, 0},
+	})
+
+	if !near(got.Rating, 1464.06, 0.01) || !near(got.Deviation, 151.52, 0.01) || !near(got.Volatility, 0.05999, 0.00001) {
+		t.Errorf("rating = %+v, want 1464.06, 151.52 and 0.05999", got)
+	}
+}
+
+func TestUpdateIsOneGamePeriod(t *testing.T) {
+	player, opponent := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}, Glicko{Rating: 1400, Deviation: 30}
+	if got, want := player.Update(opponent, 1), player.period([]outcome{{opponent, 1}}); got != want {
+		t.Errorf("Update = %+v, want %+v", got, want)
+	}
+}
+
+func TestElo(t *testing.T) {
+	for _, test := range []struct {
+		rating, opponent, score, k, want float64
+	}{
+		{1500, 1500, 1, 20, 1510},
+		{1500, 1500, 0.5, 20, 1500},
+		{1600, 1400, 0, 10, 1600 - 10*0.7597},
+		{2400, 2000, 0.5, 10, 2400 + 10*(0.5-0.9091)},
+	} {
+		if got := UpdateElo(test.rating, test.opponent, test.score, test.k); !near(got, test.want, 0.01) {
+			t.Errorf("UpdateElo(%v, %v, %v, %v) = %.2f, want %.2f", test.rating, test.opponent, test.score, test.k, got, test.want)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	chessGame, err := game.NewFromFEN("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, move := range []string{"f2 f3", "e7 e5", "g2 g4", "d8 h4"} {
+		if err := chessGame.Play(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !Rated(chessGame) {
+		t.Fatal("a finished standard game is not rated")
+	}
+
+	white, black := NewPlayer("ann"), NewPlayer("bob")
+	now := time.Now()
+	Rate(white, black, CategoryOfGame(chessGame), game.Result{Winner: board.Black}, now)
+
+	whiteRating, blackRating := white.Rating(Classical), black.Rating(Classical)
+	if whiteRating.Losses != 1 || blackRating.Wins != 1 {
+		t.Fatalf("ann %+v, bob %+v, want a win for bob", whiteRating, blackRating)
+	}
+	// Both start equal, so the changes mirror each other.
+	if !near(whiteRating.Elo+blackRating.Elo, 2*InitialElo, 1e-9) || !near(whiteRating.Glicko.Rating+blackRating.Glicko.Rating, 2*InitialGlicko, 1e-9) {
+		t.Errorf("ratings %v and %v are not symmetric", whiteRating.Elo, blackRating.Elo)
+	}
+}
+
+func TestRatedLeavesOutSetUpPositions(t *testing.T) {
+	chessGame, err := game.NewFromFEN("4k3/8/8/8/8/8/8/4K2R w K - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, move := range []string{"h1 h8", "e8 e7"} {
+		if err := chessGame.Play(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chessGame.Resign(board.Black); err != nil {
+		t.Fatal(err)
+	}
+
+	if Rated(chessGame) {
+		t.Error("a game from a set up position is rated")
+	}
+}
@@ -0,0 +1,227 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// Diagrams are laid out in board units: every square is unit x unit, the
+// y axis points down like in SVG and images.
+const unit = 100
+
+type DiagramOptions struct {
+	Options
+	// Size is the width of the picture in pixels, 480 by default. The height
+	// follows the ranks of the board.
+	Size int
+	// Arrows are drawn between pairs of squares such as {"e2", "e4"}.
+	Arrows [][2]string
+	// Caption is written in a strip below the board, which makes the
+	// picture taller than wide.
+	Caption string
+}
+
+var (
+	lightSquare = color.RGBA{0xF0, 0xD9, 0xB5, 0xFF}
+	darkSquare  = color.RGBA{0xB5, 0x88, 0x63, 0xFF}
+	highlight   = color.RGBA{0xCD, 0xD2, 0x6A, 0xB0}
+	arrowColor  = color.RGBA{0x15, 0x78, 0x1B, 0xB0}
+	whiteFill   = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	blackFill   = color.RGBA{0x2A, 0x2A, 0x2A, 0xFF}
+	outline     = color.RGBA{0x00, 0x00, 0x00, 0xFF}
+	captionFill = color.RGBA{0x31, 0x2E, 0x2B, 0xFF}
+	captionText = color.RGBA{0xEE, 0xEE, 0xEE, 0xFF}
+)
+
+// captionHeight is the height of the caption strip in diagram units.
+const captionHeight = 60
+
+type point struct {
+	x, y float64
+}
+
+// shape is one part of a piece: a polygon, or a circle when radius is set.
+type shape struct {
+	polygon []point
+	center  point
+	radius  float64
+}
+
+func poly(coordinates ...float64) shape {
+	var points []point
+	for i := 0; i+1 < len(coordinates); i += 2 {
+		points = append(points, point{coordinates[i], coordinates[i+1]})
+	}
+
+	return shape{polygon: points}
+}
+
+func circle(x, y, radius float64) shape {
+	return shape{center: point{x, y}, radius: radius}
+}
+
+const strokeWidth = 3
+
+// pieceArt draws every piece in a 100x100 square, keyed by the lowercase
+// sign. Shapes are filled and outlined in order.
+var pieceArt = map[string][]shape{
+	"p": {
+		poly(22, 88, 78, 88, 74, 74, 26, 74),
+		poly(40, 44, 60, 44, 66, 74, 34, 74),
+		circle(50, 32, 13),
+	},
+	"r": {
+		poly(22, 88, 78, 88, 76, 76, 24, 76),
+		poly(32, 76, 68, 76, 64, 40, 36, 40),
+		poly(28, 40, 72, 40, 72, 18, 62, 18, 62, 27, 55, 27, 55, 18, 45, 18, 45, 27, 38, 27, 38, 18, 28, 18),
+	},
+	"n": {
+		poly(28, 88, 78, 88, 76, 62, 70, 38, 58, 22, 50, 12, 46, 22, 36, 28, 20, 52, 26, 60, 34, 56, 44, 50, 46, 58, 30, 76),
+		circle(44, 34, 3),
+	},
+	"b": {
+		poly(22, 88, 78, 88, 74, 78, 26, 78),
+		poly(50, 20, 62, 32, 66, 48, 58, 64, 64, 78, 36, 78, 42, 64, 34, 48, 38, 32),
+		circle(50, 15, 6),
+		poly(48, 40, 58, 30, 60, 33, 50, 43),
+	},
+	"q": {
+		poly(22, 88, 78, 88, 74, 76, 26, 76),
+		poly(26, 76, 74, 76, 84, 30, 68, 56, 64, 24, 56, 54, 50, 18, 44, 54, 36, 24, 32, 56, 16, 30),
+		circle(16, 28, 5), circle(36, 22, 5), circle(50, 16, 5), circle(64, 22, 5), circle(84, 28, 5),
+	},
+	"k": {
+		poly(22, 88, 78, 88, 74, 76, 26, 76),
+		poly(26, 76, 74, 76, 80, 46, 66, 38, 56, 46, 50, 36, 44, 46, 34, 38, 20, 46),
+		poly(46, 8, 54, 8, 54, 36, 46, 36),
+		poly(38, 16, 62, 16, 62, 23, 38, 23),
+	},
+}
+
+// fairyArt stands for pieces without art of their own, a disc on a base
+// marked with the letter of the piece.
+var fairyArt = []shape{
+	poly(22, 88, 78, 88, 74, 76, 26, 76),
+	circle(50, 44, 30),
+}
+
+// art returns the shapes of the piece and whether its letter goes on top.
+func art(sign string) ([]shape, bool) {
+	if shapes, ok := pieceArt[strings.ToLower(sign)]; ok {
+		return shapes, false
+	}
+
+	return fairyArt, true
+}
+
+// squareOrigin returns the top left corner of a board square in diagram
+// units, from the perspective of the layout.
+func (view layout) squareOrigin(row, col int) point {
+	if view.perspective == board.Black {
+		row, col = view.ranks-1-row, view.files-1-col
+	}
+
+	return point{float64(col * unit), float64(row * unit)}
+}
+
+// squareCenter parses a position like "e4" or "j10".
+func (view layout) squareCenter(position string) (point, bool) {
+	if len(position) < 2 {
+		return point{}, false
+	}
+	rank, err := strconv.Atoi(position[1:])
+	col := int(position[0]) - 'a'
+	if err != nil || col < 0 || col >= view.files || rank < 1 || rank > view.ranks {
+		return point{}, false
+	}
+
+	origin := view.squareOrigin(view.ranks-rank, col)
+	return point{origin.x + unit/2, origin.y + unit/2}, true
+}
+
+// width returns the width of the board in diagram units.
+func (view layout) width() int {
+	return view.files * unit
+}
+
+// arrowPolygon returns the outline of an arrow between two square centres.
+func arrowPolygon(from, to point) []point {
+	const (
+		shaft = 7
+		head  = 20
+		long  = 36
+	)
+
+	dx, dy := to.x-from.x, to.y-from.y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return nil
+	}
+
+	ux, uy := dx/length, dy/length
+	nx, ny := -uy, ux
+	at := func(along, across float64) point {
+		return point{from.x + ux*along + nx*across, from.y + uy*along + ny*across}
+	}
+
+	end := length - 10
+	neck := end - long
+	return []point{
+		at(0, shaft), at(neck, shaft), at(neck, head), at(end, 0),
+		at(neck, -head), at(neck, -shaft), at(0, -shaft),
+	}
+}
+
+func (options DiagramOptions) size() int {
+	if options.Size <= 0 {
+		return 480
+	}
+
+	return options.Size
+}
+
+// height returns the height of the picture in diagram units.
+func (options DiagramOptions) height(view layout) int {
+	if options.Caption == "" {
+		return view.ranks * unit
+	}
+
+	return view.ranks*unit + captionHeight
+}
+
+// pixelHeight returns the height of the picture in pixels, its width being
+// the size.
+func (options DiagramOptions) pixelHeight(view layout) int {
+	return options.size() * options.height(view) / view.width()
+}
+
+// ParseArrows reads arrows written as "e2e4" or "e2-e4", separated by
+// commas.
+func ParseArrows(value string) ([][2]string, error) {
+	var arrows [][2]string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ReplaceAll(strings.TrimSpace(field), "-", "")
+		if field == "" {
+			continue
+		}
+		if len(field) != 4 {
+			return nil, fmt.Errorf("bad arrow %q, expected e.g. e2e4", field)
+		}
+		arrows = append(arrows, [2]string{field[:2], field[2:]})
+	}
+
+	return arrows, nil
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func opacity(c color.RGBA) string {
+	return fmt.Sprintf("%.2f", float64(c.A)/255)
+}
@@ -0,0 +1,114 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// Frame is one picture of an animation.
+type Frame struct {
+	Board     *board.Board
+	Highlight []string
+	Caption   string
+}
+
+// GIF writes the frames as an animated GIF that loops forever, showing each
+// frame for the delay. The size, perspective and coordinates come from the
+// options; highlights and captions from the frames.
+func GIF(w io.Writer, frames []Frame, options DiagramOptions, delay time.Duration) error {
+	animation := &gif.GIF{}
+	palette := newGIFPalette()
+
+	captions := false
+	for _, frame := range frames {
+		captions = captions || frame.Caption != ""
+	}
+
+	for _, frame := range frames {
+		frameOptions := options
+		frameOptions.Highlight = frame.Highlight
+		frameOptions.Caption = frame.Caption
+		if captions && frame.Caption == "" {
+			// Every frame must have the same size, so keep an empty strip.
+			frameOptions.Caption = " "
+		}
+
+		animation.Image = append(animation.Image, palette.convert(Image(frame.Board, frameOptions)))
+		animation.Delay = append(animation.Delay, int(delay/(10*time.Millisecond)))
+	}
+
+	if len(animation.Delay) > 0 {
+		// Let the final position stay a little longer before looping.
+		animation.Delay[len(animation.Delay)-1] *= 3
+	}
+
+	return gif.EncodeAll(w, animation)
+}
+
+// gifPalette holds the diagram colours and the anti-aliasing blends between
+// every pair of them, with a cache of the nearest entry for each colour.
+type gifPalette struct {
+	colors  color.Palette
+	nearest map[color.RGBA]uint8
+}
+
+func newGIFPalette() *gifPalette {
+	keys := []color.RGBA{
+		lightSquare, darkSquare, whiteFill, blackFill, outline, captionFill, captionText,
+		mix(highlight, lightSquare), mix(highlight, darkSquare),
+		mix(arrowColor, lightSquare), mix(arrowColor, darkSquare),
+	}
+
+	const steps = 4
+	colors := color.Palette{}
+	for _, key := range keys {
+		colors = append(colors, key)
+	}
+	for i := range keys {
+		for j := i + 1; j < len(keys); j++ {
+			for step := 1; step <= steps; step++ {
+				t := float64(step) / (steps + 1)
+				colors = append(colors, lerp(keys[i], keys[j], t))
+			}
+		}
+	}
+
+	return &gifPalette{colors: colors, nearest: make(map[color.RGBA]uint8)}
+}
+
+func (p *gifPalette) convert(img *image.RGBA) *image.Paletted {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, p.colors)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			index, ok := p.nearest[c]
+			if !ok {
+				index = uint8(p.colors.Index(c))
+				p.nearest[c] = index
+			}
+			paletted.SetColorIndex(x, y, index)
+		}
+	}
+
+	return paletted
+}
+
+// mix composites a translucent colour over an opaque one.
+func mix(over, under color.RGBA) color.RGBA {
+	return lerp(under, color.RGBA{over.R, over.G, over.B, 0xFF}, float64(over.A)/255)
+}
+
+func lerp(a, b color.RGBA, t float64) color.RGBA {
+	channel := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+
+	return color.RGBA{channel(a.R, b.R), channel(a.G, b.G), channel(a.B, b.B), 0xFF}
+}
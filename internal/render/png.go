@@ -0,0 +1,332 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// supersampling is the number of canvas pixels per picture pixel along each
+// axis, averaged down for anti-aliasing.
+const supersampling = 3
+
+// PNG writes the board as a PNG picture.
+func PNG(w io.Writer, b *board.Board, options DiagramOptions) error {
+	return png.Encode(w, Image(b, options))
+}
+
+// Image rasterizes the same diagram as SVG with the standard library only.
+func Image(b *board.Board, options DiagramOptions) *image.RGBA {
+	size := options.size()
+	view := newLayout(b, options.Perspective)
+	c := newCanvas(size*supersampling, options.pixelHeight(view)*supersampling, view.width())
+
+	for row := 0; row < view.ranks; row++ {
+		for col := 0; col < view.files; col++ {
+			fill := lightSquare
+			if view.isDark(row, col) {
+				fill = darkSquare
+			}
+			c.fillRect(view.squareOrigin(row, col), unit, unit, fill)
+		}
+	}
+
+	for _, position := range options.Highlight {
+		if center, ok := view.squareCenter(position); ok {
+			c.fillRect(point{center.x - unit/2, center.y - unit/2}, unit, unit, highlight)
+		}
+	}
+
+	if options.Coordinates {
+		drawCoordinates(c, view)
+	}
+
+	for row, signs := range b.Grid() {
+		for col, sign := range signs {
+			if sign != "" {
+				drawPiece(c, sign, view.squareOrigin(row, col))
+			}
+		}
+	}
+
+	for _, arrow := range options.Arrows {
+		from, okFrom := view.squareCenter(arrow[0])
+		to, okTo := view.squareCenter(arrow[1])
+		if okFrom && okTo {
+			c.fillPolygon(arrowPolygon(from, to), arrowColor)
+		}
+	}
+
+	if options.Caption != "" {
+		drawCaption(c, options.Caption, view)
+	}
+
+	return c.downsample(size, options.pixelHeight(view))
+}
+
+// drawCaption centres the text in the strip below the board.
+func drawCaption(c *canvas, caption string, view layout) {
+	const pixel = 5
+
+	top := float64(view.ranks * unit)
+	c.fillRect(point{0, top}, float64(view.width()), captionHeight, captionFill)
+
+	width := float64(len([]rune(caption))*6-1) * pixel
+	origin := point{(float64(view.width()) - width) / 2, top + (captionHeight-7*pixel)/2}
+	c.text(caption, origin, pixel, captionText)
+}
+
+func drawPiece(c *canvas, sign string, origin point) {
+	fill, ink := whiteFill, outline
+	if sign == strings.ToUpper(sign) {
+		fill, ink = blackFill, whiteFill
+	}
+
+	shapes, lettered := art(sign)
+	for _, part := range shapes {
+		if part.radius > 0 {
+			center := point{origin.x + part.center.x, origin.y + part.center.y}
+			c.fillCircle(center, part.radius+strokeWidth/2.0, outline)
+			c.fillCircle(center, part.radius-strokeWidth/2.0, fill)
+			continue
+		}
+
+		var polygon []point
+		for _, p := range part.polygon {
+			polygon = append(polygon, point{origin.x + p.x, origin.y + p.y})
+		}
+		c.fillPolygon(polygon, fill)
+		c.strokePolygon(polygon, strokeWidth, outline)
+	}
+
+	if lettered {
+		const pixel = 6
+		c.text(strings.ToUpper(sign), point{origin.x + 50 - 2.5*pixel, origin.y + 44 - 3.5*pixel}, pixel, ink)
+	}
+}
+
+func drawCoordinates(c *canvas, view layout) {
+	const pixel = 3
+
+	rows, cols := view.order()
+	bottom, left := rows[len(rows)-1], cols[0]
+	for _, file := range cols {
+		origin := view.squareOrigin(bottom, file)
+		c.text(string(rune('a'+file)), point{origin.x + unit - 4 - 5*pixel, origin.y + unit - 4 - 7*pixel}, pixel, view.coordinateColor(bottom, file))
+	}
+
+	for _, rank := range rows {
+		origin := view.squareOrigin(rank, left)
+		c.text(strconv.Itoa(view.ranks-rank), point{origin.x + 4, origin.y + 4}, pixel, view.coordinateColor(rank, left))
+	}
+}
+
+// coordinateColor returns the colour of the other kind of square, so that
+// coordinates stand out.
+func (view layout) coordinateColor(row, col int) color.RGBA {
+	if view.isDark(row, col) {
+		return lightSquare
+	}
+
+	return darkSquare
+}
+
+// canvas is a supersampled RGBA picture addressed in diagram units.
+type canvas struct {
+	img   *image.RGBA
+	scale float64
+}
+
+// newCanvas makes a canvas of the size in pixels for a picture the given
+// number of diagram units wide.
+func newCanvas(width, height, units int) *canvas {
+	return &canvas{
+		img:   image.NewRGBA(image.Rect(0, 0, width, height)),
+		scale: float64(width) / float64(units),
+	}
+}
+
+func (c *canvas) fillRect(origin point, width, height float64, col color.RGBA) {
+	c.fillPolygon([]point{
+		origin,
+		{origin.x + width, origin.y},
+		{origin.x + width, origin.y + height},
+		{origin.x, origin.y + height},
+	}, col)
+}
+
+// fillPolygon fills with the even-odd rule, sampling pixel centres.
+func (c *canvas) fillPolygon(polygon []point, col color.RGBA) {
+	if len(polygon) < 3 {
+		return
+	}
+
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, p := range polygon {
+		minY = math.Min(minY, p.y*c.scale)
+		maxY = math.Max(maxY, p.y*c.scale)
+	}
+
+	bounds := c.img.Bounds()
+	for y := max(int(minY), bounds.Min.Y); y <= min(int(maxY), bounds.Max.Y-1); y++ {
+		sampleY := float64(y) + 0.5
+
+		var crossings []float64
+		for i := range polygon {
+			a, b := polygon[i], polygon[(i+1)%len(polygon)]
+			ay, by := a.y*c.scale, b.y*c.scale
+			if (ay <= sampleY) == (by <= sampleY) {
+				continue
+			}
+			t := (sampleY - ay) / (by - ay)
+			crossings = append(crossings, (a.x+(b.x-a.x)*t)*c.scale)
+		}
+
+		sort.Float64s(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			from := max(int(math.Ceil(crossings[i]-0.5)), bounds.Min.X)
+			to := min(int(math.Ceil(crossings[i+1]-0.5)), bounds.Max.X)
+			for x := from; x < to; x++ {
+				c.blend(x, y, col)
+			}
+		}
+	}
+}
+
+// strokePolygon draws the closed outline with round joins.
+func (c *canvas) strokePolygon(polygon []point, width float64, col color.RGBA) {
+	half := width / 2
+
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		if length == 0 {
+			continue
+		}
+
+		nx, ny := -(b.y-a.y)/length*half, (b.x-a.x)/length*half
+		c.fillPolygon([]point{
+			{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny},
+			{b.x - nx, b.y - ny}, {a.x - nx, a.y - ny},
+		}, col)
+		c.fillCircle(a, half, col)
+	}
+}
+
+func (c *canvas) fillCircle(center point, radius float64, col color.RGBA) {
+	cx, cy, r := center.x*c.scale, center.y*c.scale, radius*c.scale
+	bounds := c.img.Bounds()
+
+	for y := max(int(cy-r), bounds.Min.Y); y <= min(int(cy+r), bounds.Max.Y-1); y++ {
+		for x := max(int(cx-r), bounds.Min.X); x <= min(int(cx+r), bounds.Max.X-1); x++ {
+			dx, dy := float64(x)+0.5-cx, float64(y)+0.5-cy
+			if dx*dx+dy*dy <= r*r {
+				c.blend(x, y, col)
+			}
+		}
+	}
+}
+
+// text draws characters of the bitmap font with square pixels of the given
+// size in diagram units.
+func (c *canvas) text(text string, origin point, pixel float64, col color.RGBA) {
+	for i, r := range text {
+		glyph := font[r]
+		for row, bits := range glyph {
+			for bit := 0; bit < 5; bit++ {
+				if bits&(1<<(4-bit)) != 0 {
+					c.fillRect(point{origin.x + float64(i*6+bit)*pixel, origin.y + float64(row)*pixel}, pixel, pixel, col)
+				}
+			}
+		}
+	}
+}
+
+// blend draws a pixel with source-over alpha compositing.
+func (c *canvas) blend(x, y int, col color.RGBA) {
+	if col.A == 0xFF {
+		c.img.SetRGBA(x, y, col)
+		return
+	}
+
+	dst := c.img.RGBAAt(x, y)
+	alpha := uint32(col.A)
+	mix := func(s, d uint8) uint8 {
+		return uint8((uint32(s)*alpha + uint32(d)*(255-alpha)) / 255)
+	}
+
+	c.img.SetRGBA(x, y, color.RGBA{mix(col.R, dst.R), mix(col.G, dst.G), mix(col.B, dst.B), 0xFF})
+}
+
+// downsample averages blocks of the canvas into a picture of the given size.
+func (c *canvas) downsample(width, height int) *image.RGBA {
+	result := image.NewRGBA(image.Rect(0, 0, width, height))
+	factor := c.img.Bounds().Dx() / width
+	samples := uint32(factor * factor)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var r, g, b uint32
+			for sy := 0; sy < factor; sy++ {
+				for sx := 0; sx < factor; sx++ {
+					p := c.img.RGBAAt(x*factor+sx, y*factor+sy)
+					r, g, b = r+uint32(p.R), g+uint32(p.G), b+uint32(p.B)
+				}
+			}
+			result.SetRGBA(x, y, color.RGBA{uint8(r / samples), uint8(g / samples), uint8(b / samples), 0xFF})
+		}
+	}
+
+	return result
+}
+
+// font is a 5x7 bitmap font for the coordinates and captions, one byte per
+// row with the leftmost pixel in bit 4. Missing characters are blank.
+var font = map[rune][7]byte{
+	'a': {0x00, 0x00, 0x0E, 0x01, 0x0F, 0x11, 0x0F},
+	'b': {0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x1E},
+	'c': {0x00, 0x00, 0x0E, 0x10, 0x10, 0x11, 0x0E},
+	'd': {0x01, 0x01, 0x0D, 0x13, 0x11, 0x11, 0x0F},
+	'e': {0x00, 0x00, 0x0E, 0x11, 0x1F, 0x10, 0x0E},
+	'f': {0x06, 0x09, 0x08, 0x1C, 0x08, 0x08, 0x08},
+	'g': {0x00, 0x0F, 0x11, 0x11, 0x0F, 0x01, 0x0E},
+	'h': {0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x11},
+	'i': {0x04, 0x00, 0x0C, 0x04, 0x04, 0x04, 0x0E},
+	'j': {0x02, 0x00, 0x06, 0x02, 0x02, 0x12, 0x0C},
+	'1': {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2': {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3': {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4': {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5': {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6': {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8': {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9': {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	'0': {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'x': {0x00, 0x00, 0x11, 0x0A, 0x04, 0x0A, 0x11},
+	'K': {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'Q': {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R': {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'B': {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'N': {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O': {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'A': {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'C': {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'Z': {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+	'L': {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'G': {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'+': {0x00, 0x04, 0x04, 0x1F, 0x04, 0x04, 0x00},
+	'#': {0x0A, 0x0A, 0x1F, 0x0A, 0x1F, 0x0A, 0x0A},
+	'=': {0x00, 0x00, 0x1F, 0x00, 0x1F, 0x00, 0x00},
+	'-': {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'.': {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C},
+	'/': {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'@': {0x0E, 0x11, 0x17, 0x15, 0x17, 0x10, 0x0F},
+}
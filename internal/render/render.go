@@ -0,0 +1,194 @@
+// Package render draws a board as text. Renderers differ in how they draw
+// pieces and squares, the options decide which side is at the bottom,
+// whether coordinates are shown and which squares are highlighted.
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+type Options struct {
+	// Perspective is the side shown at the bottom, White when Undecided.
+	Perspective board.Team
+	Coordinates bool
+	// Highlight lists squares such as "e2" and "e4" to mark, e.g. the last
+	// move.
+	Highlight []string
+}
+
+type Renderer interface {
+	Render(b *board.Board, options Options) string
+}
+
+var renderers = map[string]Renderer{
+	"ascii":   ASCII{},
+	"unicode": Unicode{},
+	"ansi":    ANSI{},
+}
+
+// New returns the renderer with the given name.
+func New(name string) (Renderer, error) {
+	renderer, ok := renderers[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown renderer %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+
+	return renderer, nil
+}
+
+func Names() []string {
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// LastMove returns the squares of the last move of a game, for
+// Options.Highlight.
+func LastMove(moves []string) []string {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	fields := strings.Fields(moves[len(moves)-1])
+	if len(fields) == 1 {
+		// A drop such as "N@f3" only marks its square.
+		if _, square, ok := strings.Cut(fields[0], "@"); ok {
+			return []string{square}
+		}
+	}
+	if len(fields) < 2 {
+		return nil
+	}
+
+	return fields[:2]
+}
+
+// cell draws one square. Row 0 is the last rank.
+type cell func(sign string, row, col int, highlighted bool) string
+
+// grid lays the cells out from the perspective of the options. Bordered
+// grids separate the cells with "|" like utils.StringifyBoard.
+func grid(b *board.Board, options Options, draw cell, cellWidth int, bordered bool) string {
+	signs := b.Grid()
+	highlighted := make(map[string]bool)
+	for _, position := range options.Highlight {
+		highlighted[position] = true
+	}
+
+	view := newLayout(b, options.Perspective)
+	rows, cols := view.order()
+
+	// Ranks are right-aligned, as the 10th has two digits.
+	labelWidth := len(strconv.Itoa(view.ranks))
+
+	var buffer strings.Builder
+	files := func() {
+		if !options.Coordinates {
+			return
+		}
+		buffer.WriteString(strings.Repeat(" ", labelWidth+1))
+		if bordered {
+			buffer.WriteString(" ")
+		}
+		for _, col := range cols {
+			file := string(rune('a' + col))
+			padding := cellWidth - 1
+			if bordered {
+				padding++
+			}
+			buffer.WriteString(strings.Repeat(" ", padding/2) + file + strings.Repeat(" ", padding-padding/2))
+		}
+		buffer.WriteString("\n")
+	}
+
+	files()
+	for _, row := range rows {
+		rank := strconv.Itoa(view.ranks - row)
+		if options.Coordinates {
+			fmt.Fprintf(&buffer, "%*s ", labelWidth, rank)
+		}
+		if bordered {
+			buffer.WriteString("|")
+		}
+
+		for _, col := range cols {
+			position := string(rune('a'+col)) + rank
+			buffer.WriteString(draw(signs[row][col], row, col, highlighted[position]))
+			if bordered {
+				buffer.WriteString("|")
+			}
+		}
+
+		if options.Coordinates {
+			buffer.WriteString(" " + rank)
+		}
+		buffer.WriteString("\n")
+	}
+	files()
+
+	return buffer.String()
+}
+
+// layout is the board as drawn: its size and the side at the bottom.
+type layout struct {
+	files, ranks int
+	perspective  board.Team
+}
+
+func newLayout(b *board.Board, perspective board.Team) layout {
+	return layout{files: b.Files(), ranks: b.Ranks(), perspective: perspective}
+}
+
+// order returns the board rows and columns in drawing order.
+func (view layout) order() (rows, cols []int) {
+	for i := 0; i < view.ranks; i++ {
+		if view.perspective == board.Black {
+			rows = append(rows, view.ranks-1-i)
+		} else {
+			rows = append(rows, i)
+		}
+	}
+	for i := 0; i < view.files; i++ {
+		if view.perspective == board.Black {
+			cols = append(cols, view.files-1-i)
+		} else {
+			cols = append(cols, i)
+		}
+	}
+
+	return rows, cols
+}
+
+func captures(b *board.Board, piece func(sign string) string) string {
+	var buffer strings.Builder
+
+	for _, line := range []struct {
+		name  string
+		signs []string
+	}{
+		{"White", b.WhiteCaptures()},
+		{"Black", b.BlackCaptures()},
+	} {
+		buffer.WriteString(line.name + " captures: [")
+		for _, sign := range line.signs {
+			buffer.WriteString(piece(sign) + " ")
+		}
+		buffer.WriteString("]\n")
+	}
+
+	return buffer.String()
+}
+
+// isDark reports whether the square is dark, a1 being dark on every board.
+func (view layout) isDark(row, col int) bool {
+	return (view.ranks-row+col)%2 == 1
+}
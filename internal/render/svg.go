@@ -0,0 +1,123 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// SVG draws the board as a standalone SVG document.
+func SVG(b *board.Board, options DiagramOptions) string {
+	var svg strings.Builder
+	size := options.size()
+	view := newLayout(b, options.Perspective)
+
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		size, options.pixelHeight(view), view.width(), options.height(view))
+
+	for row := 0; row < view.ranks; row++ {
+		for col := 0; col < view.files; col++ {
+			fill := lightSquare
+			if view.isDark(row, col) {
+				fill = darkSquare
+			}
+			origin := view.squareOrigin(row, col)
+			fmt.Fprintf(&svg, `<rect x="%g" y="%g" width="%d" height="%d" fill="%s"/>`+"\n", origin.x, origin.y, unit, unit, hex(fill))
+		}
+	}
+
+	for _, position := range options.Highlight {
+		if center, ok := view.squareCenter(position); ok {
+			fmt.Fprintf(&svg, `<rect x="%g" y="%g" width="%d" height="%d" fill="%s" fill-opacity="%s"/>`+"\n",
+				center.x-unit/2, center.y-unit/2, unit, unit, hex(highlight), opacity(highlight))
+		}
+	}
+
+	if options.Coordinates {
+		writeSVGCoordinates(&svg, view)
+	}
+
+	for row, signs := range b.Grid() {
+		for col, sign := range signs {
+			if sign != "" {
+				writeSVGPiece(&svg, sign, view.squareOrigin(row, col))
+			}
+		}
+	}
+
+	for _, arrow := range options.Arrows {
+		from, okFrom := view.squareCenter(arrow[0])
+		to, okTo := view.squareCenter(arrow[1])
+		if okFrom && okTo {
+			fmt.Fprintf(&svg, `<polygon points="%s" fill="%s" fill-opacity="%s"/>`+"\n",
+				svgPoints(arrowPolygon(from, to), point{}), hex(arrowColor), opacity(arrowColor))
+		}
+	}
+
+	if options.Caption != "" {
+		fmt.Fprintf(&svg, `<rect x="0" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			view.ranks*unit, view.width(), captionHeight, hex(captionFill))
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" font-family="monospace" font-size="36" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			view.width()/2, view.ranks*unit+captionHeight/2+12, hex(captionText), html.EscapeString(options.Caption))
+	}
+
+	svg.WriteString("</svg>\n")
+	return svg.String()
+}
+
+func writeSVGPiece(svg *strings.Builder, sign string, origin point) {
+	fill := whiteFill
+	if sign == strings.ToUpper(sign) {
+		fill = blackFill
+	}
+
+	fmt.Fprintf(svg, `<g fill="%s" stroke="%s" stroke-width="%d" stroke-linejoin="round">`, hex(fill), hex(outline), strokeWidth)
+	shapes, lettered := art(sign)
+	for _, part := range shapes {
+		if part.radius > 0 {
+			fmt.Fprintf(svg, `<circle cx="%g" cy="%g" r="%g"/>`, origin.x+part.center.x, origin.y+part.center.y, part.radius)
+		} else {
+			fmt.Fprintf(svg, `<polygon points="%s"/>`, svgPoints(part.polygon, origin))
+		}
+	}
+	svg.WriteString("</g>\n")
+
+	if lettered {
+		ink := outline
+		if fill == blackFill {
+			ink = whiteFill
+		}
+		fmt.Fprintf(svg, `<text x="%g" y="%g" font-family="sans-serif" font-size="40" font-weight="bold" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			origin.x+50, origin.y+58, hex(ink), strings.ToUpper(sign))
+	}
+}
+
+// writeSVGCoordinates puts the files on the bottom squares and the ranks on
+// the left squares, in the colour of the other square colour.
+func writeSVGCoordinates(svg *strings.Builder, view layout) {
+	rows, cols := view.order()
+
+	bottom := rows[len(rows)-1]
+	for _, file := range cols {
+		origin := view.squareOrigin(bottom, file)
+		fmt.Fprintf(svg, `<text x="%g" y="%g" font-family="sans-serif" font-size="18" font-weight="bold" text-anchor="end" fill="%s">%c</text>`+"\n",
+			origin.x+unit-4, origin.y+unit-5, hex(view.coordinateColor(bottom, file)), 'a'+file)
+	}
+
+	for _, rank := range rows {
+		origin := view.squareOrigin(rank, cols[0])
+		fmt.Fprintf(svg, `<text x="%g" y="%g" font-family="sans-serif" font-size="18" font-weight="bold" fill="%s">%d</text>`+"\n",
+			origin.x+4, origin.y+19, hex(view.coordinateColor(rank, cols[0])), view.ranks-rank)
+	}
+}
+
+func svgPoints(points []point, origin point) string {
+	var parts []string
+	for _, p := range points {
+		parts = append(parts, fmt.Sprintf("%g,%g", origin.x+p.x, origin.y+p.y))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,90 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// ASCII draws pieces as FEN letters, uppercase for White, for terminals
+// without Unicode chess glyphs. Highlighted squares are bracketed.
+type ASCII struct{}
+
+func (ASCII) Render(b *board.Board, options Options) string {
+	draw := func(sign string, row, col int, highlighted bool) string {
+		piece := "."
+		if sign != "" {
+			piece = board.FENLetter(sign)
+		}
+		return bracket(piece, highlighted)
+	}
+
+	return grid(b, options, draw, 3, true) + captures(b, board.FENLetter)
+}
+
+// Unicode draws pieces with the Unicode chess glyphs, outlined for White
+// and filled for Black.
+type Unicode struct{}
+
+func (Unicode) Render(b *board.Board, options Options) string {
+	draw := func(sign string, row, col int, highlighted bool) string {
+		piece := "_"
+		if sign != "" {
+			piece = board.Symbol(sign)
+		}
+		return bracket(piece, highlighted)
+	}
+
+	return grid(b, options, draw, 3, true) + captures(b, board.Symbol)
+}
+
+func bracket(piece string, highlighted bool) string {
+	if highlighted {
+		return "[" + piece + "]"
+	}
+
+	return " " + piece + " "
+}
+
+// 256-colour palette indexes used by ANSI.
+const (
+	colorLight      = 180
+	colorDark       = 137
+	colorLightMark  = 186
+	colorDarkMark   = 143
+	colorWhitePiece = 231
+	colorBlackPiece = 16
+)
+
+// ANSI draws the squares with coloured backgrounds and both sides with the
+// filled glyphs in white and black.
+type ANSI struct{}
+
+func (ANSI) Render(b *board.Board, options Options) string {
+	view := newLayout(b, options.Perspective)
+	draw := func(sign string, row, col int, highlighted bool) string {
+		background := colorLight
+		switch {
+		case view.isDark(row, col) && highlighted:
+			background = colorDarkMark
+		case view.isDark(row, col):
+			background = colorDark
+		case highlighted:
+			background = colorLightMark
+		}
+
+		piece := " "
+		foreground := colorBlackPiece
+		if sign != "" {
+			piece = board.Symbol(strings.ToUpper(sign))
+			if sign != strings.ToUpper(sign) {
+				foreground = colorWhitePiece
+			}
+		}
+
+		return fmt.Sprintf("\x1b[48;5;%d;1;38;5;%dm %s \x1b[0m", background, foreground, piece)
+	}
+
+	return grid(b, options, draw, 3, false) + captures(b, board.Symbol)
+}
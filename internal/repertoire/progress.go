@@ -0,0 +1,110 @@
+package repertoire
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/leitner"
+)
+
+// schedule drills a line again a day after it is first played through and
+// ever later after that; a wrong move makes it due at once.
+var schedule = leitner.Schedule{
+	0,
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	30 * 24 * time.Hour,
+}
+
+// Stats is the record of a line.
+type Stats struct {
+	leitner.Card
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// Progress holds the stats of the lines drilled by line ID.
+type Progress struct {
+	Lines map[string]*Stats `json:"lines"`
+}
+
+func NewProgress() *Progress {
+	return &Progress{Lines: make(map[string]*Stats)}
+}
+
+// Due reports whether the line is new or due for review.
+func (progress *Progress) Due(line Line, now time.Time) bool {
+	stats, ok := progress.Lines[line.ID]
+	return !ok || stats.IsDue(now)
+}
+
+// Pass moves the line to the next box of the schedule.
+func (progress *Progress) Pass(line Line, now time.Time) {
+	stats := progress.stats(line)
+	stats.Passed++
+	stats.Card = schedule.Pass(stats.Card, now)
+}
+
+// Fail sends the line back to the first box, due at once.
+func (progress *Progress) Fail(line Line, now time.Time) {
+	stats := progress.stats(line)
+	stats.Failed++
+	stats.Card = schedule.Fail(now)
+}
+
+func (progress *Progress) stats(line Line) *Stats {
+	stats, ok := progress.Lines[line.ID]
+	if !ok {
+		stats = &Stats{}
+		progress.Lines[line.ID] = stats
+	}
+
+	return stats
+}
+
+// DefaultDir is ~/.chess_on_golang/repertoire.
+func DefaultDir() string {
+	return datadir.Path("repertoire")
+}
+
+// LoadProgress reads the progress saved at the path, empty when there is
+// none.
+func LoadProgress(path string) (*Progress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewProgress(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	progress := NewProgress()
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+	if progress.Lines == nil {
+		progress.Lines = make(map[string]*Stats)
+	}
+
+	return progress, nil
+}
+
+// Save writes the progress to the path.
+func (progress *Progress) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return datadir.WriteFile(path, data)
+}
@@ -0,0 +1,189 @@
+// Package repertoire drills an opening repertoire read from PGN: the main
+// lines and variations of the games form a tree of the moves a player
+// answers with and the replies they expect. The computer plays the replies
+// and the player has to find the repertoire moves, each line being
+// reviewed again after a growing interval.
+package repertoire
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/pgn"
+)
+
+// Line is a path through the repertoire from the start to the end of a
+// variation. ID writes it in SAN with move numbers.
+type Line struct {
+	ID       string
+	Commands []string
+}
+
+// Repertoire is the tree of moves of a side.
+type Repertoire struct {
+	// Side is the player's side, the other side's moves being the replies
+	// the computer chooses from.
+	Side  board.Team
+	Lines []Line
+	start *board.Board
+	team  board.Team
+	// moves holds the moves of the tree by position key, so that lines that
+	// transpose share them.
+	moves map[string][]string
+}
+
+// Build reads the repertoire of the side from the main lines and the
+// variations of the games, which must all start from the same position.
+func Build(games []pgn.Game, side board.Team) (*Repertoire, error) {
+	if len(games) == 0 {
+		return nil, errors.New("no games")
+	}
+
+	repertoire := &Repertoire{Side: side, moves: make(map[string][]string)}
+	for i, game := range games {
+		start, team, err := game.Start()
+		if err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+		if repertoire.start == nil {
+			repertoire.start, repertoire.team = start, team
+		} else if start.PositionKey(team) != repertoire.start.PositionKey(repertoire.team) {
+			return nil, fmt.Errorf("game %d starts from another position", i+1)
+		}
+
+		if err := repertoire.walk(start.Clone(), team, game.Moves, line{}); err != nil {
+			return nil, fmt.Errorf("game %d: %w", i+1, err)
+		}
+	}
+
+	repertoire.prune()
+	if len(repertoire.Lines) == 0 {
+		return nil, errors.New("the games have no moves")
+	}
+
+	return repertoire, nil
+}
+
+// line is a path being walked, with the SAN of its moves.
+type line struct {
+	commands []string
+	sans     []string
+}
+
+func (l line) extend(command, san string) line {
+	return line{
+		commands: append(append([]string(nil), l.commands...), command),
+		sans:     append(append([]string(nil), l.sans...), san),
+	}
+}
+
+// walk adds the moves and their variations, each variation standing for
+// the move it follows in the text.
+func (repertoire *Repertoire) walk(b *board.Board, team board.Team, moves []pgn.Move, path line) error {
+	for _, move := range moves {
+		for _, variation := range move.Variations {
+			if err := repertoire.walk(b.Clone(), team, variation, path); err != nil {
+				return err
+			}
+		}
+
+		command, err := b.ParseSAN(move.SAN, team)
+		if err != nil {
+			return fmt.Errorf("%s after %s: %w", move.SAN, strings.Join(path.sans, " "), err)
+		}
+		san, _ := b.SAN(command, team)
+
+		repertoire.add(b.PositionKey(team), command)
+		path = path.extend(command, san)
+		b.Execute(command, team)
+		team = team.Opponent()
+	}
+
+	if len(path.commands) > 0 {
+		repertoire.Lines = append(repertoire.Lines, Line{ID: repertoire.number(path.sans), Commands: path.commands})
+	}
+	return nil
+}
+
+func (repertoire *Repertoire) add(key, command string) {
+	for _, known := range repertoire.moves[key] {
+		if known == command {
+			return
+		}
+	}
+
+	repertoire.moves[key] = append(repertoire.moves[key], command)
+}
+
+// prune drops the lines repeated or continued by another line.
+func (repertoire *Repertoire) prune() {
+	var lines []Line
+	for i, candidate := range repertoire.Lines {
+		kept := true
+		for j, other := range repertoire.Lines {
+			longer := len(other.Commands) > len(candidate.Commands)
+			if i != j && hasPrefix(other.Commands, candidate.Commands) && (longer || j < i) {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			lines = append(lines, candidate)
+		}
+	}
+
+	repertoire.Lines = lines
+}
+
+// number writes the moves of a line from the start with move numbers.
+func (repertoire *Repertoire) number(sans []string) string {
+	fields := strings.Fields(repertoire.start.FEN(repertoire.team))
+	number := 1
+	fmt.Sscan(fields[len(fields)-1], &number)
+
+	var tokens []string
+	team := repertoire.team
+	for i, san := range sans {
+		switch {
+		case team == board.White:
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		case i == 0:
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, san)
+
+		if team == board.Black {
+			number++
+		}
+		team = team.Opponent()
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+// Start returns the position the repertoire starts from and the side to
+// move.
+func (repertoire *Repertoire) Start() (*board.Board, board.Team) {
+	return repertoire.start.Clone(), repertoire.team
+}
+
+// Moves returns the moves of the repertoire in the position with the team
+// to move, in the form accepted by Board.Execute.
+func (repertoire *Repertoire) Moves(b *board.Board, team board.Team) []string {
+	return repertoire.moves[b.PositionKey(team)]
+}
+
+func hasPrefix(commands, prefix []string) bool {
+	if len(prefix) > len(commands) {
+		return false
+	}
+	for i := range prefix {
+		if commands[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
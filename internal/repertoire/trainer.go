@@ -0,0 +1,198 @@
+package repertoire
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/uci"
+)
+
+// Trainer drills the lines due with a player typing moves, in SAN, as
+// commands such as "g1 f3" or in UCI. "quit" ends the session.
+type Trainer struct {
+	Repertoire *Repertoire
+	Progress   *Progress
+	reader     *bufio.Reader
+	out        io.Writer
+	rng        *rand.Rand
+}
+
+func NewTrainer(repertoire *Repertoire, progress *Progress, in io.Reader, out io.Writer) *Trainer {
+	return &Trainer{
+		Repertoire: repertoire,
+		Progress:   progress,
+		reader:     bufio.NewReader(in),
+		out:        out,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Run drills up to count lines, all those due when count is zero, until
+// none is due or the player quits. onDrill is called after each one, e.g.
+// to save the progress.
+func (trainer *Trainer) Run(count int, onDrill func(*Progress) error) error {
+	for drilled := 0; count == 0 || drilled < count; drilled++ {
+		due := trainer.due(time.Now())
+		if len(due) == 0 {
+			fmt.Fprintln(trainer.out, "No line is due, the next review is on", trainer.nextReview().Format("2006-01-02 15:04")+".")
+			return nil
+		}
+
+		if quit := trainer.Drill(due); quit {
+			return nil
+		}
+		if onDrill != nil {
+			if err := onDrill(trainer.Progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (trainer *Trainer) due(now time.Time) []Line {
+	var due []Line
+	for _, line := range trainer.Repertoire.Lines {
+		if trainer.Progress.Due(line, now) {
+			due = append(due, line)
+		}
+	}
+
+	return due
+}
+
+func (trainer *Trainer) nextReview() time.Time {
+	var next time.Time
+	for _, stats := range trainer.Progress.Lines {
+		if next.IsZero() || stats.Due.Before(next) {
+			next = stats.Due
+		}
+	}
+
+	return next
+}
+
+// Drill plays from the start until the end of the repertoire or a move
+// outside it. The computer picks its replies at random among those leading
+// to the lines given. It reports whether the player quit.
+func (trainer *Trainer) Drill(lines []Line) bool {
+	repertoire := trainer.Repertoire
+	b, team := repertoire.Start()
+	var path []string
+
+	fmt.Fprintf(trainer.out, "New drill, you play %s.\n", repertoire.Side)
+	for {
+		moves := repertoire.Moves(b, team)
+		if len(moves) == 0 {
+			for _, line := range repertoire.Lines {
+				if len(line.Commands) == len(path) && hasPrefix(line.Commands, path) {
+					trainer.Progress.Pass(line, time.Now())
+					fmt.Fprintf(trainer.out, "Line complete: %s\n\n", line.ID)
+				}
+			}
+			return false
+		}
+
+		if team != repertoire.Side {
+			reply := trainer.choose(moves, path, lines)
+			san, _ := b.SAN(reply, team)
+			fmt.Fprintf(trainer.out, "%s plays %s.\n", team, san)
+			b.Execute(reply, team)
+			path = append(path, reply)
+			team = team.Opponent()
+			continue
+		}
+
+		fmt.Fprintln(trainer.out, b.String())
+		command, quit := trainer.readMove(b, team)
+		if quit {
+			return true
+		}
+		if !contains(moves, command) {
+			trainer.fail(b, team, moves, path)
+			return false
+		}
+
+		b.Execute(command, team)
+		path = append(path, command)
+		team = team.Opponent()
+	}
+}
+
+// choose picks a reply at random, among those that stay on the lines when
+// there are some.
+func (trainer *Trainer) choose(moves, path []string, lines []Line) string {
+	var wanted []string
+	for _, move := range moves {
+		next := append(append([]string(nil), path...), move)
+		for _, line := range lines {
+			if hasPrefix(line.Commands, next) {
+				wanted = append(wanted, move)
+				break
+			}
+		}
+	}
+	if len(wanted) == 0 {
+		wanted = moves
+	}
+
+	return wanted[trainer.rng.Intn(len(wanted))]
+}
+
+// fail shows the repertoire moves and sends the lines through the
+// position back for review.
+func (trainer *Trainer) fail(b *board.Board, team board.Team, moves, path []string) {
+	var sans []string
+	for _, move := range moves {
+		san, _ := b.SAN(move, team)
+		sans = append(sans, san)
+	}
+	fmt.Fprintf(trainer.out, "Wrong, the repertoire plays %s.\n\n", strings.Join(sans, " or "))
+
+	for _, line := range trainer.Repertoire.Lines {
+		if hasPrefix(line.Commands, path) {
+			trainer.Progress.Fail(line, time.Now())
+		}
+	}
+}
+
+// readMove asks for a move until a legal one is typed.
+func (trainer *Trainer) readMove(b *board.Board, team board.Team) (string, bool) {
+	for {
+		fmt.Fprintf(trainer.out, "%s> ", team)
+		input, err := trainer.reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err == io.EOF && input == "" {
+			fmt.Fprintln(trainer.out)
+			return "", true
+		}
+
+		switch {
+		case input == "":
+			continue
+		case strings.EqualFold(input, "quit"):
+			return "", true
+		}
+
+		if command, err := uci.ParseMove(b, team, input); err == nil {
+			return command, false
+		}
+		fmt.Fprintln(trainer.out, "Illegal move, try again or type quit.")
+	}
+}
+
+func contains(moves []string, move string) bool {
+	for _, candidate := range moves {
+		if candidate == move {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,424 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/websocket"
+)
+
+const (
+	roleSpectator  = "spectator"
+	chatHistory    = 100
+	clientQueue    = 64
+	clockInterval  = time.Second
+	maxChatMessage = 500
+)
+
+// hub fans the events of one game out to its players and spectators over
+// websocket connections.
+type hub struct {
+	mu      sync.Mutex
+	clients map[*client]bool
+	tokens  map[board.Team]string
+	chat    []chatMessage
+}
+
+type client struct {
+	conn *websocket.Conn
+	role string
+	name string
+	send chan []byte
+}
+
+type chatMessage struct {
+	From string `json:"from"`
+	Role string `json:"role"`
+	Text string `json:"text"`
+}
+
+// event is a message pushed to the clients. Type is one of "welcome",
+// "move", "check", "end", "state", "clock", "chat" and "error".
+type event struct {
+	Type  string        `json:"type"`
+	Role  string        `json:"role,omitempty"`
+	Token string        `json:"token,omitempty"`
+	Move  string        `json:"move,omitempty"`
+	Team  string        `json:"team,omitempty"`
+	State *gameState    `json:"state,omitempty"`
+	Clock *clockState   `json:"clock,omitempty"`
+	Chat  []chatMessage `json:"chat,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+// command is a message sent by a client. Type is one of "move", "chat",
+// "resign", "offerDraw", "acceptDraw", "declineDraw" and "claimDraw".
+type command struct {
+	Type string `json:"type"`
+	Move string `json:"move"`
+	Text string `json:"text"`
+}
+
+func newHub() *hub {
+	return &hub{
+		clients: make(map[*client]bool),
+		tokens:  make(map[board.Team]string),
+	}
+}
+
+// liveGame upgrades GET /games/{id}/ws?role=white|black|spectator&name=...
+// to a websocket. The first player to take a seat receives a token in the
+// welcome event, reconnecting with ?token=... gives the seat back and the
+// full state is sent again. The seats of a rated game are only given to
+// the players it was created for.
+func (server *Server) liveGame(w http.ResponseWriter, r *http.Request) {
+	e, ok := server.store.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("game not found"))
+		return
+	}
+
+	query := r.URL.Query()
+	role := query.Get("role")
+	if role == "" {
+		role = roleSpectator
+	}
+
+	var token, player string
+	if role != roleSpectator {
+		team, err := board.ParseTeam(role)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		role = team.String()
+
+		player = e.player(team)
+		if player != "" && !strings.EqualFold(query.Get("name"), player) {
+			writeError(w, http.StatusForbidden, errors.New("the "+role+" seat is kept for "+player))
+			return
+		}
+		if token, ok = e.hub.claimSeat(team, query.Get("token")); !ok {
+			writeError(w, http.StatusForbidden, errors.New("seat is taken"))
+			return
+		}
+	}
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	c := &client{
+		conn: conn,
+		role: role,
+		name: query.Get("name"),
+		send: make(chan []byte, clientQueue),
+	}
+	if player != "" {
+		c.name = player
+	}
+	if c.name == "" {
+		c.name = role
+	}
+
+	e.mu.Lock()
+	state := newGameState(e.id, e.game)
+	e.hub.register(c, event{Type: "welcome", Role: role, Token: token, State: &state})
+	e.mu.Unlock()
+
+	go c.writeLoop()
+	e.readLoop(c)
+}
+
+// claimSeat hands out a new token for a free seat, or accepts the token of
+// the player that already holds it.
+func (h *hub) claimSeat(team board.Team, token string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current, taken := h.tokens[team]
+	if taken {
+		return current, token == current
+	}
+
+	token = newToken()
+	h.tokens[team] = token
+	return token, true
+}
+
+// holds reports whether the token is the one of the seat.
+func (h *hub) holds(team board.Team, token string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current, taken := h.tokens[team]
+	return taken && subtle.ConstantTimeCompare([]byte(token), []byte(current)) == 1
+}
+
+// register adds the client and queues the welcome event. A player that
+// reconnects replaces its previous connection.
+func (h *hub) register(c *client, welcome event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c.role != roleSpectator {
+		for other := range h.clients {
+			if other.role == c.role {
+				h.drop(other)
+			}
+		}
+	}
+
+	welcome.Chat = append([]chatMessage(nil), h.chat...)
+	h.clients[c] = true
+	h.queue(c, encode(welcome))
+}
+
+func (h *hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c] {
+		h.drop(c)
+	}
+}
+
+func (h *hub) broadcast(events ...event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ev := range events {
+		message := encode(ev)
+		for c := range h.clients {
+			h.queue(c, message)
+		}
+	}
+}
+
+func (h *hub) say(message chatMessage) {
+	h.mu.Lock()
+	h.chat = append(h.chat, message)
+	if len(h.chat) > chatHistory {
+		h.chat = h.chat[len(h.chat)-chatHistory:]
+	}
+	h.mu.Unlock()
+
+	h.broadcast(event{Type: "chat", Chat: []chatMessage{message}})
+}
+
+func (h *hub) sendTo(c *client, ev event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c] {
+		h.queue(c, encode(ev))
+	}
+}
+
+// queue must be called with the hub lock held. Clients that cannot keep up
+// are disconnected instead of blocking the game.
+func (h *hub) queue(c *client, message []byte) {
+	select {
+	case c.send <- message:
+	default:
+		h.drop(c)
+	}
+}
+
+func (h *hub) drop(c *client) {
+	delete(h.clients, c)
+	close(c.send)
+}
+
+func (c *client) writeLoop() {
+	for message := range c.send {
+		if err := c.conn.WriteMessage(message); err != nil {
+			break
+		}
+	}
+
+	c.conn.Close()
+}
+
+func (e *entry) readLoop(c *client) {
+	defer e.hub.unregister(c)
+
+	for {
+		message, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd command
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			e.hub.sendTo(c, event{Type: "error", Error: err.Error()})
+			continue
+		}
+
+		if err := e.handle(c, cmd); err != nil {
+			e.hub.sendTo(c, event{Type: "error", Error: err.Error()})
+		}
+	}
+}
+
+func (e *entry) handle(c *client, cmd command) error {
+	if cmd.Type == "chat" {
+		if cmd.Text == "" || len(cmd.Text) > maxChatMessage {
+			return errors.New("chat message must have 1 to 500 characters")
+		}
+		e.hub.say(chatMessage{From: c.name, Role: c.role, Text: cmd.Text})
+		return nil
+	}
+
+	if c.role == roleSpectator {
+		return errors.New("spectators can only chat")
+	}
+	team, _ := board.ParseTeam(c.role)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch cmd.Type {
+	case "move":
+		e.checkTime()
+		if e.game.Turn() != team {
+			return errors.New("not your turn")
+		}
+		if err := e.game.Play(cmd.Move); err != nil {
+			return err
+		}
+		e.announceMove(cmd.Move)
+	case "resign":
+		if err := e.game.Resign(team); err != nil {
+			return err
+		}
+		e.announce()
+	case "offerDraw":
+		if err := e.game.OfferDraw(team); err != nil {
+			return err
+		}
+		e.announce()
+	case "acceptDraw":
+		if err := e.game.AcceptDraw(team); err != nil {
+			return err
+		}
+		e.announce()
+	case "declineDraw":
+		if err := e.game.DeclineDraw(team); err != nil {
+			return err
+		}
+		e.announce()
+	case "claimDraw":
+		if err := e.game.ClaimDraw(team); err != nil {
+			return err
+		}
+		e.announce()
+	default:
+		return errors.New("unknown command " + cmd.Type)
+	}
+
+	return nil
+}
+
+// announceMove pushes a played move followed by check and end events. It must
+// be called with the entry lock held.
+func (e *entry) announceMove(move string) {
+	e.rate()
+	state := newGameState(e.id, e.game)
+	events := []event{{Type: "move", Move: move, State: &state}}
+
+	if state.Check {
+		events = append(events, event{Type: "check", Team: state.Turn})
+	}
+	if state.Status == statusFinished {
+		events = append(events, event{Type: "end", State: &state})
+	}
+
+	e.hub.broadcast(events...)
+}
+
+// announce pushes the state after any other change of the game. It must be
+// called with the entry lock held.
+func (e *entry) announce() {
+	e.rate()
+	state := newGameState(e.id, e.game)
+	events := []event{{Type: "state", State: &state}}
+
+	if state.Status == statusFinished {
+		events = append(events, event{Type: "end", State: &state})
+	}
+
+	e.hub.broadcast(events...)
+}
+
+// startClock starts watchClock for a game with a clock that is not over,
+// unless it already runs. A finished game reopened by an undo needs it
+// again. It must be called with the entry lock held, or before the entry is
+// shared.
+func (e *entry) startClock() {
+	if _, over := e.game.Result(); e.game.Clock() == nil || over || e.watching {
+		return
+	}
+
+	e.watching = true
+	go e.watchClock()
+}
+
+// checkTime ends the game when a flag has fallen and announces it, so that a
+// move arriving before watchClock notices the flag still ends and rates the
+// game. It must be called with the entry lock held.
+func (e *entry) checkTime() bool {
+	if !e.game.CheckTime() {
+		return false
+	}
+
+	e.announce()
+	return true
+}
+
+// watchClock pushes clock updates while the game runs and ends it when a
+// flag falls.
+func (e *entry) watchClock() {
+	ticker := time.NewTicker(clockInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		e.mu.Lock()
+		_, over := e.game.Result()
+		if !over {
+			if e.checkTime() {
+				over = true
+			} else if e.game.Clock().Running() != board.Undecided {
+				e.hub.broadcast(event{Type: "clock", Clock: newClockState(e.game.Clock())})
+			}
+		}
+		if over {
+			e.watching = false
+		}
+		e.mu.Unlock()
+
+		if over {
+			return
+		}
+	}
+}
+
+func encode(ev event) []byte {
+	message, _ := json.Marshal(ev)
+	return message
+}
+
+func newToken() string {
+	buffer := make([]byte, 16)
+	rand.Read(buffer)
+	return hex.EncodeToString(buffer)
+}
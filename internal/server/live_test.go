@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/websocket"
+)
+
+// newLiveGame serves a store holding one game created from the body and
+// returns the server and the websocket URL of the game without query.
+func newLiveGame(t *testing.T, body string) (*httptest.Server, string) {
+	t.Helper()
+
+	ts := httptest.NewServer(New(NewStore()))
+	t.Cleanup(ts.Close)
+
+	response, err := http.Post(ts.URL+"/games", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	var state gameState
+	if err := json.NewDecoder(response.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+
+	return ts, "ws" + strings.TrimPrefix(ts.URL, "http") + "/games/" + state.ID + "/ws"
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+
+	conn, err := websocket.Dial(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, cmd command) {
+	t.Helper()
+
+	message, _ := json.Marshal(cmd)
+	if err := conn.WriteMessage(message); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expect reads events until one of the type arrives.
+func expect(t *testing.T, conn *websocket.Conn, eventType string) event {
+	t.Helper()
+
+	events := make(chan event, 1)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			message, err := conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			var ev event
+			if err := json.Unmarshal(message, &ev); err != nil {
+				errs <- err
+				return
+			}
+			if ev.Type == eventType {
+				events <- ev
+				return
+			}
+		}
+	}()
+
+	select {
+	case ev := <-events:
+		return ev
+	case err := <-errs:
+		t.Fatalf("waiting for a %s event: %v", eventType, err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s event", eventType)
+	}
+
+	return event{}
+}
+
+func TestLiveMoves(t *testing.T) {
+	_, url := newLiveGame(t, "{}")
+	white := dial(t, url+"?role=white")
+	expect(t, white, "welcome")
+	black := dial(t, url+"?role=black")
+	expect(t, black, "welcome")
+
+	send(t, white, command{Type: "move", Move: "e2 e4"})
+	for _, conn := range []*websocket.Conn{white, black} {
+		ev := expect(t, conn, "move")
+		if ev.Move != "e2 e4" || ev.State == nil || ev.State.Turn != "black" {
+			t.Fatalf("move event = %+v, want e2 e4 with black to move", ev)
+		}
+	}
+
+	send(t, white, command{Type: "move", Move: "d2 d4"})
+	if ev := expect(t, white, "error"); ev.Error != "not your turn" {
+		t.Errorf("error = %q, want not your turn", ev.Error)
+	}
+}
+
+func TestLiveSpectatorCannotMove(t *testing.T) {
+	_, url := newLiveGame(t, "{}")
+	spectator := dial(t, url+"?role=spectator")
+	if ev := expect(t, spectator, "welcome"); ev.Role != roleSpectator || ev.Token != "" {
+		t.Fatalf("welcome = %+v, want a spectator without token", ev)
+	}
+
+	send(t, spectator, command{Type: "move", Move: "e2 e4"})
+	if ev := expect(t, spectator, "error"); ev.Error != "spectators can only chat" {
+		t.Errorf("error = %q, want spectators can only chat", ev.Error)
+	}
+}
+
+func TestLiveChat(t *testing.T) {
+	_, url := newLiveGame(t, "{}")
+	white := dial(t, url+"?role=white&name=ann")
+	expect(t, white, "welcome")
+	spectator := dial(t, url+"?name=kibitzer")
+	expect(t, spectator, "welcome")
+
+	send(t, spectator, command{Type: "chat", Text: "good luck"})
+	ev := expect(t, white, "chat")
+	if len(ev.Chat) != 1 || ev.Chat[0] != (chatMessage{From: "kibitzer", Role: roleSpectator, Text: "good luck"}) {
+		t.Fatalf("chat = %+v", ev.Chat)
+	}
+
+	// Late joiners get the chat so far with the welcome.
+	late := dial(t, url)
+	if ev := expect(t, late, "welcome"); len(ev.Chat) != 1 || ev.Chat[0].Text != "good luck" {
+		t.Errorf("welcome chat = %+v, want the earlier message", ev.Chat)
+	}
+}
+
+func TestLiveReconnect(t *testing.T) {
+	_, url := newLiveGame(t, "{}")
+	white := dial(t, url+"?role=white")
+	token := expect(t, white, "welcome").Token
+	if token == "" {
+		t.Fatal("welcome has no token")
+	}
+	send(t, white, command{Type: "move", Move: "e2 e4"})
+	expect(t, white, "move")
+	white.Close()
+
+	if _, err := websocket.Dial(url + "?role=white"); !errors.Is(err, websocket.ErrBadHandshake) {
+		t.Fatalf("taking a held seat without token: err = %v, want a refused handshake", err)
+	}
+
+	again := dial(t, url+"?role=white&token="+token)
+	ev := expect(t, again, "welcome")
+	if ev.State == nil || len(ev.State.Moves) != 1 || ev.State.Turn != "black" {
+		t.Fatalf("welcome state = %+v, want the game after e2 e4", ev.State)
+	}
+}
+
+func TestLiveMoveAfterFlag(t *testing.T) {
+	_, url := newLiveGame(t, `{"clock": {"base": 0.1}}`)
+	white := dial(t, url+"?role=white")
+	expect(t, white, "welcome")
+	black := dial(t, url+"?role=black")
+	expect(t, black, "welcome")
+
+	send(t, white, command{Type: "move", Move: "e2 e4"})
+	expect(t, black, "move")
+
+	// The move arrives long before watchClock looks at the clock again.
+	time.Sleep(200 * time.Millisecond)
+	send(t, black, command{Type: "move", Move: "e7 e5"})
+
+	ev := expect(t, white, "end")
+	if ev.State == nil || ev.State.Winner != "white" || ev.State.Termination != game.TerminationTimeForfeit {
+		t.Fatalf("end event = %+v, want white winning on time", ev.State)
+	}
+	if ev := expect(t, black, "error"); ev.Error == "" {
+		t.Error("the late move was not refused")
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/websocket"
+)
+
+// newRatedServer serves a store with ratings.
+func newRatedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	ratings, err := storage.NewRatingStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore()
+	store.SetRatings(ratings)
+
+	ts := httptest.NewServer(New(store))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func post(t *testing.T, url, body string) (int, gameState) {
+	t.Helper()
+
+	response, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	var state gameState
+	json.NewDecoder(response.Body).Decode(&state)
+	return response.StatusCode, state
+}
+
+func TestRatedGameStart(t *testing.T) {
+	ts := newRatedServer(t)
+
+	for _, body := range []string{
+		`{"white": "ann", "black": "bob", "fen": "4k3/8/8/8/8/8/8/4K2R w K - 0 1"}`,
+		`{"white": "ann", "black": "bob", "chess960": 0}`,
+		`{"white": "ann", "black": "bob", "variant": "Atomic"}`,
+		`{"white": "ann", "black": "Ann"}`,
+		`{"white": "a.b", "black": "bob"}`,
+	} {
+		if status, _ := post(t, ts.URL+"/games", body); status != http.StatusBadRequest {
+			t.Errorf("POST /games %s: status %d, want %d", body, status, http.StatusBadRequest)
+		}
+	}
+
+	// Unrated games may still start anywhere.
+	if status, _ := post(t, ts.URL+"/games", `{"variant": "Atomic"}`); status != http.StatusCreated {
+		t.Errorf("unrated Atomic game: status %d, want %d", status, http.StatusCreated)
+	}
+}
+
+func TestRatedGameNeedsSeatTokens(t *testing.T) {
+	ts := newRatedServer(t)
+	status, state := post(t, ts.URL+"/games", `{"white": "Ann", "black": "bob"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("POST /games: status %d", status)
+	}
+	game := ts.URL + "/games/" + state.ID
+	url := "ws" + strings.TrimPrefix(game, "http") + "/ws"
+
+	if _, err := websocket.Dial(url + "?role=white&name=mallory"); !errors.Is(err, websocket.ErrBadHandshake) {
+		t.Fatalf("taking Ann's seat as mallory: err = %v, want a refused handshake", err)
+	}
+	white := dial(t, url+"?role=white&name=ann")
+	token := expect(t, white, "welcome").Token
+
+	if status, _ := post(t, game+"/moves", `{"move": "e2 e4"}`); status != http.StatusForbidden {
+		t.Errorf("move without token: status %d, want %d", status, http.StatusForbidden)
+	}
+	if status, _ := post(t, game+"/resign", `{"team": "black", "token": "`+token+`"}`); status != http.StatusForbidden {
+		t.Errorf("resigning for black with white's token: status %d, want %d", status, http.StatusForbidden)
+	}
+	if status, _ := post(t, game+"/moves", `{"move": "e2 e4", "token": "`+token+`"}`); status != http.StatusOK {
+		t.Fatalf("move with token: status %d, want %d", status, http.StatusOK)
+	}
+	if status, _ := post(t, game+"/undo", ``); status != http.StatusConflict {
+		t.Errorf("undo: status %d, want %d", status, http.StatusConflict)
+	}
+
+	// The chat shows the name the game was created with.
+	send(t, white, command{Type: "chat", Text: "hi"})
+	if ev := expect(t, white, "chat"); ev.Chat[0].From != "Ann" {
+		t.Errorf("chat from %q, want Ann", ev.Chat[0].From)
+	}
+}
@@ -0,0 +1,343 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/rating"
+)
+
+// Server exposes the games of a Store as a JSON API:
+//
+//	POST /games                    create a game, body {"fen": "..."} is optional
+//	GET  /games                    list games
+//	GET  /games/{id}               game state
+//	POST /games/{id}/moves         body {"move": "e2 e4"}
+//	POST /games/{id}/undo          take back the last move
+//	POST /games/{id}/resign        body {"team": "white"}
+//	POST /games/{id}/draw/offer    body {"team": "white"}
+//	POST /games/{id}/draw/accept   body {"team": "black"}
+//	GET  /games/{id}/ws            live updates, see liveGame
+//	GET  /players/{name}           ratings and rating history of a player
+//	GET  /leaderboard/{category}   bullet, blitz, rapid or classical
+//
+// A game created with "clock": {"base": 300, "increment": 2} (seconds) is
+// played with a clock. One created with "white" and "black" player names
+// is rated when the store has ratings: it starts from the standard
+// position, its seats are kept for the named players, moves, resignations
+// and draw actions must carry the "token" of the player's seat, handed out
+// by liveGame, and it cannot be taken back.
+type Server struct {
+	store *Store
+	mux   *http.ServeMux
+}
+
+func New(store *Store) *Server {
+	server := &Server{store: store, mux: http.NewServeMux()}
+
+	server.mux.HandleFunc("POST /games", server.createGame)
+	server.mux.HandleFunc("GET /games", server.listGames)
+	server.mux.HandleFunc("GET /games/{id}", server.withGame(server.getGame))
+	server.mux.HandleFunc("POST /games/{id}/moves", server.withGame(server.move))
+	server.mux.HandleFunc("POST /games/{id}/undo", server.withGame(server.undo))
+	server.mux.HandleFunc("POST /games/{id}/resign", server.withGame(server.resign))
+	server.mux.HandleFunc("POST /games/{id}/draw/offer", server.withGame(server.offerDraw))
+	server.mux.HandleFunc("POST /games/{id}/draw/accept", server.withGame(server.acceptDraw))
+	server.mux.HandleFunc("GET /games/{id}/ws", server.liveGame)
+	server.mux.HandleFunc("GET /players/{name}", server.getPlayer)
+	server.mux.HandleFunc("GET /leaderboard/{category}", server.leaderboard)
+
+	return server
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mux.ServeHTTP(w, r)
+}
+
+var (
+	errUnrated    = errors.New("games on this server are not rated")
+	errRated      = errors.New("rated games cannot be taken back")
+	errRatedStart = errors.New("rated games start from the standard position")
+	errSeatToken  = errors.New("rated games need the token of the player's seat")
+)
+
+type createRequest struct {
+	FEN   string        `json:"fen"`
+	Clock *clockRequest `json:"clock"`
+	// Chess960 is the number of a Fischer Random start position, negative
+	// for a random one.
+	Chess960 *int `json:"chess960"`
+	// Variant is the name of a variant such as "Atomic", standard chess
+	// when empty.
+	Variant string `json:"variant"`
+	// White and Black name the players of a rated game.
+	White string `json:"white"`
+	Black string `json:"black"`
+}
+
+type clockRequest struct {
+	Base      float64 `json:"base"`
+	Increment float64 `json:"increment"`
+}
+
+// Token is the seat token of the player, needed in rated games.
+type moveRequest struct {
+	Move  string `json:"move"`
+	Token string `json:"token"`
+}
+
+type teamRequest struct {
+	Team  string `json:"team"`
+	Token string `json:"token"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (server *Server) createGame(w http.ResponseWriter, r *http.Request) {
+	var request createRequest
+	if r.ContentLength != 0 && !decode(w, r, &request) {
+		return
+	}
+
+	variant, err := board.ParseVariant(request.Variant)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if server.store.ratings != nil && request.White != "" && request.Black != "" {
+		for _, name := range []string{request.White, request.Black} {
+			if err := datadir.CheckName(name); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		if strings.EqualFold(request.White, request.Black) {
+			writeError(w, http.StatusBadRequest, errors.New("rated games need two different players"))
+			return
+		}
+		if request.FEN != "" || request.Chess960 != nil || variant != board.Standard {
+			writeError(w, http.StatusBadRequest, errRatedStart)
+			return
+		}
+	}
+
+	var chessGame *game.ChessGame
+	switch {
+	case request.Chess960 != nil && (request.FEN != "" || variant != board.Standard):
+		err = errors.New("chess960 cannot be combined with fen or variant")
+	case request.Chess960 != nil:
+		chessGame, err = game.NewChess960(*request.Chess960)
+	default:
+		chessGame, err = game.NewVariant(request.FEN, variant)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if request.Clock != nil {
+		if request.Clock.Base <= 0 || request.Clock.Increment < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("clock needs a positive base time"))
+			return
+		}
+		chessGame.SetClock(game.NewClock(game.TimeControl{
+			Base:      seconds(request.Clock.Base),
+			Increment: seconds(request.Clock.Increment),
+		}))
+	}
+
+	id := server.store.Add(chessGame, request.White, request.Black)
+	writeJSON(w, http.StatusCreated, newGameState(id, chessGame))
+}
+
+func (server *Server) listGames(w http.ResponseWriter, r *http.Request) {
+	summaries := []gameSummary{}
+	for _, e := range server.store.list() {
+		e.mu.Lock()
+		summaries = append(summaries, newGameSummary(e.id, e.game))
+		e.mu.Unlock()
+	}
+
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// withGame looks up the game of the request and calls the handler with the
+// game locked.
+func (server *Server) withGame(handler func(http.ResponseWriter, *http.Request, *entry)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e, ok := server.store.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, errors.New("game not found"))
+			return
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		handler(w, r, e)
+	}
+}
+
+func (server *Server) getGame(w http.ResponseWriter, r *http.Request, e *entry) {
+	writeJSON(w, http.StatusOK, newGameState(e.id, e.game))
+}
+
+func (server *Server) move(w http.ResponseWriter, r *http.Request, e *entry) {
+	var request moveRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	if !e.authorized(e.game.Turn(), request.Token) {
+		server.respond(w, e, errSeatToken)
+		return
+	}
+
+	e.checkTime()
+	err := e.game.Play(request.Move)
+	if err == nil {
+		e.announceMove(request.Move)
+	}
+	server.respond(w, e, err)
+}
+
+func (server *Server) undo(w http.ResponseWriter, r *http.Request, e *entry) {
+	if e.ratings != nil {
+		server.respond(w, e, errRated)
+		return
+	}
+
+	err := e.game.Undo()
+	if err == nil {
+		e.startClock()
+		e.announce()
+	}
+	server.respond(w, e, err)
+}
+
+func (server *Server) getPlayer(w http.ResponseWriter, r *http.Request) {
+	if server.store.ratings == nil {
+		writeError(w, http.StatusNotFound, errUnrated)
+		return
+	}
+
+	player, err := server.store.ratings.Player(r.PathValue("name"))
+	if errors.Is(err, datadir.ErrBadName) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, player)
+}
+
+func (server *Server) leaderboard(w http.ResponseWriter, r *http.Request) {
+	if server.store.ratings == nil {
+		writeError(w, http.StatusNotFound, errUnrated)
+		return
+	}
+
+	category, err := rating.ParseCategory(r.PathValue("category"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	players, err := server.store.ratings.Players()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	standings := rating.Leaderboard(players, category)
+	if standings == nil {
+		standings = []rating.Standing{}
+	}
+	writeJSON(w, http.StatusOK, standings)
+}
+
+func (server *Server) resign(w http.ResponseWriter, r *http.Request, e *entry) {
+	server.withTeam(w, r, e, e.game.Resign)
+}
+
+func (server *Server) offerDraw(w http.ResponseWriter, r *http.Request, e *entry) {
+	server.withTeam(w, r, e, e.game.OfferDraw)
+}
+
+func (server *Server) acceptDraw(w http.ResponseWriter, r *http.Request, e *entry) {
+	server.withTeam(w, r, e, e.game.AcceptDraw)
+}
+
+func (server *Server) withTeam(w http.ResponseWriter, r *http.Request, e *entry, action func(board.Team) error) {
+	var request teamRequest
+	if !decode(w, r, &request) {
+		return
+	}
+
+	team, err := board.ParseTeam(request.Team)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !e.authorized(team, request.Token) {
+		server.respond(w, e, errSeatToken)
+		return
+	}
+
+	err = action(team)
+	if err == nil {
+		e.announce()
+	}
+	server.respond(w, e, err)
+}
+
+// respond writes the game state, or maps the error of a game action to its
+// status code.
+func (server *Server) respond(w http.ResponseWriter, e *entry, err error) {
+	var illegal game.IllegalMoveError
+
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, newGameState(e.id, e.game))
+	case errors.As(err, &illegal):
+		writeError(w, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, game.ErrUnknownTeam):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, errSeatToken):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, http.StatusConflict, err)
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+// request sends a JSON body to the server and decodes the answer into v
+// unless v is nil.
+func request(t *testing.T, server *Server, method, path, body string, v interface{}) int {
+	t.Helper()
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+
+	if v != nil {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+// createGame creates a game from the body and returns its state.
+func createGame(t *testing.T, server *Server, body string) gameState {
+	t.Helper()
+
+	var state gameState
+	if status := request(t, server, "POST", "/games", body, &state); status != http.StatusCreated {
+		t.Fatalf("creating a game: status %d", status)
+	}
+	return state
+}
+
+func TestCreateFromFEN(t *testing.T) {
+	server := New(NewStore())
+	fen := "4k3/8/8/8/8/8/4P3/4K3 b - - 0 1"
+
+	state := createGame(t, server, `{"fen": "`+fen+`"}`)
+	if state.FEN != fen || state.Turn != "black" || state.Status != statusActive {
+		t.Errorf("created game %+v, want the position %s with black to move", state, fen)
+	}
+
+	var got gameState
+	if status := request(t, server, "GET", "/games/"+state.ID, "", &got); status != http.StatusOK || got.FEN != fen {
+		t.Errorf("GET /games/%s = %d %s, want %s", state.ID, status, got.FEN, fen)
+	}
+
+	if status := request(t, server, "POST", "/games", `{"fen": "8/8/8 w - - 0 1"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("creating from a broken FEN: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestMoveAndUndo(t *testing.T) {
+	server := New(NewStore())
+	id := createGame(t, server, `{"fen": ""}`).ID
+
+	var state gameState
+	if status := request(t, server, "POST", "/games/"+id+"/moves", `{"move": "e2 e4"}`, &state); status != http.StatusOK {
+		t.Fatalf("legal move: status %d", status)
+	}
+	if state.Turn != "black" || len(state.Moves) != 1 {
+		t.Errorf("after e2 e4: turn %s, moves %v", state.Turn, state.Moves)
+	}
+
+	var failure errorResponse
+	if status := request(t, server, "POST", "/games/"+id+"/moves", `{"move": "e7 e4"}`, &failure); status != http.StatusUnprocessableEntity {
+		t.Errorf("illegal move: status %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+	if failure.Error == "" {
+		t.Error("illegal move answered without an error")
+	}
+
+	if status := request(t, server, "POST", "/games/"+id+"/undo", "", &state); status != http.StatusOK {
+		t.Fatalf("undo: status %d", status)
+	}
+	if state.Turn != "white" || len(state.Moves) != 0 {
+		t.Errorf("after undo: turn %s, moves %v", state.Turn, state.Moves)
+	}
+}
+
+func TestResign(t *testing.T) {
+	server := New(NewStore())
+	id := createGame(t, server, "").ID
+
+	var state gameState
+	if status := request(t, server, "POST", "/games/"+id+"/resign", `{"team": "white"}`, &state); status != http.StatusOK {
+		t.Fatalf("resign: status %d", status)
+	}
+	if state.Status != statusFinished || state.Winner != "black" {
+		t.Errorf("after white resigned: status %s, winner %q", state.Status, state.Winner)
+	}
+
+	if status := request(t, server, "POST", "/games/"+id+"/moves", `{"move": "e2 e4"}`, nil); status != http.StatusConflict {
+		t.Errorf("move after resignation: status %d, want %d", status, http.StatusConflict)
+	}
+	if status := request(t, server, "POST", "/games/"+id+"/resign", `{"team": "nobody"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("resign of an unknown team: status %d, want %d", status, http.StatusBadRequest)
+	}
+}
+
+func TestDrawOffer(t *testing.T) {
+	server := New(NewStore())
+	id := createGame(t, server, "").ID
+
+	if status := request(t, server, "POST", "/games/"+id+"/draw/accept", `{"team": "black"}`, nil); status != http.StatusConflict {
+		t.Errorf("accepting a draw nobody offered: status %d, want %d", status, http.StatusConflict)
+	}
+
+	var state gameState
+	if status := request(t, server, "POST", "/games/"+id+"/draw/offer", `{"team": "white"}`, &state); status != http.StatusOK {
+		t.Fatalf("draw offer: status %d", status)
+	}
+	if state.DrawOffer != "white" || state.Status != statusActive {
+		t.Errorf("after the offer: offer %q, status %s", state.DrawOffer, state.Status)
+	}
+
+	if status := request(t, server, "POST", "/games/"+id+"/draw/accept", `{"team": "black"}`, &state); status != http.StatusOK {
+		t.Fatalf("draw accept: status %d", status)
+	}
+	if state.Status != statusFinished || state.Winner != "" {
+		t.Errorf("after the draw: status %s, winner %q", state.Status, state.Winner)
+	}
+}
+
+func TestUnknownGame(t *testing.T) {
+	server := New(NewStore())
+
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/games/7"},
+		{"POST", "/games/7/moves"},
+		{"POST", "/games/7/undo"},
+		{"POST", "/games/7/resign"},
+	} {
+		status := request(t, server, route.method, route.path, `{"move": "e2 e4", "team": "white"}`, nil)
+		if status != http.StatusNotFound {
+			t.Errorf("%s %s: status %d, want %d", route.method, route.path, status, http.StatusNotFound)
+		}
+	}
+}
+
+func TestRatedPlayerNames(t *testing.T) {
+	ratings, err := storage.NewRatingStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore()
+	store.SetRatings(ratings)
+	server := New(store)
+
+	if status := request(t, server, "POST", "/games", `{"white": "../ann", "black": "bob"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("rated game with a bad name: status %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := request(t, server, "GET", "/players/a.b", "", nil); status != http.StatusBadRequest {
+		t.Errorf("player with a bad name: status %d, want %d", status, http.StatusBadRequest)
+	}
+	if status := request(t, server, "GET", "/players/Ann", "", nil); status != http.StatusOK {
+		t.Errorf("new player: status %d, want %d", status, http.StatusOK)
+	}
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+const (
+	statusActive   = "active"
+	statusFinished = "finished"
+)
+
+type gameState struct {
+	ID            string      `json:"id"`
+	FEN           string      `json:"fen"`
+	Board         []string    `json:"board"`
+	Turn          string      `json:"turn"`
+	Check         bool        `json:"check"`
+	LegalMoves    []string    `json:"legalMoves"`
+	Moves         []string    `json:"moves"`
+	WhiteCaptures []string    `json:"whiteCaptures"`
+	BlackCaptures []string    `json:"blackCaptures"`
+	Status        string      `json:"status"`
+	Winner        string      `json:"winner,omitempty"`
+	Termination   string      `json:"termination,omitempty"`
+	DrawOffer     string      `json:"drawOffer,omitempty"`
+	Clock         *clockState `json:"clock,omitempty"`
+}
+
+type clockState struct {
+	White   int64  `json:"white"`
+	Black   int64  `json:"black"`
+	Running string `json:"running,omitempty"`
+}
+
+type gameSummary struct {
+	ID     string `json:"id"`
+	FEN    string `json:"fen"`
+	Turn   string `json:"turn"`
+	Moves  int    `json:"moves"`
+	Status string `json:"status"`
+}
+
+// newGameState must be called with the entry lock held.
+func newGameState(id string, chessGame *game.ChessGame) gameState {
+	b := chessGame.Board()
+
+	state := gameState{
+		ID:            id,
+		FEN:           chessGame.FEN(),
+		Board:         boardRows(b),
+		Turn:          chessGame.Turn().String(),
+		Check:         b.InCheck(chessGame.Turn()),
+		LegalMoves:    chessGame.LegalMoves(),
+		Moves:         chessGame.Moves(),
+		WhiteCaptures: fenLetters(b.WhiteCaptures()),
+		BlackCaptures: fenLetters(b.BlackCaptures()),
+		Status:        statusActive,
+	}
+
+	if offer := chessGame.DrawOffer(); offer != board.Undecided {
+		state.DrawOffer = offer.String()
+	}
+
+	state.Clock = newClockState(chessGame.Clock())
+
+	if result, over := chessGame.Result(); over {
+		state.Status = statusFinished
+		state.Termination = result.Termination
+		if result.Winner != board.Undecided {
+			state.Winner = result.Winner.String()
+		}
+	}
+
+	return state
+}
+
+func newGameSummary(id string, chessGame *game.ChessGame) gameSummary {
+	summary := gameSummary{
+		ID:     id,
+		FEN:    chessGame.FEN(),
+		Turn:   chessGame.Turn().String(),
+		Moves:  len(chessGame.Moves()),
+		Status: statusActive,
+	}
+
+	if _, over := chessGame.Result(); over {
+		summary.Status = statusFinished
+	}
+
+	return summary
+}
+
+// newClockState returns the remaining times in milliseconds, or nil for
+// games without a clock.
+func newClockState(clock *game.Clock) *clockState {
+	if clock == nil {
+		return nil
+	}
+
+	state := &clockState{
+		White: clock.Remaining(board.White).Milliseconds(),
+		Black: clock.Remaining(board.Black).Milliseconds(),
+	}
+	if running := clock.Running(); running != board.Undecided {
+		state.Running = running.String()
+	}
+
+	return state
+}
+
+// boardRows renders every rank as eight FEN letters from the a-file, with
+// "." for empty squares, starting from the 8th rank.
+func boardRows(b *board.Board) []string {
+	var rows []string
+	for _, row := range b.Grid() {
+		var buffer strings.Builder
+		for _, sign := range row {
+			if sign == "" {
+				buffer.WriteString(".")
+			} else {
+				buffer.WriteString(board.FENLetter(sign))
+			}
+		}
+		rows = append(rows, buffer.String())
+	}
+
+	return rows
+}
+
+func fenLetters(signs []string) []string {
+	letters := make([]string, 0, len(signs))
+	for _, sign := range signs {
+		letters = append(letters, board.FENLetter(sign))
+	}
+
+	return letters
+}
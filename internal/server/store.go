@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/storage"
+)
+
+// Store keeps the games of the server in memory. The map is guarded by the
+// store lock and every game by its own lock, so that moves in different
+// games do not wait for each other.
+type Store struct {
+	mu     sync.RWMutex
+	games  map[string]*entry
+	nextID int
+	// ratings, when set, rates the finished games between named players.
+	ratings *storage.RatingStore
+}
+
+type entry struct {
+	mu   sync.Mutex
+	id   string
+	game *game.ChessGame
+	hub  *hub
+	// white and black name the players of a rated game.
+	white, black string
+	ratings      *storage.RatingStore
+	rated        bool
+	// watching is set while watchClock runs.
+	watching bool
+}
+
+func NewStore() *Store {
+	return &Store{games: make(map[string]*entry)}
+}
+
+// SetRatings makes the games between named players rated, the ratings being
+// kept in the store. It must be called before serving.
+func (store *Store) SetRatings(ratings *storage.RatingStore) {
+	store.ratings = ratings
+}
+
+// Add stores a game. A game whose players are both named is rated when the
+// store has ratings.
+func (store *Store) Add(chessGame *game.ChessGame, white, black string) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.nextID++
+	id := strconv.Itoa(store.nextID)
+	e := &entry{id: id, game: chessGame, hub: newHub(), white: white, black: black}
+	if white != "" && black != "" {
+		e.ratings = store.ratings
+	}
+	store.games[id] = e
+	e.startClock()
+
+	return id
+}
+
+// rate updates the ratings of the players once a rated game is over. It must
+// be called with the entry lock held.
+func (e *entry) rate() {
+	if e.ratings == nil || e.rated {
+		return
+	}
+	if _, over := e.game.Result(); !over {
+		return
+	}
+
+	e.rated = true
+	if _, _, err := e.ratings.Rate(e.white, e.black, e.game); err != nil && !errors.Is(err, storage.ErrUnrated) {
+		e.hub.broadcast(event{Type: "error", Error: "ratings were not updated: " + err.Error()})
+	}
+}
+
+// player is the name of the player of the team in a rated game, empty
+// otherwise.
+func (e *entry) player(team board.Team) string {
+	switch {
+	case e.ratings == nil:
+		return ""
+	case team == board.White:
+		return e.white
+	case team == board.Black:
+		return e.black
+	}
+
+	return ""
+}
+
+// authorized reports whether a REST action of the team may be played: in a
+// rated game only with the token of the team's seat.
+func (e *entry) authorized(team board.Team, token string) bool {
+	return e.ratings == nil || e.hub.holds(team, token)
+}
+
+func (store *Store) get(id string) (*entry, bool) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	e, ok := store.games[id]
+	return e, ok
+}
+
+func (store *Store) list() []*entry {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+
+	entries := make([]*entry, 0, len(store.games))
+	for _, e := range store.games {
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, _ := strconv.Atoi(entries[i].id)
+		b, _ := strconv.Atoi(entries[j].id)
+		return a < b
+	})
+
+	return entries
+}
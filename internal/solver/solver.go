@@ -0,0 +1,290 @@
+// Package solver proves forced mates for chess problems: the side to move,
+// the attacker, mates in at most n moves whatever the defender plays. It
+// finds every key, the first moves that force the mate, so that cooked
+// problems with more than one key show up, and writes out the solution
+// tree of each.
+package solver
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+var ErrNoMate = errors.New("no forced mate")
+
+// Attack is a move of the attacker and the defences against it, none when
+// it mates.
+type Attack struct {
+	Command  string
+	SAN      string
+	Defences []Defence
+}
+
+// Defence is a reply of the defender and the quickest way the attacker
+// goes on to mate.
+type Defence struct {
+	Command string
+	SAN     string
+	Attack  Attack
+}
+
+// Key is a first move that forces mate in Moves moves.
+type Key struct {
+	Attack
+	Moves int
+}
+
+// Solution lists the keys, the quickest mates first. The problem is cooked
+// when there is more than one.
+type Solution struct {
+	Keys  []Key
+	Nodes int
+}
+
+func (solution Solution) Cooked() bool {
+	return len(solution.Keys) > 1
+}
+
+// Solve finds every first move of the team that mates in at most n moves,
+// with its solution tree.
+func Solve(b *board.Board, team board.Team, n int) (Solution, error) {
+	if n < 1 {
+		return Solution{}, errors.New("mate must be in at least one move")
+	}
+
+	s := &solver{
+		attacker: team,
+		proven:   make(map[string]int),
+		refuted:  make(map[string]int),
+		killers:  make(map[int]string),
+	}
+
+	var solution Solution
+	for _, move := range b.LegalMoves(team) {
+		for moves := 1; moves <= n; moves++ {
+			if s.mateMove(b, move, moves) {
+				solution.Keys = append(solution.Keys, Key{Attack: s.attack(b, move, moves), Moves: moves})
+				break
+			}
+		}
+	}
+	solution.Nodes = s.nodes
+	if len(solution.Keys) == 0 {
+		return solution, ErrNoMate
+	}
+
+	sort.SliceStable(solution.Keys, func(i, j int) bool {
+		return solution.Keys[i].Moves < solution.Keys[j].Moves
+	})
+	return solution, nil
+}
+
+// solver runs a depth-limited AND/OR search: some move of the attacker
+// must meet every reply of the defender. Positions with the attacker to
+// move are remembered by the depth they were proven or refuted at.
+type solver struct {
+	attacker board.Team
+	proven   map[string]int
+	refuted  map[string]int
+	// killers holds by depth the last reply that refuted an attack, tried
+	// first against the next one.
+	killers map[int]string
+	nodes   int
+}
+
+// mates reports whether the attacker, to move, mates in at most n moves.
+func (s *solver) mates(b *board.Board, n int) bool {
+	key := b.PositionKey(s.attacker)
+	if depth, ok := s.proven[key]; ok && depth <= n {
+		return true
+	}
+	if depth, ok := s.refuted[key]; ok && depth >= n {
+		return false
+	}
+
+	found := false
+	for _, child := range s.children(b, n) {
+		if child.mate || n > 1 && s.defended(child.board, n) {
+			found = true
+			break
+		}
+	}
+
+	if found {
+		s.proven[key] = n
+	} else {
+		s.refuted[key] = max(s.refuted[key], n)
+	}
+	return found
+}
+
+// mateMove reports whether the move of the attacker mates in at most n
+// moves.
+func (s *solver) mateMove(b *board.Board, move string, n int) bool {
+	s.nodes++
+	child := b.Clone()
+	if child.Execute(move, s.attacker) {
+		return true
+	}
+
+	return n > 1 && s.defended(child, n)
+}
+
+// defended reports whether the attacker, having moved, mates in at most n
+// moves, counting the move played, against every reply.
+func (s *solver) defended(b *board.Board, n int) bool {
+	defender := s.attacker.Opponent()
+	replies := b.LegalMoves(defender)
+	if len(replies) == 0 {
+		// Stalemate, mate having been found by Execute.
+		return false
+	}
+
+	for _, reply := range s.orderDefences(b, replies, n) {
+		s.nodes++
+		next := b.Clone()
+		next.Execute(reply, defender)
+		if !s.mates(next, n-1) {
+			s.killers[n] = reply
+			return false
+		}
+	}
+
+	return true
+}
+
+type child struct {
+	board *board.Board
+	mate  bool
+	check bool
+}
+
+// children plays every move of the attacker, checks first. Without moves
+// left after this one only a mate will do, so the search stops at it.
+func (s *solver) children(b *board.Board, n int) []child {
+	defender := s.attacker.Opponent()
+	grid := b.Grid()
+
+	var checks, captures, quiet []child
+	for _, move := range b.LegalMoves(s.attacker) {
+		s.nodes++
+		position := b.Clone()
+		if position.Execute(move, s.attacker) {
+			return []child{{board: position, mate: true}}
+		}
+		if n == 1 {
+			continue
+		}
+
+		c := child{board: position, check: position.InCheck(defender)}
+		switch {
+		case c.check:
+			checks = append(checks, c)
+		case isCapture(grid, move):
+			captures = append(captures, c)
+		default:
+			quiet = append(quiet, c)
+		}
+	}
+
+	return append(append(checks, captures...), quiet...)
+}
+
+// orderDefences tries the reply that refuted the last attack first, then
+// captures and king moves, which most often get out of a mating net.
+func (s *solver) orderDefences(b *board.Board, replies []string, n int) []string {
+	grid := b.Grid()
+	killer := s.killers[n]
+
+	keys := make(map[string]int, len(replies))
+	for _, reply := range replies {
+		switch {
+		case reply == killer:
+			keys[reply] = 3
+		case isCapture(grid, reply):
+			keys[reply] = 2
+		case strings.ToLower(at(grid, strings.Fields(reply)[0])) == "k":
+			keys[reply] = 1
+		}
+	}
+
+	ordered := append([]string(nil), replies...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return keys[ordered[i]] > keys[ordered[j]]
+	})
+	return ordered
+}
+
+// attack writes out the solution tree of a move that mates in n moves:
+// every defence with the quickest mate after it.
+func (s *solver) attack(b *board.Board, move string, n int) Attack {
+	san, _ := b.SAN(move, s.attacker)
+	attack := Attack{Command: move, SAN: san}
+
+	child := b.Clone()
+	if child.Execute(move, s.attacker) {
+		return attack
+	}
+
+	defender := s.attacker.Opponent()
+	for _, reply := range child.LegalMoves(defender) {
+		defence := Defence{Command: reply}
+		defence.SAN, _ = child.SAN(reply, defender)
+
+		next := child.Clone()
+		next.Execute(reply, defender)
+		defence.Attack = s.quickest(next, n-1)
+		attack.Defences = append(attack.Defences, defence)
+	}
+
+	return attack
+}
+
+// quickest returns the tree of the attacker's quickest mate, in at most n
+// moves.
+func (s *solver) quickest(b *board.Board, n int) Attack {
+	for moves := 1; moves <= n; moves++ {
+		if !s.mates(b, moves) {
+			continue
+		}
+		for _, move := range b.LegalMoves(s.attacker) {
+			if s.mateMove(b, move, moves) {
+				return s.attack(b, move, moves)
+			}
+		}
+	}
+
+	return Attack{}
+}
+
+// isCapture reports whether the move lands on a piece, castling by taking
+// one's own rook aside.
+func isCapture(grid [][]string, move string) bool {
+	tokens := strings.Fields(move)
+	if len(tokens) < 2 {
+		return false
+	}
+
+	piece, target := at(grid, tokens[0]), at(grid, tokens[1])
+	return target != "" && (piece == strings.ToLower(piece)) != (target == strings.ToLower(target))
+}
+
+// at returns the sign on the square of the grid, row 0 being the last rank.
+func at(grid [][]string, square string) string {
+	if strings.Contains(square, "@") || len(square) < 2 {
+		return ""
+	}
+
+	rank := 0
+	for _, digit := range square[1:] {
+		rank = 10*rank + int(digit-'0')
+	}
+	if rank < 1 || rank > len(grid) {
+		return ""
+	}
+
+	return grid[len(grid)-rank][square[0]-'a']
+}
@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func solve(t *testing.T, fen string, n int) (Solution, error) {
+	t.Helper()
+
+	b, team, err := board.ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Solve(b, team, n)
+}
+
+func TestUniqueKeys(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		fen  string
+		n    int
+		key  string
+	}{
+		{"back rank mate in 1", "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 1, "Ra8#"},
+		{"Morphy's mate in 2", "kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1", 2, "Ra6"},
+		{"Philidor's smothered mate in 3", "5rk1/5Npp/8/8/8/1Q6/8/6K1 w - - 0 1", 3, "Nh6+"},
+	} {
+		solution, err := solve(t, test.fen, test.n)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if solution.Cooked() || solution.Keys[0].SAN != test.key || solution.Keys[0].Moves != test.n {
+			t.Errorf("%s: keys %+v, want only %s mating in %d", test.name, solution.Keys, test.key, test.n)
+		}
+	}
+}
+
+func TestSolutionTree(t *testing.T) {
+	solution, err := solve(t, "kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers := make(map[string]string)
+	for _, defence := range solution.Keys[0].Defences {
+		answers[defence.SAN] = defence.Attack.SAN
+	}
+	if answers["bxa6"] != "b7#" || answers["Bc7"] != "Rxa7#" {
+		t.Errorf("answers to the defences = %v, want bxa6 b7# and Bc7 Rxa7#", answers)
+	}
+}
+
+func TestCook(t *testing.T) {
+	solution, err := solve(t, "6k1/5ppp/8/8/8/8/8/R3R1K1 w - - 0 1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !solution.Cooked() || len(solution.Keys) != 2 {
+		t.Fatalf("keys %+v, want Ra8# and Re8#", solution.Keys)
+	}
+	for i, san := range []string{"Ra8#", "Re8#"} {
+		if solution.Keys[i].SAN != san {
+			t.Errorf("key %d = %s, want %s", i, solution.Keys[i].SAN, san)
+		}
+	}
+}
+
+func TestNoMate(t *testing.T) {
+	if _, err := solve(t, "5rk1/5Npp/8/8/8/1Q6/8/6K1 w - - 0 1", 2); !errors.Is(err, ErrNoMate) {
+		t.Errorf("mate in 3 solved as a mate in 2: err = %v, want ErrNoMate", err)
+	}
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+)
+
+const fileExtension = ".json"
+
+// FileStore keeps every record as <id>.json in a directory. IDs are
+// consecutive numbers.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// DefaultDir is ~/.chess_on_golang/games.
+func DefaultDir() string {
+	return datadir.Path("games")
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (store *FileStore) Save(record *Record) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	if record.ID == "" {
+		id, err := store.nextID()
+		if err != nil {
+			return err
+		}
+		record.ID = id
+		record.CreatedAt = now
+	}
+	record.UpdatedAt = now
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return datadir.WriteFile(store.path(record.ID), data)
+}
+
+func (store *FileStore) Load(id string) (Record, error) {
+	if strings.ContainsAny(id, `/\`) || id == "" {
+		return Record{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(store.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Record{}, ErrNotFound
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return Record{}, err
+	}
+
+	return record, nil
+}
+
+func (store *FileStore) List() ([]Record, error) {
+	ids, err := store.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		record, err := store.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].UpdatedAt.After(records[j].UpdatedAt)
+	})
+
+	return records, nil
+}
+
+func (store *FileStore) ids() ([]string, error) {
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, fileExtension) {
+			ids = append(ids, strings.TrimSuffix(name, fileExtension))
+		}
+	}
+
+	return ids, nil
+}
+
+func (store *FileStore) nextID() (string, error) {
+	ids, err := store.ids()
+	if err != nil {
+		return "", err
+	}
+
+	next := 1
+	for _, id := range ids {
+		if n, err := strconv.Atoi(id); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	return strconv.Itoa(next), nil
+}
+
+func (store *FileStore) path(id string) string {
+	return filepath.Join(store.dir, id+fileExtension)
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/rating"
+)
+
+var ErrUnrated = errors.New("the game is not rated")
+
+// RatingStore keeps the ratings of every player as <name>.json in a
+// directory, named as datadir.File names them: names are compared without
+// case and limited to letters, digits, '-' and '_'.
+type RatingStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// DefaultRatingsDir is ~/.chess_on_golang/ratings.
+func DefaultRatingsDir() string {
+	return datadir.Path("ratings")
+}
+
+func NewRatingStore(dir string) (*RatingStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &RatingStore{dir: dir}, nil
+}
+
+// Player returns the ratings of the player, without any for a player who
+// has not played a rated game.
+func (store *RatingStore) Player(name string) (*rating.Player, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.load(name)
+}
+
+// Players returns every rated player by name.
+func (store *RatingStore) Players() ([]*rating.Player, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entries, err := os.ReadDir(store.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var players []*rating.Player
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExtension) {
+			continue
+		}
+		player, err := store.read(filepath.Join(store.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		return strings.ToLower(players[i].Name) < strings.ToLower(players[j].Name)
+	})
+	return players, nil
+}
+
+// Rate updates and saves the ratings of the players of a finished game in
+// the category of its clock, and returns them. Games that rating.Rated
+// leaves out and games of a player against themselves give ErrUnrated.
+func (store *RatingStore) Rate(white, black string, chessGame *game.ChessGame) (*rating.Player, *rating.Player, error) {
+	if !rating.Rated(chessGame) || strings.EqualFold(white, black) {
+		return nil, nil, ErrUnrated
+	}
+	result, _ := chessGame.Result()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	whitePlayer, err := store.load(white)
+	if err != nil {
+		return nil, nil, err
+	}
+	blackPlayer, err := store.load(black)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rating.Rate(whitePlayer, blackPlayer, rating.CategoryOfGame(chessGame), result, time.Now())
+	if err := store.save(whitePlayer, blackPlayer); err != nil {
+		return nil, nil, err
+	}
+
+	return whitePlayer, blackPlayer, nil
+}
+
+func (store *RatingStore) load(name string) (*rating.Player, error) {
+	path, err := datadir.File(store.dir, name, fileExtension)
+	if err != nil {
+		return nil, err
+	}
+
+	player, err := store.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return rating.NewPlayer(name), nil
+	}
+
+	return player, err
+}
+
+func (store *RatingStore) read(path string) (*rating.Player, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	player := rating.NewPlayer("")
+	if err := json.Unmarshal(data, player); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return player, nil
+}
+
+// save writes the players together, so that a game never counts for one
+// of them only: every player is written to a temporary file first, as
+// datadir.WriteFile does, and when one cannot be renamed over the player's
+// file, the files already replaced are restored.
+func (store *RatingStore) save(players ...*rating.Player) error {
+	type file struct {
+		path, temporary string
+		previous        []byte
+		existed         bool
+	}
+
+	var files []file
+	removeTemporaries := func(files []file) {
+		for _, f := range files {
+			os.Remove(f.temporary)
+		}
+	}
+
+	for _, player := range players {
+		path, err := datadir.File(store.dir, player.Name, fileExtension)
+		if err != nil {
+			removeTemporaries(files)
+			return err
+		}
+		previous, err := os.ReadFile(path)
+		existed := err == nil
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			removeTemporaries(files)
+			return err
+		}
+		data, err := json.MarshalIndent(player, "", "  ")
+		if err != nil {
+			removeTemporaries(files)
+			return err
+		}
+		temporary, err := datadir.WriteTemporary(path, data)
+		if err != nil {
+			removeTemporaries(files)
+			return err
+		}
+		files = append(files, file{path: path, temporary: temporary, previous: previous, existed: existed})
+	}
+
+	for i, f := range files {
+		if err := os.Rename(f.temporary, f.path); err != nil {
+			removeTemporaries(files[i:])
+			for _, done := range files[:i] {
+				if done.existed {
+					datadir.WriteFile(done.path, done.previous)
+				} else {
+					os.Remove(done.path)
+				}
+			}
+			return err
+		}
+	}
+
+	return nil
+}
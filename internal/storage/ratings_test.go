@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+// resignedGame is a game black resigned after a move each.
+func resignedGame(t *testing.T) *game.ChessGame {
+	t.Helper()
+
+	chessGame, err := game.NewVariant("", board.Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, move := range []string{"e2 e4", "e7 e5"} {
+		if err := chessGame.Play(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := chessGame.Resign(board.Black); err != nil {
+		t.Fatal(err)
+	}
+
+	return chessGame
+}
+
+func TestRate(t *testing.T) {
+	store, err := NewRatingStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	white, black, err := store.Rate("Ann", "bob", resignedGame(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if white.Ratings["classical"].Wins != 1 || black.Ratings["classical"].Losses != 1 {
+		t.Fatalf("ratings = %+v and %+v, want a win for Ann", white.Ratings, black.Ratings)
+	}
+
+	// Names are compared without case.
+	again, err := store.Player("ann")
+	if err != nil || again.Ratings["classical"].Games != 1 {
+		t.Fatalf("Player(ann) = %+v, %v, want Ann's rating", again, err)
+	}
+
+	entries, _ := os.ReadDir(store.dir)
+	if len(entries) != 2 {
+		t.Errorf("%d files in the store, want ann.json and bob.json without temporary files", len(entries))
+	}
+}
+
+func TestRateWritesBothOrNeither(t *testing.T) {
+	store, err := NewRatingStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Black's file cannot be read, so the game must not count for white
+	// either.
+	if err := os.Mkdir(filepath.Join(store.dir, "bob.json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Rate("ann", "bob", resignedGame(t)); err == nil {
+		t.Fatal("Rate succeeded with an unreadable player")
+	}
+
+	if _, err := os.Stat(filepath.Join(store.dir, "ann.json")); !os.IsNotExist(err) {
+		t.Errorf("ann.json was written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.dir, "ann.json.tmp")); !os.IsNotExist(err) {
+		t.Errorf("ann.json.tmp was left behind: %v", err)
+	}
+}
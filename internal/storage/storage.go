@@ -0,0 +1,134 @@
+// Package storage persists games so that they can be listed and resumed
+// later. Store is the interface for backends, FileStore keeps every game as
+// a JSON file in a directory. RatingStore keeps the ratings of the players
+// of the servers.
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+var ErrNotFound = errors.New("game not found")
+
+type Store interface {
+	// Save writes the record, assigning an ID to new records.
+	Save(record *Record) error
+	Load(id string) (Record, error)
+	// List returns all records, most recently updated first.
+	List() ([]Record, error)
+}
+
+// Record is the persisted form of a game.
+type Record struct {
+	ID          string       `json:"id"`
+	White       string       `json:"white,omitempty"`
+	Black       string       `json:"black,omitempty"`
+	StartFEN    string       `json:"startFen"`
+	Variant     string       `json:"variant,omitempty"`
+	Moves       []string     `json:"moves"`
+	Clock       *ClockRecord `json:"clock,omitempty"`
+	Result      string       `json:"result"`
+	Winner      string       `json:"winner,omitempty"`
+	Termination string       `json:"termination,omitempty"`
+	WhiteHints  int          `json:"whiteHints,omitempty"`
+	BlackHints  int          `json:"blackHints,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+// ClockRecord holds the time control and the remaining times in milliseconds.
+type ClockRecord struct {
+	Base      int64 `json:"baseMs"`
+	Increment int64 `json:"incrementMs"`
+	White     int64 `json:"whiteMs"`
+	Black     int64 `json:"blackMs"`
+}
+
+const unfinished = "*"
+
+// Finished reports whether the recorded game has a result.
+func (record Record) Finished() bool {
+	return record.Result != "" && record.Result != unfinished
+}
+
+// Capture copies the current state of the game into the record.
+func (record *Record) Capture(chessGame *game.ChessGame) {
+	record.StartFEN = chessGame.StartFEN()
+	record.Variant = ""
+	if variant := chessGame.Variant(); variant != board.Standard {
+		record.Variant = variant.Name()
+	}
+	record.Moves = chessGame.Moves()
+	record.WhiteHints = chessGame.Hints(board.White)
+	record.BlackHints = chessGame.Hints(board.Black)
+
+	record.Clock = nil
+	if clock := chessGame.Clock(); clock != nil {
+		record.Clock = &ClockRecord{
+			Base:      clock.Control().Base.Milliseconds(),
+			Increment: clock.Control().Increment.Milliseconds(),
+			White:     clock.Remaining(board.White).Milliseconds(),
+			Black:     clock.Remaining(board.Black).Milliseconds(),
+		}
+	}
+
+	record.Result, record.Winner, record.Termination = unfinished, "", ""
+	if result, over := chessGame.Result(); over {
+		record.Result = result.Score()
+		record.Termination = result.Termination
+		if result.Winner != board.Undecided {
+			record.Winner = result.Winner.String()
+		}
+	}
+}
+
+// Restore replays the recorded moves and returns the game in the state it
+// was saved in.
+func Restore(record Record) (*game.ChessGame, error) {
+	variant, err := board.ParseVariant(record.Variant)
+	if err != nil {
+		return nil, err
+	}
+
+	chessGame, err := game.NewVariant(record.StartFEN, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Clock != nil {
+		chessGame.SetClock(game.NewClock(game.TimeControl{
+			Base:      time.Duration(record.Clock.Base) * time.Millisecond,
+			Increment: time.Duration(record.Clock.Increment) * time.Millisecond,
+		}))
+	}
+
+	for _, move := range record.Moves {
+		if err := chessGame.Play(move); err != nil {
+			return nil, err
+		}
+	}
+
+	chessGame.SetHints(board.White, record.WhiteHints)
+	chessGame.SetHints(board.Black, record.BlackHints)
+
+	if clock := chessGame.Clock(); clock != nil {
+		clock.SetRemaining(board.White, time.Duration(record.Clock.White)*time.Millisecond)
+		clock.SetRemaining(board.Black, time.Duration(record.Clock.Black)*time.Millisecond)
+	}
+
+	if _, over := chessGame.Result(); record.Finished() && !over {
+		winner := board.Undecided
+		if record.Winner != "" {
+			if winner, err = board.ParseTeam(record.Winner); err != nil {
+				return nil, err
+			}
+		}
+		chessGame.SetResult(game.Result{Winner: winner, Termination: record.Termination})
+	}
+
+	return chessGame, nil
+}
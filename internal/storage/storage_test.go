@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+func TestHintsAreSaved(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chessGame, err := game.NewFromFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chessGame.Hint(); err != nil {
+		t.Fatal(err)
+	}
+	if err := chessGame.Play("g1 f1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chessGame.Threats(); err != nil {
+		t.Fatal(err)
+	}
+
+	record := &Record{}
+	record.Capture(chessGame)
+	if err := store.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := store.Load(record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Restore(loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if white, black := restored.Hints(board.White), restored.Hints(board.Black); white != 1 || black != 1 {
+		t.Errorf("restored hints: white %d, black %d, want 1 each", white, black)
+	}
+}
@@ -0,0 +1,396 @@
+package tablebase
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Squares count from a1 along the ranks to h8 and sets of squares are
+// bitboards, the strong side playing White.
+var (
+	kingMask   [64]uint64
+	knightMask [64]uint64
+	pawnMask   [64]uint64
+	rookLine   [64]uint64
+	bishopLine [64]uint64
+	// between holds the squares strictly between two squares on a line.
+	between [64][64]uint64
+	// rays lists the squares in each direction from a square, the first
+	// four directions along ranks and files.
+	rays [64][8][]int
+)
+
+var directions = [8][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func init() {
+	knightJumps := [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+
+	for square := 0; square < 64; square++ {
+		file, rank := square%8, square/8
+		for i, direction := range directions {
+			if to, ok := offset(file, rank, direction[0], direction[1]); ok {
+				kingMask[square] |= 1 << to
+			}
+
+			var path uint64
+			for step := 1; ; step++ {
+				to, ok := offset(file, rank, step*direction[0], step*direction[1])
+				if !ok {
+					break
+				}
+				rays[square][i] = append(rays[square][i], to)
+				between[square][to] = path
+				path |= 1 << to
+				if i < 4 {
+					rookLine[square] |= 1 << to
+				} else {
+					bishopLine[square] |= 1 << to
+				}
+			}
+		}
+		for _, jump := range knightJumps {
+			if to, ok := offset(file, rank, jump[0], jump[1]); ok {
+				knightMask[square] |= 1 << to
+			}
+		}
+		for _, df := range []int{-1, 1} {
+			if to, ok := offset(file, rank, df, 1); ok {
+				pawnMask[square] |= 1 << to
+			}
+		}
+	}
+}
+
+func offset(file, rank, df, dr int) (int, bool) {
+	file, rank = file+df, rank+dr
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return 0, false
+	}
+
+	return rank*8 + file, true
+}
+
+// attacks reports whether a piece of the strong side on from attacks to,
+// sliding pieces being stopped by the occupied squares.
+func attacks(kind byte, from, to int, occupied uint64) bool {
+	target := uint64(1) << to
+	switch kind {
+	case 'k':
+		return kingMask[from]&target != 0
+	case 'n':
+		return knightMask[from]&target != 0
+	case 'p':
+		return pawnMask[from]&target != 0
+	case 'r':
+		return rookLine[from]&target != 0 && between[from][to]&occupied == 0
+	case 'b':
+		return bishopLine[from]&target != 0 && between[from][to]&occupied == 0
+	case 'q':
+		return (rookLine[from]|bishopLine[from])&target != 0 && between[from][to]&occupied == 0
+	}
+
+	return false
+}
+
+// Requires returns the tables Generate needs for the endgame: those of the
+// pieces a pawn promotes to. Knights and bishops cannot mate alone, their
+// promotions are draws.
+func Requires(name string) []string {
+	if !strings.Contains(name, "P") {
+		return nil
+	}
+
+	return []string{strings.Replace(name, "P", "Q", 1), strings.Replace(name, "P", "R", 1)}
+}
+
+// generator builds a table. Men are numbered as in the index: the strong
+// king, the lone king, then the pieces.
+type generator struct {
+	table *Table
+	kinds []byte
+	men   int
+	// size is the number of positions with one side to move, those with the
+	// lone king to move coming second.
+	size int
+	// moves counts the moves of the lone king not yet known to lose.
+	moves []byte
+	// seeds are the positions won by a promotion, by the distance to mate.
+	seeds map[int][]int
+}
+
+// Generate builds the table of the endgame by retrograde analysis: from the
+// mates it goes back one ply at a time to the positions that reach them.
+// load returns the tables listed by Requires.
+func Generate(name string, load func(name string) (*Table, error)) (*Table, error) {
+	table, err := newTable(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(table.pieces) > 1 && strings.Contains(table.pieces, "p") {
+		return nil, errors.New("pawns are only generated alone with their king")
+	}
+
+	g := &generator{
+		table: table,
+		kinds: []byte("kk" + table.pieces),
+		men:   table.men(),
+		size:  1 << (6 * table.men()),
+		seeds: make(map[int][]int),
+	}
+	g.moves = make([]byte, g.size)
+
+	g.classify()
+	for _, promoted := range Requires(name) {
+		child, err := load(promoted)
+		if err != nil {
+			return nil, err
+		}
+		g.promote(child)
+	}
+
+	for distance := 0; ; distance++ {
+		if distance+2 >= illegal {
+			return nil, fmt.Errorf("%s has mates longer than %d plies", name, illegal-2)
+		}
+
+		pending := false
+		for seed, positions := range g.seeds {
+			switch {
+			case seed == distance:
+				for _, index := range positions {
+					if table.values[index] == draw {
+						table.values[index] = byte(distance + 1)
+					}
+				}
+			case seed > distance:
+				pending = true
+			}
+		}
+
+		found := false
+		for index, value := range table.values {
+			if value == byte(distance+1) {
+				g.retract(index, distance)
+				found = true
+			}
+		}
+		if !found && !pending {
+			break
+		}
+	}
+
+	return table, nil
+}
+
+// decode fills the squares of the men and reports whether the lone king is
+// to move.
+func (g *generator) decode(index int, squares []int) bool {
+	for i := g.men - 1; i >= 0; i-- {
+		squares[i] = index & 63
+		index >>= 6
+	}
+
+	return index == 1
+}
+
+func (g *generator) encode(loneToMove bool, squares []int) int {
+	return g.table.index(!loneToMove, squares)
+}
+
+// attacked reports whether the strong side attacks the square, leaving out
+// the man skip, a piece the lone king takes.
+func (g *generator) attacked(squares []int, square int, occupied uint64, skip int) bool {
+	for i := 0; i < g.men; i++ {
+		if i != 1 && i != skip && attacks(g.kinds[i], squares[i], square, occupied) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classify marks the positions that cannot occur and the mates, and counts
+// the moves of the lone king.
+func (g *generator) classify() {
+	squares := make([]int, g.men)
+	for index := range g.table.values {
+		loneToMove := g.decode(index, squares)
+
+		var occupied uint64
+		for _, square := range squares {
+			occupied |= 1 << square
+		}
+		if bits.OnesCount64(occupied) != g.men || kingMask[squares[0]]&(1<<squares[1]) != 0 {
+			g.table.values[index] = illegal
+			continue
+		}
+		if g.pawnOnEdgeRank(squares) {
+			g.table.values[index] = illegal
+			continue
+		}
+
+		inCheck := g.attacked(squares, squares[1], occupied, -1)
+		if !loneToMove {
+			if inCheck {
+				g.table.values[index] = illegal
+			}
+			continue
+		}
+
+		// The lone king leaves its square, which may have stopped a line.
+		moves := 0
+		free := occupied &^ (1 << squares[1])
+		for targets := kingMask[squares[1]] &^ kingMask[squares[0]] &^ (1 << squares[0]); targets != 0; targets &= targets - 1 {
+			target := bits.TrailingZeros64(targets)
+			taken := -1
+			for i := 2; i < g.men; i++ {
+				if squares[i] == target {
+					taken = i
+				}
+			}
+			if !g.attacked(squares, target, free, taken) {
+				moves++
+			}
+		}
+
+		g.moves[index-g.size] = byte(moves)
+		if moves == 0 && inCheck {
+			g.table.values[index] = 1
+		}
+	}
+}
+
+func (g *generator) pawnOnEdgeRank(squares []int) bool {
+	for i := 2; i < g.men; i++ {
+		if g.kinds[i] == 'p' && (squares[i] < 8 || squares[i] >= 56) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// promote seeds the positions in which the pawn promotes to the piece of
+// the child table and the lone king gets mated.
+func (g *generator) promote(child *Table) {
+	squares := make([]int, g.men)
+	for index := 0; index < g.size; index++ {
+		if g.table.values[index] == illegal {
+			continue
+		}
+		g.decode(index, squares)
+
+		pawn := squares[2]
+		if pawn < 48 || pawn == squares[0]-8 || pawn == squares[1]-8 {
+			continue
+		}
+
+		value := child.values[child.index(false, []int{squares[0], squares[1], pawn + 8})]
+		if value == draw || value == illegal || (value-1)%2 == 1 {
+			continue
+		}
+
+		// Mated in value-1 plies after the promotion.
+		distance := int(value)
+		g.seeds[distance] = append(g.seeds[distance], index)
+	}
+}
+
+// retract goes back one ply from a position decided at the distance. The
+// strong side wins the positions from which it can reach a lost position of
+// the lone king; the lone king loses when all its moves reach won ones.
+func (g *generator) retract(index, distance int) {
+	squares := make([]int, g.men)
+	loneToMove := g.decode(index, squares)
+
+	var occupied uint64
+	for _, square := range squares {
+		occupied |= 1 << square
+	}
+
+	if !loneToMove {
+		from := squares[1]
+		for origins := kingMask[from] &^ occupied; origins != 0; origins &= origins - 1 {
+			squares[1] = bits.TrailingZeros64(origins)
+			previous := g.encode(true, squares)
+			if g.table.values[previous] != draw {
+				continue
+			}
+			g.moves[previous-g.size]--
+			if g.moves[previous-g.size] == 0 {
+				g.table.values[previous] = byte(distance + 2)
+			}
+		}
+		return
+	}
+
+	for i := 0; i < g.men; i++ {
+		if i == 1 {
+			continue
+		}
+		to := squares[i]
+		for _, from := range g.origins(g.kinds[i], to, occupied) {
+			squares[i] = from
+			previous := g.encode(false, squares)
+			if g.table.values[previous] == draw {
+				g.table.values[previous] = byte(distance + 2)
+			}
+		}
+		squares[i] = to
+	}
+}
+
+// origins returns the empty squares a strong man can have come from to the
+// square. Nothing was taken, the lone king having no pieces.
+func (g *generator) origins(kind byte, to int, occupied uint64) []int {
+	var from []int
+	switch kind {
+	case 'k', 'n':
+		mask := kingMask[to]
+		if kind == 'n' {
+			mask = knightMask[to]
+		}
+		for squares := mask &^ occupied; squares != 0; squares &= squares - 1 {
+			from = append(from, bits.TrailingZeros64(squares))
+		}
+	case 'p':
+		if to >= 16 && occupied&(1<<(to-8)) == 0 {
+			from = append(from, to-8)
+			if to/8 == 3 && occupied&(1<<(to-16)) == 0 {
+				from = append(from, to-16)
+			}
+		}
+	default:
+		first, last := 0, 8
+		switch kind {
+		case 'r':
+			last = 4
+		case 'b':
+			first = 4
+		}
+		for _, ray := range rays[to][first:last] {
+			for _, square := range ray {
+				if occupied&(1<<square) != 0 {
+					break
+				}
+				from = append(from, square)
+			}
+		}
+	}
+
+	return from
+}
+
+// Longest returns the longest distance to mate of the table in plies.
+func (table *Table) Longest() int {
+	longest := 0
+	for _, value := range table.values {
+		if value != illegal && int(value)-1 > longest {
+			longest = int(value) - 1
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,406 @@
+// Package tablebase generates and probes endgame tablebases: for every
+// position of an endgame the distance to mate with best play. Tables are
+// built by retrograde analysis for a lone king against a king and pieces,
+// KQK, KRK, KPK and KBNK, on the standard 8x8 board without castling
+// rights. Only tables in this package's own format are probed: Syzygy
+// WDL/DTZ files are not supported.
+package tablebase
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/datadir"
+)
+
+// Endgames are the tables Generate builds, named by the pieces of the
+// strong side and the lone king.
+var Endgames = []string{"KQK", "KRK", "KPK", "KBNK"}
+
+var ErrUnsupported = errors.New("position is not in a tablebase endgame")
+
+// maxPieces is the number of pieces besides the kings of the largest table.
+const maxPieces = 2
+
+// pieceOrder sorts the pieces of a table name.
+const pieceOrder = "qrbnp"
+
+const (
+	fileExtension = ".tb.gz"
+	header        = "chess_on_golang tablebase "
+)
+
+// Values of a table entry besides a distance to mate in plies plus one.
+const (
+	draw    = 0
+	illegal = 255
+)
+
+// WDL is the outcome of a position for the side to move.
+type WDL int
+
+const (
+	Loss WDL = iota - 1
+	Draw
+	Win
+)
+
+func (wdl WDL) String() string {
+	switch wdl {
+	case Win:
+		return "win"
+	case Loss:
+		return "loss"
+	default:
+		return "draw"
+	}
+}
+
+// Result is the value of a position for the side to move. DTM is the
+// number of plies to mate with best play, zero for a draw.
+type Result struct {
+	WDL WDL
+	DTM int
+}
+
+// Table holds one byte per position of an endgame: zero for a draw, 255
+// for a position that cannot occur, otherwise the distance to mate in
+// plies plus one. The side to move mates when the distance is odd and is
+// mated when it is even. The strong side is White in the table.
+type Table struct {
+	Name   string
+	pieces string
+	values []byte
+}
+
+func newTable(name string) (*Table, error) {
+	pieces, ok := tablePieces(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown endgame %q, expected one of %s", name, strings.Join(Endgames, ", "))
+	}
+
+	return &Table{Name: name, pieces: pieces, values: make([]byte, 2<<(6*(len(pieces)+2)))}, nil
+}
+
+// tablePieces returns the lowercase pieces of the strong side besides the
+// king of a table name.
+func tablePieces(name string) (string, bool) {
+	for _, endgame := range Endgames {
+		if endgame == name {
+			return strings.ToLower(name[1 : len(name)-1]), true
+		}
+	}
+
+	return "", false
+}
+
+// men is the number of pieces on the board, kings included.
+func (table *Table) men() int {
+	return len(table.pieces) + 2
+}
+
+// index of the position with the squares of the strong king, the lone king
+// and the pieces, squares counting from a1 along the ranks.
+func (table *Table) index(strongToMove bool, squares []int) int {
+	index := 1
+	if strongToMove {
+		index = 0
+	}
+	for _, square := range squares {
+		index = index<<6 | square
+	}
+
+	return index
+}
+
+// result reads the entry at the index.
+func (table *Table) result(index int) (Result, error) {
+	value := table.values[index]
+	switch {
+	case value == illegal:
+		return Result{}, errors.New("position cannot occur")
+	case value == draw:
+		return Result{WDL: Draw}, nil
+	case (value-1)%2 == 1:
+		return Result{WDL: Win, DTM: int(value) - 1}, nil
+	default:
+		return Result{WDL: Loss, DTM: int(value) - 1}, nil
+	}
+}
+
+// Probe returns the result of the position with the team to move.
+func (table *Table) Probe(b *board.Board, team board.Team) (Result, error) {
+	name, strongToMove, squares, err := material(b, team)
+	if err != nil {
+		return Result{}, err
+	}
+	if name != table.Name {
+		return Result{}, fmt.Errorf("position is %s, not %s", name, table.Name)
+	}
+
+	return table.result(table.index(strongToMove, squares))
+}
+
+// material names the endgame of the position and returns the squares of
+// its men as the table orders them, mirrored when Black is the strong side.
+func material(b *board.Board, team board.Team) (string, bool, []int, error) {
+	if b.Files() != 8 || b.Ranks() != 8 || b.Variant() != board.Standard {
+		return "", false, nil, ErrUnsupported
+	}
+
+	var kings [2]int
+	var pieces [2][]string
+	var squares [2][]int
+	for row, signs := range b.Grid() {
+		for col, sign := range signs {
+			if sign == "" {
+				continue
+			}
+			side := 0
+			if sign != strings.ToLower(sign) {
+				side = 1
+			}
+
+			square := (7-row)*8 + col
+			if strings.ToLower(sign) == "k" {
+				kings[side] = square
+				continue
+			}
+			if len(pieces[0])+len(pieces[1]) == maxPieces {
+				return "", false, nil, ErrUnsupported
+			}
+			pieces[side] = append(pieces[side], strings.ToLower(sign))
+			squares[side] = append(squares[side], square)
+		}
+	}
+
+	strong := 0
+	if len(pieces[0]) == 0 {
+		strong = 1
+	}
+	if len(pieces[1-strong]) != 0 || len(pieces[strong]) == 0 {
+		return "", false, nil, ErrUnsupported
+	}
+	if fields := strings.Fields(b.FEN(team)); fields[2] != "-" {
+		return "", false, nil, ErrUnsupported
+	}
+
+	// The pieces go in the order of the table name.
+	ordered := []int{kings[strong], kings[1-strong]}
+	name := "K"
+	for _, letter := range pieceOrder {
+		for i, piece := range pieces[strong] {
+			if piece == string(letter) {
+				name += strings.ToUpper(piece)
+				ordered = append(ordered, squares[strong][i])
+			}
+		}
+	}
+	name += "K"
+	if _, ok := tablePieces(name); !ok {
+		return "", false, nil, ErrUnsupported
+	}
+
+	if strong == 1 {
+		for i := range ordered {
+			ordered[i] ^= 56
+		}
+	}
+
+	return name, (team == board.White) == (strong == 0), ordered, nil
+}
+
+// Write writes the table gzip compressed after a header naming it.
+func (table *Table) Write(w io.Writer) error {
+	compressed := gzip.NewWriter(w)
+	if _, err := io.WriteString(compressed, header+table.Name+"\n"); err != nil {
+		return err
+	}
+	if _, err := compressed.Write(table.values); err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
+
+// Read reads a table written by Write.
+func Read(r io.Reader) (*Table, error) {
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer compressed.Close()
+
+	reader := bufio.NewReader(compressed)
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, header) {
+		return nil, errors.New("not a tablebase file")
+	}
+
+	table, err := newTable(strings.TrimSpace(strings.TrimPrefix(line, header)))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(reader, table.values); err != nil {
+		return nil, fmt.Errorf("tablebase %s is truncated", table.Name)
+	}
+
+	return table, nil
+}
+
+// Save writes the table to its file in the directory.
+func (table *Table) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(Path(dir, table.Name))
+	if err != nil {
+		return err
+	}
+	if err := table.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Load reads the table of the endgame from the directory.
+func Load(dir, name string) (*Table, error) {
+	file, err := os.Open(Path(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.Name(), err)
+	}
+	if table.Name != name {
+		return nil, fmt.Errorf("%s holds %s", file.Name(), table.Name)
+	}
+
+	return table, nil
+}
+
+// Path is the file of the endgame in the directory.
+func Path(dir, name string) string {
+	return filepath.Join(dir, name+fileExtension)
+}
+
+// DefaultDir is ~/.chess_on_golang/tablebases.
+func DefaultDir() string {
+	return datadir.Path("tablebases")
+}
+
+// Set probes the tables of a directory, each read on its first use.
+type Set struct {
+	dir    string
+	mu     sync.Mutex
+	tables map[string]*Table
+	errs   map[string]error
+}
+
+// Open returns the set of tables in the directory. Missing tables are only
+// reported by Probe.
+func Open(dir string) *Set {
+	return &Set{dir: dir, tables: make(map[string]*Table), errs: make(map[string]error)}
+}
+
+// Probe returns the result of the position with the team to move.
+func (set *Set) Probe(b *board.Board, team board.Team) (Result, error) {
+	name, strongToMove, squares, err := material(b, team)
+	if err != nil {
+		return Result{}, err
+	}
+
+	table, err := set.table(name)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return table.result(table.index(strongToMove, squares))
+}
+
+func (set *Set) table(name string) (*Table, error) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	if table, ok := set.tables[name]; ok {
+		return table, nil
+	}
+	if err, ok := set.errs[name]; ok {
+		return nil, err
+	}
+
+	table, err := Load(set.dir, name)
+	if err != nil {
+		set.errs[name] = err
+		return nil, err
+	}
+	set.tables[name] = table
+	return table, nil
+}
+
+// Move is a legal move and the result it leads to for the side playing it.
+type Move struct {
+	Command string
+	Result  Result
+}
+
+// Moves returns the legal moves of the team ranked best first: the
+// quickest wins, the draws, then the slowest losses. A move leaving the
+// tablebase endgames, such as taking the last piece, is a draw.
+func (set *Set) Moves(b *board.Board, team board.Team) ([]Move, error) {
+	if _, err := set.Probe(b, team); err != nil {
+		return nil, err
+	}
+
+	var moves []Move
+	for _, command := range b.LegalMoves(team) {
+		child := b.Clone()
+		if child.Execute(command, team) {
+			moves = append(moves, Move{Command: command, Result: Result{WDL: Win, DTM: 1}})
+			continue
+		}
+
+		result, err := set.Probe(child, team.Opponent())
+		switch {
+		case errors.Is(err, ErrUnsupported):
+			result = Result{WDL: Draw}
+		case err != nil:
+			return nil, err
+		}
+		if result.WDL != Draw {
+			result = Result{WDL: -result.WDL, DTM: result.DTM + 1}
+		}
+		moves = append(moves, Move{Command: command, Result: result})
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Result.better(moves[j].Result)
+	})
+	return moves, nil
+}
+
+func (result Result) better(other Result) bool {
+	switch {
+	case result.WDL != other.WDL:
+		return result.WDL > other.WDL
+	case result.WDL == Win:
+		return result.DTM < other.DTM
+	default:
+		return result.DTM > other.DTM
+	}
+}
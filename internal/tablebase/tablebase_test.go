@@ -0,0 +1,58 @@
+package tablebase
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func generate(t *testing.T, name string) *Table {
+	t.Helper()
+
+	table, err := Generate(name, func(name string) (*Table, error) {
+		return Generate(name, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return table
+}
+
+// TestLongest checks the longest mates against the known ones: 10 moves
+// with a queen, 16 with a rook, 28 with a pawn and 33 with bishop and
+// knight, the lone king moving first and being mated after 2n plies.
+func TestLongest(t *testing.T) {
+	for name, moves := range map[string]int{"KQK": 10, "KRK": 16, "KPK": 28, "KBNK": 33} {
+		if testing.Short() && name == "KBNK" {
+			continue
+		}
+		if got, want := generate(t, name).Longest(), 2*moves; got != want {
+			t.Errorf("%s: longest mate %d plies, want %d", name, got, want)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	table := generate(t, "KQK")
+
+	for _, test := range []struct {
+		fen  string
+		want Result
+	}{
+		{"7k/8/6K1/8/8/8/8/1Q6 w - - 0 1", Result{Win, 1}},
+		{"7k/8/5QK1/8/8/8/8/8 b - - 0 1", Result{Loss, 2}},
+		// Stalemate.
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Result{Draw, 0}},
+		// The lone king takes the queen.
+		{"8/8/8/8/8/8/1Q6/k6K b - - 0 1", Result{Draw, 0}},
+	} {
+		b, team, err := board.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := table.Probe(b, team); err != nil || got != test.want {
+			t.Errorf("Probe(%s) = %+v, %v, want %+v", test.fen, got, err, test.want)
+		}
+	}
+}
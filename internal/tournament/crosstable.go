@@ -0,0 +1,216 @@
+package tournament
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// crosstable lays out the standings as rows of cells under a header. A
+// round robin has a column per opponent by place with "1", "½" or "0",
+// a Swiss a column per round with the result, colour and place of the
+// opponent, e.g. "+w3" or "=b5".
+func (tournament *Tournament) crosstable() ([]string, [][]string) {
+	standings := tournament.Standings()
+	place := make(map[int]int)
+	for i, standing := range standings {
+		place[standing.Player] = i + 1
+	}
+
+	header := []string{"#", "Name", "Rating"}
+	columns := len(tournament.Rounds)
+	if tournament.System == RoundRobin {
+		columns = len(standings)
+	}
+	for column := 1; column <= columns; column++ {
+		if tournament.System == RoundRobin {
+			header = append(header, strconv.Itoa(column))
+		} else {
+			header = append(header, "R"+strconv.Itoa(column))
+		}
+	}
+	header = append(header, "Pts")
+	for _, tiebreak := range tournament.System.Tiebreaks() {
+		header = append(header, tiebreak.Short())
+	}
+
+	var rows [][]string
+	for _, standing := range standings {
+		player := tournament.Players[standing.Player]
+		row := []string{strconv.Itoa(standing.Rank), player.Name, ""}
+		if player.Rating > 0 {
+			row[2] = strconv.Itoa(player.Rating)
+		}
+
+		if tournament.System == RoundRobin {
+			row = append(row, tournament.opponentCells(standing.Player, standings)...)
+		} else {
+			row = append(row, tournament.roundCells(standing.Player, place)...)
+		}
+
+		row = append(row, formatPoints(standing.Points))
+		for _, value := range standing.Tiebreaks {
+			row = append(row, formatPoints(value))
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+// opponentCells returns the results of the player against each player in
+// the order of the standings.
+func (tournament *Tournament) opponentCells(player int, standings []Standing) []string {
+	games := tournament.games(player)
+	var cells []string
+	for _, standing := range standings {
+		cell := ""
+		if standing.Player == player {
+			cell = "×"
+		}
+		for _, game := range games {
+			if game.opponent == standing.Player && game.finished {
+				cell += map[float64]string{1: "1", 0.5: "½", 0: "0"}[game.points]
+			}
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells
+}
+
+// roundCells returns the games of the player round by round.
+func (tournament *Tournament) roundCells(player int, place map[int]int) []string {
+	var cells []string
+	for _, round := range tournament.Rounds {
+		cell := ""
+		for _, pairing := range round.Pairings {
+			white, black := pairing.points()
+			switch {
+			case pairing.White == player && pairing.Black == Bye:
+				cell = "+bye"
+			case pairing.White == player:
+				cell = sign(pairing, white) + "w" + strconv.Itoa(place[pairing.Black])
+			case pairing.Black == player:
+				cell = sign(pairing, black) + "b" + strconv.Itoa(place[pairing.White])
+			}
+		}
+		cells = append(cells, cell)
+	}
+
+	return cells
+}
+
+// sign writes a result as +, = or -, nothing while the game is unfinished.
+func sign(pairing Pairing, points float64) string {
+	switch {
+	case pairing.Result == "":
+		return ""
+	case points == 1:
+		return "+"
+	case points == 0.5:
+		return "="
+	default:
+		return "-"
+	}
+}
+
+// title names the tournament with its system and progress.
+func (tournament *Tournament) title() string {
+	system := "Round robin"
+	if tournament.System == Swiss {
+		system = "Swiss"
+	}
+
+	return fmt.Sprintf("%s, %s, round %d of %d", tournament.Name, system, len(tournament.Rounds), tournament.TotalRounds)
+}
+
+// Crosstable writes the crosstable as text with aligned columns.
+func (tournament *Tournament) Crosstable() string {
+	header, rows := tournament.crosstable()
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(tournament.title() + "\n\n")
+	for _, row := range append([][]string{header}, rows...) {
+		var cells []string
+		for i, cell := range row {
+			padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i == 1 {
+				cells = append(cells, cell+padding)
+			} else {
+				cells = append(cells, padding+cell)
+			}
+		}
+		text.WriteString(strings.TrimRight(strings.Join(cells, "  "), " ") + "\n")
+	}
+
+	return text.String()
+}
+
+// CrosstableHTML writes the crosstable as a standalone HTML page.
+func (tournament *Tournament) CrosstableHTML() string {
+	header, rows := tournament.crosstable()
+	title := html.EscapeString(tournament.title())
+
+	var page strings.Builder
+	fmt.Fprintf(&page, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n", title)
+	page.WriteString("<style>table{border-collapse:collapse;font-family:sans-serif}th,td{border:1px solid #999;padding:2px 8px;text-align:center}td.name{text-align:left}</style>\n")
+	fmt.Fprintf(&page, "</head>\n<body>\n<h1>%s</h1>\n<table>\n<tr>", title)
+	for _, cell := range header {
+		fmt.Fprintf(&page, "<th>%s</th>", html.EscapeString(cell))
+	}
+	page.WriteString("</tr>\n")
+	for _, row := range rows {
+		page.WriteString("<tr>")
+		for i, cell := range row {
+			if i == 1 {
+				fmt.Fprintf(&page, "<td class=\"name\">%s</td>", html.EscapeString(cell))
+			} else {
+				fmt.Fprintf(&page, "<td>%s</td>", html.EscapeString(cell))
+			}
+		}
+		page.WriteString("</tr>\n")
+	}
+	page.WriteString("</table>\n</body>\n</html>\n")
+
+	return page.String()
+}
+
+// Pairings writes the pairings of a round, counted from 1, one board a
+// line with the points of the players before the round.
+func (tournament *Tournament) Pairings(round int) string {
+	if round < 1 || round > len(tournament.Rounds) {
+		return ""
+	}
+
+	before := &Tournament{System: tournament.System, Players: tournament.Players, Rounds: tournament.Rounds[:round-1]}
+	var text strings.Builder
+	for number, pairing := range tournament.Rounds[round-1].Pairings {
+		white := before.label(pairing.White)
+		if pairing.Black == Bye {
+			fmt.Fprintf(&text, "%3d. %s has a bye\n", number+1, white)
+			continue
+		}
+
+		result := pairing.Result
+		if result == "" {
+			result = "-"
+		}
+		fmt.Fprintf(&text, "%3d. %s  %s  %s\n", number+1, white, result, before.label(pairing.Black))
+	}
+
+	return text.String()
+}
+
+// label names a player with the points.
+func (tournament *Tournament) label(player int) string {
+	return fmt.Sprintf("%s (%s)", tournament.Players[player].Name, formatPoints(tournament.score(player)))
+}
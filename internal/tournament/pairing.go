@@ -0,0 +1,265 @@
+package tournament
+
+import (
+	"sort"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// bergerRound pairs a round, counted from 0, of the Berger tables. The
+// pairing numbers are the order of registration, the last number being
+// the bye for an odd number of players.
+func (tournament *Tournament) bergerRound(index int) Round {
+	players := len(tournament.Players)
+	size := players + players%2
+	others := size - 1
+
+	// The last number meets a number moving by half the field each round
+	// and changes colour every round; on the other boards the numbers
+	// after it play white against those before it.
+	wrap := func(number int) int {
+		return (number-1+others)%others + 1
+	}
+	fixed := index*(size/2)%others + 1
+	pairs := [][2]int{{fixed, size}}
+	if index%2 == 1 {
+		pairs[0] = [2]int{size, fixed}
+	}
+	for number := 1; number < size/2; number++ {
+		pairs = append(pairs, [2]int{wrap(fixed + number), wrap(fixed - number)})
+	}
+
+	var round Round
+	var bye *Pairing
+	for _, pair := range pairs {
+		white, black := pair[0]-1, pair[1]-1
+		switch {
+		case white == players:
+			bye = &Pairing{White: black, Black: Bye, Result: "1-0"}
+		case black == players:
+			bye = &Pairing{White: white, Black: Bye, Result: "1-0"}
+		default:
+			round.Pairings = append(round.Pairings, Pairing{White: white, Black: black})
+		}
+	}
+	if bye != nil {
+		round.Pairings = append(round.Pairings, *bye)
+	}
+
+	return round
+}
+
+// swissRound pairs a round by the Dutch system: the players are ranked by
+// score and rating, each score group is split in halves paired top
+// against top, and a player who cannot be paired in the group floats down
+// to the next. Players never meet twice and, if it can be helped, no
+// player gets a colour against an absolute preference.
+func (tournament *Tournament) swissRound() (Round, error) {
+	ranked := tournament.ranking()
+
+	var bye *Pairing
+	if len(ranked)%2 == 1 {
+		// The lowest ranked player without a bye sits out.
+		sitter := len(ranked) - 1
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if !tournament.hadBye(ranked[i]) {
+				sitter = i
+				break
+			}
+		}
+		bye = &Pairing{White: ranked[sitter], Black: Bye, Result: "1-0"}
+		ranked = append(ranked[:sitter:sitter], ranked[sitter+1:]...)
+	}
+
+	pairs, ok := tournament.pair(ranked, true)
+	if !ok {
+		pairs, ok = tournament.pair(ranked, false)
+	}
+	if !ok {
+		return Round{}, ErrNoPairing
+	}
+
+	var round Round
+	for number, pair := range pairs {
+		round.Pairings = append(round.Pairings, tournament.allocate(pair[0], pair[1], number))
+	}
+	if bye != nil {
+		round.Pairings = append(round.Pairings, *bye)
+	}
+
+	return round, nil
+}
+
+// ranking returns the players by score, then rating, then registration.
+func (tournament *Tournament) ranking() []int {
+	ranked := make([]int, len(tournament.Players))
+	scores := make([]float64, len(tournament.Players))
+	for i := range ranked {
+		ranked[i] = i
+		scores[i] = tournament.score(i)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return tournament.Players[a].Rating > tournament.Players[b].Rating
+	})
+	return ranked
+}
+
+func (tournament *Tournament) hadBye(player int) bool {
+	for _, game := range tournament.games(player) {
+		if game.opponent == Bye {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pair pairs the ranked players, the highest one first with the candidates
+// in Dutch order, backtracking when the rest cannot be paired. strict also
+// keeps absolute colour preferences.
+func (tournament *Tournament) pair(ranked []int, strict bool) ([][2]int, bool) {
+	if len(ranked) == 0 {
+		return nil, true
+	}
+
+	top := ranked[0]
+	for _, candidate := range tournament.candidates(ranked) {
+		if !tournament.compatible(top, ranked[candidate], strict) {
+			continue
+		}
+
+		rest := make([]int, 0, len(ranked)-2)
+		for i, player := range ranked[1:] {
+			if i+1 != candidate {
+				rest = append(rest, player)
+			}
+		}
+		if pairs, ok := tournament.pair(rest, strict); ok {
+			return append([][2]int{{top, ranked[candidate]}}, pairs...), true
+		}
+	}
+
+	return nil, false
+}
+
+// candidates returns the positions of the opponents of the highest ranked
+// player in the order they are tried: the lower half of its score group
+// from its counterpart down, then the upper half from the bottom, then
+// the lower groups.
+func (tournament *Tournament) candidates(ranked []int) []int {
+	score := tournament.score(ranked[0])
+	group := 1
+	for group < len(ranked) && tournament.score(ranked[group]) == score {
+		group++
+	}
+
+	var order []int
+	for i := group / 2; i < group; i++ {
+		if i > 0 {
+			order = append(order, i)
+		}
+	}
+	for i := group/2 - 1; i > 0; i-- {
+		order = append(order, i)
+	}
+	for i := group; i < len(ranked); i++ {
+		order = append(order, i)
+	}
+
+	return order
+}
+
+// compatible reports whether the players may meet: they have not met and,
+// when strict, do not both need the same colour.
+func (tournament *Tournament) compatible(a, b int, strict bool) bool {
+	for _, game := range tournament.games(a) {
+		if game.opponent == b {
+			return false
+		}
+	}
+	if !strict {
+		return true
+	}
+
+	colourA, strengthA := tournament.preference(a)
+	colourB, strengthB := tournament.preference(b)
+	return !(strengthA == absolute && strengthB == absolute && colourA == colourB)
+}
+
+// Strengths of a colour preference.
+const (
+	none = iota
+	mild
+	strong
+	absolute
+)
+
+// preference returns the colour the player is due and how strongly: a
+// player who had a colour twice more or twice in a row must get the other,
+// one who had it once more should, and one with even colours would
+// alternate.
+func (tournament *Tournament) preference(player int) (board.Team, int) {
+	var colours []board.Team
+	difference := 0
+	for _, game := range tournament.games(player) {
+		if game.opponent == Bye {
+			continue
+		}
+		colours = append(colours, game.colour)
+		if game.colour == board.White {
+			difference++
+		} else {
+			difference--
+		}
+	}
+	if len(colours) == 0 {
+		return board.Undecided, none
+	}
+
+	last := colours[len(colours)-1]
+	switch {
+	case difference > 1:
+		return board.Black, absolute
+	case difference < -1:
+		return board.White, absolute
+	case len(colours) > 1 && colours[len(colours)-2] == last:
+		return last.Opponent(), absolute
+	case difference == 1:
+		return board.Black, strong
+	case difference == -1:
+		return board.White, strong
+	default:
+		return last.Opponent(), mild
+	}
+}
+
+// allocate gives the colours of a pair, a ranked above b, on the board of
+// the number counted from 0: both get their due colour when they differ,
+// otherwise the stronger preference wins and then the higher ranked
+// player. In the first round the higher ranked player has white on every
+// other board.
+func (tournament *Tournament) allocate(a, b, number int) Pairing {
+	colourA, strengthA := tournament.preference(a)
+	colourB, strengthB := tournament.preference(b)
+
+	aWhite := number%2 == 0
+	switch {
+	case strengthA == none && strengthB == none:
+	case colourA != colourB && strengthA != none && strengthB != none:
+		aWhite = colourA == board.White
+	case strengthA >= strengthB:
+		aWhite = colourA == board.White
+	default:
+		aWhite = colourB != board.White
+	}
+
+	if aWhite {
+		return Pairing{White: a, Black: b}
+	}
+	return Pairing{White: b, Black: a}
+}
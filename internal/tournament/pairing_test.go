@@ -0,0 +1,93 @@
+package tournament
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// bergerTables are the FIDE Berger tables by pairing numbers, white first.
+var bergerTables = map[int][][][2]int{
+	4: {
+		{{1, 4}, {2, 3}},
+		{{4, 3}, {1, 2}},
+		{{2, 4}, {3, 1}},
+	},
+	6: {
+		{{1, 6}, {2, 5}, {3, 4}},
+		{{6, 4}, {5, 3}, {1, 2}},
+		{{2, 6}, {3, 1}, {4, 5}},
+		{{6, 5}, {1, 4}, {2, 3}},
+		{{3, 6}, {4, 2}, {5, 1}},
+	},
+}
+
+func newRoundRobin(t *testing.T, players int) *Tournament {
+	t.Helper()
+
+	tournament, err := New("test", RoundRobin, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= players; i++ {
+		if _, err := tournament.AddPlayer(fmt.Sprintf("player %d", i), 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tournament
+}
+
+func TestBergerTables(t *testing.T) {
+	for players, rounds := range bergerTables {
+		tournament := newRoundRobin(t, players)
+		if tournament.TotalRounds != len(rounds) {
+			t.Errorf("%d players: %d rounds, want %d", players, tournament.TotalRounds, len(rounds))
+		}
+
+		for index, want := range rounds {
+			var got [][2]int
+			for _, pairing := range tournament.bergerRound(index).Pairings {
+				got = append(got, [2]int{pairing.White + 1, pairing.Black + 1})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%d players, round %d: %v, want %v", players, index+1, got, want)
+			}
+		}
+	}
+}
+
+// TestBergerBye pairs five players by the table of six, the sixth number
+// being the bye, given last.
+func TestBergerBye(t *testing.T) {
+	tournament := newRoundRobin(t, 5)
+
+	met := make(map[[2]int]bool)
+	for index, table := range bergerTables[6] {
+		round := tournament.bergerRound(index)
+		if len(round.Pairings) != 3 {
+			t.Fatalf("round %d has %d pairings, want 3", index+1, len(round.Pairings))
+		}
+
+		bye := round.Pairings[2]
+		if bye.Black != Bye || bye.Result != "1-0" {
+			t.Errorf("round %d: last pairing %+v is not a bye", index+1, bye)
+		}
+		for _, pair := range table {
+			if pair[0] == 6 && bye.White != pair[1]-1 || pair[1] == 6 && bye.White != pair[0]-1 {
+				t.Errorf("round %d: bye for %d, want the opponent of 6 in %v", index+1, bye.White+1, table)
+			}
+		}
+
+		for _, pairing := range round.Pairings[:2] {
+			key := [2]int{min(pairing.White, pairing.Black), max(pairing.White, pairing.Black)}
+			if met[key] {
+				t.Errorf("round %d: %v meet again", index+1, key)
+			}
+			met[key] = true
+		}
+	}
+	if len(met) != 10 {
+		t.Errorf("%d games, want every pair of five players once", len(met))
+	}
+}
@@ -0,0 +1,164 @@
+package tournament
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Tiebreak orders players with the same points.
+type Tiebreak string
+
+const (
+	// Buchholz is the sum of the scores of the opponents.
+	Buchholz Tiebreak = "Buchholz"
+	// SonnebornBerger is the sum of the scores of the opponents beaten and
+	// half those of the opponents drawn.
+	SonnebornBerger Tiebreak = "Sonneborn-Berger"
+	// DirectEncounter is the points scored against the other players with
+	// the same points, when all of them have met.
+	DirectEncounter Tiebreak = "Direct encounter"
+)
+
+// Short is the abbreviation of the tiebreak in crosstables.
+func (tiebreak Tiebreak) Short() string {
+	switch tiebreak {
+	case Buchholz:
+		return "BH"
+	case SonnebornBerger:
+		return "SB"
+	default:
+		return "DE"
+	}
+}
+
+// Tiebreaks returns the tiebreaks of the system in the order they apply.
+func (system System) Tiebreaks() []Tiebreak {
+	if system == RoundRobin {
+		return []Tiebreak{DirectEncounter, SonnebornBerger}
+	}
+
+	return []Tiebreak{Buchholz, SonnebornBerger, DirectEncounter}
+}
+
+// Standing is the place of a player. Tiebreaks are in the order of
+// System.Tiebreaks, and players equal on points and tiebreaks share their
+// rank.
+type Standing struct {
+	Rank      int
+	Player    int
+	Points    float64
+	Tiebreaks []float64
+}
+
+// Standings ranks the players on the finished games. Byes count a point
+// but are left out of the tiebreaks.
+func (tournament *Tournament) Standings() []Standing {
+	tiebreaks := tournament.System.Tiebreaks()
+	scores := make([]float64, len(tournament.Players))
+	for player := range tournament.Players {
+		scores[player] = tournament.score(player)
+	}
+
+	standings := make([]Standing, len(tournament.Players))
+	for player := range tournament.Players {
+		standing := Standing{Player: player, Points: scores[player]}
+		for _, tiebreak := range tiebreaks {
+			standing.Tiebreaks = append(standing.Tiebreaks, tournament.tiebreak(tiebreak, player, scores))
+		}
+		standings[player] = standing
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		if less, decided := compare(standings[i], standings[j]); decided {
+			return less
+		}
+		return tournament.Players[standings[i].Player].Rating > tournament.Players[standings[j].Player].Rating
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 {
+			if _, decided := compare(standings[i-1], standings[i]); !decided {
+				standings[i].Rank = standings[i-1].Rank
+			}
+		}
+	}
+
+	return standings
+}
+
+// compare reports whether a ranks above b, and whether the points and
+// tiebreaks tell them apart.
+func compare(a, b Standing) (bool, bool) {
+	if a.Points != b.Points {
+		return a.Points > b.Points, true
+	}
+	for i := range a.Tiebreaks {
+		if a.Tiebreaks[i] != b.Tiebreaks[i] {
+			return a.Tiebreaks[i] > b.Tiebreaks[i], true
+		}
+	}
+
+	return false, false
+}
+
+func (tournament *Tournament) tiebreak(tiebreak Tiebreak, player int, scores []float64) float64 {
+	total := 0.0
+	switch tiebreak {
+	case Buchholz, SonnebornBerger:
+		for _, game := range tournament.games(player) {
+			if game.opponent == Bye || !game.finished {
+				continue
+			}
+			if tiebreak == Buchholz {
+				total += scores[game.opponent]
+			} else {
+				total += game.points * scores[game.opponent]
+			}
+		}
+
+	case DirectEncounter:
+		tied := make(map[int]bool)
+		var group []int
+		for other := range tournament.Players {
+			if scores[other] == scores[player] {
+				tied[other] = true
+				group = append(group, other)
+			}
+		}
+		if len(group) < 2 || !tournament.allMet(group) {
+			return 0
+		}
+
+		for _, game := range tournament.games(player) {
+			if game.finished && tied[game.opponent] {
+				total += game.points
+			}
+		}
+	}
+
+	return total
+}
+
+// allMet reports whether each of the players has played all the others.
+func (tournament *Tournament) allMet(players []int) bool {
+	for _, player := range players {
+		opponents := make(map[int]bool)
+		for _, game := range tournament.games(player) {
+			if game.finished {
+				opponents[game.opponent] = true
+			}
+		}
+		for _, other := range players {
+			if other != player && !opponents[other] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// formatPoints writes points without trailing zeros, e.g. 2.5 or 3.
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
@@ -0,0 +1,284 @@
+// Package tournament runs round-robin and Swiss tournaments: it keeps the
+// players, pairs the rounds, records the results of the games and ranks
+// the players with tiebreaks. A tournament is saved as a JSON file.
+package tournament
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+var (
+	ErrStarted         = errors.New("the tournament has started")
+	ErrFinished        = errors.New("all the rounds are paired")
+	ErrRoundUnfinished = errors.New("the round has games without a result")
+	ErrTooFewPlayers   = errors.New("a tournament needs two players")
+	ErrNoPairing       = errors.New("no pairing of the round is possible")
+	ErrGameNotOver     = errors.New("the game is not over")
+)
+
+// System is the way the rounds are paired.
+type System string
+
+const (
+	// RoundRobin pairs everyone with everyone by the Berger tables.
+	RoundRobin System = "round-robin"
+	// Swiss pairs players with the same score by the Dutch system.
+	Swiss System = "swiss"
+)
+
+func ParseSystem(name string) (System, error) {
+	switch strings.ToLower(name) {
+	case "round-robin", "roundrobin", "rr":
+		return RoundRobin, nil
+	case "swiss":
+		return Swiss, nil
+	default:
+		return "", fmt.Errorf("unknown tournament system %q", name)
+	}
+}
+
+// Bye stands for the opponent of the player sitting out a round.
+const Bye = -1
+
+type Player struct {
+	Name   string `json:"name"`
+	Rating int    `json:"rating,omitempty"`
+}
+
+// Pairing is a game of a round between the players of the indexes. A bye
+// is paired with Bye as black and recorded as a win.
+type Pairing struct {
+	White int `json:"white"`
+	Black int `json:"black"`
+	// Result is "1-0", "0-1" or "1/2-1/2", empty until the game is over.
+	Result string `json:"result,omitempty"`
+}
+
+// Round holds the pairings of a round, in board order.
+type Round struct {
+	Pairings []Pairing `json:"pairings"`
+}
+
+type Tournament struct {
+	Name   string `json:"name"`
+	System System `json:"system"`
+	// TotalRounds is the length of a Swiss tournament. A round robin has a
+	// round less than its players, counting a bye for an odd number.
+	TotalRounds int      `json:"totalRounds"`
+	Players     []Player `json:"players"`
+	Rounds      []Round  `json:"rounds"`
+}
+
+// New creates a tournament without players. rounds is only used by the
+// Swiss system.
+func New(name string, system System, rounds int) (*Tournament, error) {
+	if system == Swiss && rounds < 1 {
+		return nil, errors.New("a Swiss tournament needs a number of rounds")
+	}
+
+	return &Tournament{Name: name, System: system, TotalRounds: rounds}, nil
+}
+
+// AddPlayer registers a player before the first round and returns its
+// index.
+func (tournament *Tournament) AddPlayer(name string, rating int) (int, error) {
+	if len(tournament.Rounds) > 0 {
+		return 0, ErrStarted
+	}
+	if tournament.find(name) >= 0 {
+		return 0, fmt.Errorf("%s is already registered", name)
+	}
+
+	tournament.Players = append(tournament.Players, Player{Name: name, Rating: rating})
+	if tournament.System == RoundRobin {
+		tournament.TotalRounds = len(tournament.Players) + len(tournament.Players)%2 - 1
+	}
+	return len(tournament.Players) - 1, nil
+}
+
+// find returns the index of the player with the name, or -1.
+func (tournament *Tournament) find(name string) int {
+	for i, player := range tournament.Players {
+		if strings.EqualFold(player.Name, name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Finished reports whether every round is paired and played.
+func (tournament *Tournament) Finished() bool {
+	return len(tournament.Rounds) == tournament.TotalRounds && tournament.roundDone()
+}
+
+// roundDone reports whether the last round paired has all its results.
+func (tournament *Tournament) roundDone() bool {
+	if len(tournament.Rounds) == 0 {
+		return true
+	}
+
+	for _, pairing := range tournament.Rounds[len(tournament.Rounds)-1].Pairings {
+		if pairing.Result == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NextRound pairs the next round once the last one is over.
+func (tournament *Tournament) NextRound() (Round, error) {
+	switch {
+	case len(tournament.Players) < 2:
+		return Round{}, ErrTooFewPlayers
+	case !tournament.roundDone():
+		return Round{}, ErrRoundUnfinished
+	case len(tournament.Rounds) >= tournament.TotalRounds:
+		return Round{}, ErrFinished
+	}
+
+	var round Round
+	var err error
+	if tournament.System == RoundRobin {
+		round = tournament.bergerRound(len(tournament.Rounds))
+	} else if round, err = tournament.swissRound(); err != nil {
+		return Round{}, err
+	}
+
+	tournament.Rounds = append(tournament.Rounds, round)
+	return round, nil
+}
+
+// Record sets the result of a game, "1-0", "0-1" or "1/2-1/2", by round
+// and board numbers counted from 1. A result may be corrected.
+func (tournament *Tournament) Record(round, number int, result string) error {
+	if round < 1 || round > len(tournament.Rounds) {
+		return fmt.Errorf("round %d is not paired", round)
+	}
+	pairings := tournament.Rounds[round-1].Pairings
+	if number < 1 || number > len(pairings) {
+		return fmt.Errorf("round %d has no board %d", round, number)
+	}
+	if pairings[number-1].Black == Bye {
+		return fmt.Errorf("board %d of round %d is a bye", number, round)
+	}
+
+	switch result {
+	case "1-0", "0-1", "1/2-1/2":
+	default:
+		return fmt.Errorf("result %q: want 1-0, 0-1 or 1/2-1/2", result)
+	}
+
+	pairings[number-1].Result = result
+	return nil
+}
+
+// RecordGame records the result of a finished game played on the board.
+func (tournament *Tournament) RecordGame(round, number int, chessGame *game.ChessGame) error {
+	result, over := chessGame.Result()
+	if !over {
+		return ErrGameNotOver
+	}
+
+	return tournament.Record(round, number, result.Score())
+}
+
+// points returns what the players of the pairing scored, nothing while the
+// game is unfinished.
+func (pairing Pairing) points() (white, black float64) {
+	switch pairing.Result {
+	case "1-0":
+		return 1, 0
+	case "0-1":
+		return 0, 1
+	case "1/2-1/2":
+		return 0.5, 0.5
+	default:
+		return 0, 0
+	}
+}
+
+// played is a game of a player as seen by the player.
+type played struct {
+	opponent int
+	colour   board.Team
+	points   float64
+	finished bool
+}
+
+// games returns the games of a player round by round, a bye being played
+// against Bye. A bye scores a point in a Swiss tournament only, each
+// player of a round robin with an odd number of players having one.
+func (tournament *Tournament) games(player int) []played {
+	var games []played
+	for _, round := range tournament.Rounds {
+		for _, pairing := range round.Pairings {
+			white, black := pairing.points()
+			if pairing.Black == Bye && tournament.System == RoundRobin {
+				white = 0
+			}
+			finished := pairing.Result != ""
+			switch player {
+			case pairing.White:
+				games = append(games, played{opponent: pairing.Black, colour: board.White, points: white, finished: finished})
+			case pairing.Black:
+				games = append(games, played{opponent: pairing.White, colour: board.Black, points: black, finished: finished})
+			}
+		}
+	}
+
+	return games
+}
+
+// score is the points of the player in the finished games.
+func (tournament *Tournament) score(player int) float64 {
+	total := 0.0
+	for _, game := range tournament.games(player) {
+		total += game.points
+	}
+
+	return total
+}
+
+// Load reads a tournament saved at the path.
+func Load(path string) (*Tournament, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tournament := &Tournament{}
+	if err := json.Unmarshal(data, tournament); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := ParseSystem(string(tournament.System)); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return tournament, nil
+}
+
+// Save writes the tournament to the path.
+func (tournament *Tournament) Save(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(tournament, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
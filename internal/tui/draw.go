@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+// 256-colour palette indexes of the square backgrounds.
+const (
+	colorLight    = 180
+	colorDark     = 137
+	colorCursor   = 68
+	colorSelected = 71
+	colorTarget   = 108
+	colorLastMove = 143
+	colorCheck    = 160
+
+	colorWhitePiece = 231
+	colorBlackPiece = 16
+)
+
+// Both sides use the solid glyphs, the colour tells them apart.
+var glyphs = map[string]string{
+	"k": "♚", "q": "♛", "r": "♜", "b": "♝", "n": "♞", "p": "♟",
+}
+
+// glyph returns the glyph of the piece, its uppercase letter for fairy
+// pieces.
+func glyph(sign string) string {
+	if glyph, ok := glyphs[strings.ToLower(sign)]; ok {
+		return glyph
+	}
+
+	return strings.ToUpper(sign)
+}
+
+// panelLeft is the screen column of the panel next to the board.
+func (u *ui) panelLeft() int {
+	return boardLeft + u.files*cellWidth + 4
+}
+
+func (u *ui) draw() {
+	var screen strings.Builder
+	screen.WriteString("\x1b[H\x1b[2J")
+
+	u.drawBoard(&screen)
+	u.drawPanel(&screen)
+
+	moveTo(&screen, boardTop+u.ranks+2, 1)
+	screen.WriteString(u.status())
+	moveTo(&screen, boardTop+u.ranks+3, 1)
+	screen.WriteString(u.message)
+
+	fmt.Fprint(u.out, screen.String())
+}
+
+func (u *ui) drawBoard(screen *strings.Builder) {
+	grid := u.game.Board().Grid()
+	highlights := u.highlights()
+
+	moveTo(screen, boardTop-1, boardLeft)
+	screen.WriteString(u.fileLabels())
+
+	for row := 0; row < u.ranks; row++ {
+		moveTo(screen, boardTop+row, 1)
+		rank := u.fromScreen(row, 0).row
+		fmt.Fprintf(screen, "%2d ", u.ranks-rank)
+
+		for col := 0; col < u.files; col++ {
+			sq := u.fromScreen(row, col)
+			background := colorLight
+			if (u.ranks-sq.row+sq.col)%2 == 1 {
+				background = colorDark
+			}
+			if color, ok := highlights[sq]; ok {
+				background = color
+			}
+
+			fmt.Fprintf(screen, "\x1b[48;5;%dm", background)
+			screen.WriteString(cell(grid[sq.row][sq.col], u.isTarget(sq)))
+			screen.WriteString("\x1b[0m")
+		}
+
+		fmt.Fprintf(screen, " %d", u.ranks-rank)
+	}
+
+	moveTo(screen, boardTop+u.ranks, boardLeft)
+	screen.WriteString(u.fileLabels())
+}
+
+// highlights returns the background colour of the marked squares. Later
+// marks win: last move, check, legal targets, selection, cursor.
+func (u *ui) highlights() map[square]int {
+	highlights := make(map[square]int)
+
+	for _, sq := range u.lastMove(u.game.Moves()) {
+		highlights[sq] = colorLastMove
+	}
+
+	if king, ok := u.kingInCheck(); ok {
+		highlights[king] = colorCheck
+	}
+
+	if u.selected != nil {
+		highlights[*u.selected] = colorSelected
+	}
+	highlights[u.cursor] = colorCursor
+
+	return highlights
+}
+
+func (u *ui) kingInCheck() (square, bool) {
+	team := u.game.Turn()
+	if !u.game.Board().InCheck(team) {
+		return square{}, false
+	}
+
+	for row, signs := range u.game.Board().Grid() {
+		for col, sign := range signs {
+			if sign == "" {
+				continue
+			}
+			piece := board.CreatePiece(sign, row, col)
+			if piece.Team() == team && strings.EqualFold(sign, "k") {
+				return square{row: row, col: col}, true
+			}
+		}
+	}
+
+	return square{}, false
+}
+
+func cell(sign string, target bool) string {
+	if sign == "" {
+		if target {
+			return fmt.Sprintf("\x1b[38;5;%dm • ", colorBlackPiece)
+		}
+		return "   "
+	}
+
+	color := colorWhitePiece
+	if sign == strings.ToUpper(sign) {
+		color = colorBlackPiece
+	}
+
+	marker := " "
+	if target {
+		marker = "×"
+	}
+
+	return fmt.Sprintf("\x1b[1;38;5;%dm%s%s ", color, marker, glyph(sign))
+}
+
+func (u *ui) fileLabels() string {
+	var files strings.Builder
+	for col := 0; col < u.files; col++ {
+		fmt.Fprintf(&files, " %c ", 'a'+u.fromScreen(0, col).col)
+	}
+
+	return files.String()
+}
+
+func (u *ui) drawPanel(screen *strings.Builder) {
+	line := boardTop - 1
+	write := func(text string) {
+		moveTo(screen, line, u.panelLeft())
+		screen.WriteString(text)
+		line++
+	}
+
+	if clock := u.game.Clock(); clock != nil {
+		write(fmt.Sprintf("White %s   Black %s",
+			formatClock(clock.Remaining(board.White)), formatClock(clock.Remaining(board.Black))))
+	}
+
+	b := u.game.Board()
+	write("White captures: " + captures(b.WhiteCaptures()))
+	write("Black captures: " + captures(b.BlackCaptures()))
+	write("")
+	write("Moves:")
+
+	// Show the latest moves that fit next to the board.
+	moves := u.game.Moves()
+	rows := (len(moves) + 1) / 2
+	first := 0
+	if room := boardTop + u.ranks + 1 - line; rows > room {
+		first = rows - room
+	}
+
+	for i := first; i < rows; i++ {
+		text := fmt.Sprintf("%3d. %-7s", i+1, moves[2*i])
+		if 2*i+1 < len(moves) {
+			text += moves[2*i+1]
+		}
+		write(text)
+	}
+}
+
+func (u *ui) status() string {
+	if result, over := u.game.Result(); over {
+		return fmt.Sprintf("Game over: %s %s", result.Score(), result.Termination)
+	}
+
+	status := fmt.Sprintf("%s to move", strings.ToUpper(u.game.Turn().String()))
+	if u.game.Board().InCheck(u.game.Turn()) {
+		status += ", check!"
+	}
+
+	return status
+}
+
+func captures(signs []string) string {
+	var text strings.Builder
+	for _, sign := range signs {
+		text.WriteString(glyph(sign))
+	}
+
+	return text.String()
+}
+
+func moveTo(screen *strings.Builder, row, col int) {
+	fmt.Fprintf(screen, "\x1b[%d;%dH", row, col)
+}
+
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyEscape
+	keyMouse
+	keyInterrupt
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+
+	// Mouse presses carry the 1-based screen position.
+	x, y int
+}
+
+// parseKeys splits raw terminal input into keys. Escape sequences are
+// expected to arrive in a single read, as terminals send them.
+func parseKeys(input []byte) []key {
+	var keys []key
+
+	for len(input) > 0 {
+		switch {
+		case strings.HasPrefix(string(input), "\x1b[<"):
+			k, n := parseMouse(input)
+			if k != nil {
+				keys = append(keys, *k)
+			}
+			input = input[n:]
+		case len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O'):
+			switch input[2] {
+			case 'A':
+				keys = append(keys, key{kind: keyUp})
+			case 'B':
+				keys = append(keys, key{kind: keyDown})
+			case 'C':
+				keys = append(keys, key{kind: keyRight})
+			case 'D':
+				keys = append(keys, key{kind: keyLeft})
+			}
+			input = input[3:]
+		case input[0] == 0x1b:
+			keys = append(keys, key{kind: keyEscape})
+			input = input[1:]
+		case input[0] == '\r' || input[0] == '\n' || input[0] == ' ':
+			keys = append(keys, key{kind: keyEnter})
+			input = input[1:]
+		case input[0] == 0x03:
+			keys = append(keys, key{kind: keyInterrupt})
+			input = input[1:]
+		default:
+			keys = append(keys, key{kind: keyRune, r: rune(input[0])})
+			input = input[1:]
+		}
+	}
+
+	return keys
+}
+
+// parseMouse reads an SGR mouse report "ESC [ < b ; x ; y M". Only presses
+// of the left button are returned.
+func parseMouse(input []byte) (*key, int) {
+	end := strings.IndexAny(string(input), "Mm")
+	if end < 0 {
+		return nil, len(input)
+	}
+
+	fields := strings.Split(string(input[3:end]), ";")
+	if len(fields) != 3 || input[end] != 'M' {
+		return nil, end + 1
+	}
+
+	button, _ := strconv.Atoi(fields[0])
+	x, _ := strconv.Atoi(fields[1])
+	y, _ := strconv.Atoi(fields[2])
+	if button != 0 {
+		return nil, end + 1
+	}
+
+	return &key{kind: keyMouse, x: x, y: y}, end + 1
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package tui
+
+import "errors"
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("tui: raw terminal mode is only supported on linux")
+}
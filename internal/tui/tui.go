@@ -0,0 +1,294 @@
+// Package tui is a full-screen terminal interface for a game: the board is
+// drawn with ANSI colours, pieces are picked and dropped with the keyboard
+// or the mouse, and side panels show the moves, captures and clocks.
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/game"
+)
+
+const (
+	// Screen position of the top left square, 1-based as in ANSI sequences.
+	boardTop  = 3
+	boardLeft = 4
+	cellWidth = 3
+
+	refreshInterval = time.Second
+)
+
+type square struct {
+	row, col int
+}
+
+// ui is the state of the interface. Squares are board coordinates, row 0
+// being the last rank.
+type ui struct {
+	game         *game.ChessGame
+	files, ranks int
+	out          io.Writer
+	cursor       square
+	selected     *square
+	flipped      bool
+	message      string
+	quit         bool
+}
+
+// Run plays the game in the terminal until it ends or the user quits.
+func Run(chessGame *game.ChessGame) error {
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	b := chessGame.Board()
+	u := &ui{
+		game:    chessGame,
+		files:   b.Files(),
+		ranks:   b.Ranks(),
+		out:     os.Stdout,
+		cursor:  square{row: b.Ranks() - 2, col: b.Files() / 2},
+		flipped: chessGame.Turn() == board.Black,
+		message: "Arrows or mouse to pick a piece, enter to move it, ? for help.",
+	}
+
+	// Alternate screen, hidden cursor and SGR mouse reports.
+	fmt.Fprint(u.out, "\x1b[?1049h\x1b[?25l\x1b[?1000h\x1b[?1006h")
+	defer fmt.Fprint(u.out, "\x1b[?1006l\x1b[?1000l\x1b[?25h\x1b[?1049l")
+
+	input := make(chan []byte)
+	go readInput(os.Stdin, input)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	u.draw()
+	for !u.quit {
+		select {
+		case data, ok := <-input:
+			if !ok {
+				return nil
+			}
+			for _, k := range parseKeys(data) {
+				u.handle(k)
+			}
+		case <-ticker.C:
+			if u.game.CheckTime() {
+				u.message = "Time is up."
+			}
+		}
+		u.draw()
+	}
+
+	return nil
+}
+
+func readInput(r io.Reader, input chan<- []byte) {
+	defer close(input)
+
+	buffer := make([]byte, 256)
+	for {
+		n, err := r.Read(buffer)
+		if err != nil {
+			return
+		}
+		input <- append([]byte(nil), buffer[:n]...)
+	}
+}
+
+func (u *ui) handle(k key) {
+	switch k.kind {
+	case keyInterrupt:
+		u.quit = true
+	case keyUp:
+		u.moveCursor(-1, 0)
+	case keyDown:
+		u.moveCursor(1, 0)
+	case keyLeft:
+		u.moveCursor(0, -1)
+	case keyRight:
+		u.moveCursor(0, 1)
+	case keyEnter:
+		u.pick(u.cursor)
+	case keyEscape:
+		u.selected = nil
+	case keyMouse:
+		if sq, ok := u.squareAt(k.x, k.y); ok {
+			u.cursor = sq
+			u.pick(sq)
+		}
+	case keyRune:
+		u.command(k.r)
+	}
+}
+
+func (u *ui) command(r rune) {
+	switch r {
+	case 'q':
+		u.quit = true
+	case 'k', 'w':
+		u.moveCursor(-1, 0)
+	case 'j', 's':
+		u.moveCursor(1, 0)
+	case 'h', 'a':
+		u.moveCursor(0, -1)
+	case 'l', 'd':
+		u.moveCursor(0, 1)
+	case 'f':
+		u.flipped = !u.flipped
+	case 'u':
+		u.selected = nil
+		if err := u.game.Undo(); err != nil {
+			u.message = err.Error()
+		} else {
+			u.message = "Move taken back."
+		}
+	case 'r':
+		if err := u.game.Resign(u.game.Turn()); err != nil {
+			u.message = err.Error()
+		}
+	case '?':
+		u.message = "arrows/hjkl move, enter/space/click select, esc cancel, f flip, u undo, r resign, q quit"
+	}
+}
+
+// moveCursor moves the cursor in screen directions, so that up is always
+// up whichever way the board is flipped.
+func (u *ui) moveCursor(down, right int) {
+	if u.flipped {
+		down, right = -down, -right
+	}
+
+	u.cursor.row = clamp(u.cursor.row+down, u.ranks)
+	u.cursor.col = clamp(u.cursor.col+right, u.files)
+}
+
+// pick selects a piece of the side to move, or plays the selected piece to
+// the square.
+func (u *ui) pick(sq square) {
+	if _, over := u.game.Result(); over {
+		u.message = "The game is over, q to quit."
+		return
+	}
+
+	if u.selected != nil && u.isTarget(sq) {
+		move := u.position(*u.selected) + " " + u.position(sq)
+		u.selected = nil
+		if err := u.game.Play(move); err != nil {
+			u.message = err.Error()
+		} else {
+			u.message = ""
+		}
+		return
+	}
+
+	if u.ownPiece(sq) {
+		u.selected = &sq
+		return
+	}
+
+	u.selected = nil
+}
+
+func (u *ui) ownPiece(sq square) bool {
+	sign := u.game.Board().Grid()[sq.row][sq.col]
+	if sign == "" {
+		return false
+	}
+
+	return board.CreatePiece(sign, sq.row, sq.col).Team() == u.game.Turn()
+}
+
+func (u *ui) isTarget(sq square) bool {
+	if u.selected == nil {
+		return false
+	}
+
+	move := u.position(*u.selected) + " " + u.position(sq)
+	for _, legal := range u.game.LegalMoves() {
+		if legal == move {
+			return true
+		}
+	}
+
+	return false
+}
+
+// squareAt maps a screen position to a board square.
+func (u *ui) squareAt(x, y int) (square, bool) {
+	row := y - boardTop
+	col := (x - boardLeft) / cellWidth
+	if x < boardLeft || row < 0 || row >= u.ranks || col >= u.files {
+		return square{}, false
+	}
+
+	return u.fromScreen(row, col), true
+}
+
+// fromScreen converts screen rows and columns of the board to a square; the
+// conversion is its own inverse.
+func (u *ui) fromScreen(row, col int) square {
+	if u.flipped {
+		return square{row: u.ranks - 1 - row, col: u.files - 1 - col}
+	}
+
+	return square{row: row, col: col}
+}
+
+func (u *ui) position(sq square) string {
+	return string(rune('a'+sq.col)) + strconv.Itoa(u.ranks-sq.row)
+}
+
+func (u *ui) parsePosition(position string) (square, bool) {
+	if len(position) < 2 {
+		return square{}, false
+	}
+	rank, err := strconv.Atoi(position[1:])
+	if err != nil {
+		return square{}, false
+	}
+
+	sq := square{row: u.ranks - rank, col: int(position[0] - 'a')}
+	if clamp(sq.row, u.ranks) != sq.row || clamp(sq.col, u.files) != sq.col {
+		return square{}, false
+	}
+
+	return sq, true
+}
+
+// clamp keeps the value between 0 and size-1.
+func clamp(value, size int) int {
+	if value < 0 {
+		return 0
+	}
+	if value >= size {
+		return size - 1
+	}
+
+	return value
+}
+
+func (u *ui) lastMove(moves []string) []square {
+	if len(moves) == 0 {
+		return nil
+	}
+
+	var squares []square
+	for _, field := range strings.Fields(moves[len(moves)-1]) {
+		// A drop such as "N@f3" only marks its square.
+		field = field[strings.LastIndex(field, "@")+1:]
+		if sq, ok := u.parsePosition(field); ok {
+			squares = append(squares, sq)
+		}
+	}
+
+	return squares
+}
@@ -0,0 +1,167 @@
+package uci
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/tablebase"
+)
+
+// maxDepth is the depth of a search bounded by time only.
+const maxDepth = 64
+
+// defaultLimits are used by go without depth, movetime or clock.
+var defaultLimits = engine.Limits{Depth: 5, Time: 3 * time.Second}
+
+// Serve answers the UCI commands read from in with the internal engine until
+// quit or the end of the input. The search is synchronous, so stop has no
+// effect and go infinite searches with the default limits.
+func Serve(in io.Reader, out io.Writer, tables *tablebase.Set) error {
+	server := server{out: out, tables: tables}
+	server.position("startpos")
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "uci":
+			fmt.Fprintln(out, "id name chess_on_golang")
+			fmt.Fprintln(out, "id author chess_on_golang")
+			fmt.Fprintf(out, "option name %s type check default false\n", Chess960Option)
+			fmt.Fprintln(out, "uciok")
+		case "isready":
+			fmt.Fprintln(out, "readyok")
+		case "setoption":
+			server.setOption(fields[1:])
+		case "position":
+			server.position(strings.Join(fields[1:], " "))
+		case "go":
+			server.search(fields[1:])
+		case "quit":
+			return nil
+		}
+	}
+
+	return scanner.Err()
+}
+
+type server struct {
+	out      io.Writer
+	tables   *tablebase.Set
+	chess960 bool
+	board    *board.Board
+	team     board.Team
+}
+
+// setOption reads "name UCI_Chess960 value true", the only option.
+func (server *server) setOption(fields []string) {
+	if len(fields) == 4 && fields[0] == "name" && fields[1] == Chess960Option && fields[2] == "value" {
+		server.chess960 = fields[3] == "true"
+		server.board.SetChess960(server.chess960)
+	}
+}
+
+// position reads "startpos" or "fen FEN" with the moves that follow. An
+// illegal move is reported and ends the moves.
+func (server *server) position(text string) {
+	fen, moves, _ := strings.Cut(text, "moves")
+	fen = strings.TrimSpace(fen)
+	if fen == "startpos" {
+		fen = board.StartFEN
+	} else {
+		fen = strings.TrimSpace(strings.TrimPrefix(fen, "fen"))
+	}
+
+	b, team, err := board.ParseFEN(fen)
+	if err != nil {
+		fmt.Fprintf(server.out, "info string %v\n", err)
+		return
+	}
+	b.SetChess960(server.chess960)
+
+	for _, move := range strings.Fields(moves) {
+		command := Command(move)
+		if !slices.Contains(b.LegalMoves(team), command) {
+			fmt.Fprintf(server.out, "info string illegal move %s\n", move)
+			break
+		}
+		b.Execute(command, team)
+		team = team.Opponent()
+	}
+
+	server.board, server.team = b, team
+}
+
+// search reads the limits of go and answers with info and bestmove.
+func (server *server) search(fields []string) {
+	limits := engine.Limits{Tablebases: server.tables}
+	clock := map[string]int{}
+	for i := 0; i+1 < len(fields); i++ {
+		value, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			continue
+		}
+		switch fields[i] {
+		case "depth":
+			limits.Depth = value
+		case "movetime":
+			limits.Time = time.Duration(value) * time.Millisecond
+		case "wtime", "btime", "winc", "binc", "movestogo":
+			clock[fields[i]] = value
+		}
+	}
+
+	// On the clock a move gets its share of the time left, assuming 30
+	// moves to go, and most of the increment.
+	timeKey, incrementKey := "wtime", "winc"
+	if server.team == board.Black {
+		timeKey, incrementKey = "btime", "binc"
+	}
+	if remaining, ok := clock[timeKey]; ok && limits.Time == 0 {
+		movesToGo := 30
+		if clock["movestogo"] > 0 {
+			movesToGo = clock["movestogo"]
+		}
+		budget := min(remaining/movesToGo+clock[incrementKey]*3/4, remaining/2)
+		limits.Time = time.Duration(max(budget, 1)) * time.Millisecond
+	}
+	switch {
+	case limits.Depth == 0 && limits.Time == 0:
+		limits.Depth, limits.Time = defaultLimits.Depth, defaultLimits.Time
+	case limits.Depth == 0:
+		limits.Depth = maxDepth
+	}
+
+	result, err := engine.Search(server.board, server.team, limits)
+	if err != nil {
+		fmt.Fprintln(server.out, "bestmove (none)")
+		return
+	}
+
+	var pv []string
+	for _, move := range result.PV {
+		pv = append(pv, Move(move))
+	}
+	fmt.Fprintf(server.out, "info depth %d score %s nodes %d pv %s\n", result.Depth, formatScore(result.Score), result.Nodes, strings.Join(pv, " "))
+	fmt.Fprintf(server.out, "bestmove %s\n", Move(result.Move))
+}
+
+// formatScore writes a score as "cp 35" or "mate -3".
+func formatScore(score int) string {
+	if moves, ok := engine.MateIn(score); ok {
+		return fmt.Sprintf("mate %d", moves)
+	}
+
+	return fmt.Sprintf("cp %d", score)
+}
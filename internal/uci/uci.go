@@ -0,0 +1,324 @@
+// Package uci speaks the Universal Chess Interface: Engine drives an
+// external engine as a child process and Serve makes the internal engine
+// answer a UCI GUI. Moves are written as in UCI, e.g. e7e8q, and Chess960
+// castlings as the king taking its own rook, which is how Board.Execute
+// takes them once the UCI_Chess960 option is set.
+package uci
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/engine"
+)
+
+var ErrTimeout = errors.New("engine did not answer in time")
+
+const (
+	// answerTimeout bounds the wait for uciok, readyok and, past the move
+	// time, for bestmove.
+	answerTimeout = 10 * time.Second
+	// Chess960Option is the option that switches an engine to Chess960.
+	Chess960Option = "UCI_Chess960"
+)
+
+// Engine is an external engine reading commands on its standard input.
+type Engine struct {
+	// Name is the name the engine gives with "id name".
+	Name    string
+	options map[string]bool
+	cmd     *exec.Cmd
+	in      io.WriteCloser
+	lines   chan string
+}
+
+// Limits bounds a search of an external engine. Zero values are left out
+// of the go command.
+type Limits struct {
+	Depth    int
+	MoveTime time.Duration
+}
+
+// Result is the answer of an engine to go. Score is in centipawns for the
+// side to move, mates scored as by the internal engine, and HasScore false
+// when the engine gave none.
+type Result struct {
+	Move     string
+	Score    int
+	HasScore bool
+}
+
+// Start runs the command, the path of the engine and its arguments, and
+// waits for it to accept UCI.
+func Start(command []string) (*Engine, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no engine command")
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	uci := &Engine{
+		Name:    command[0],
+		options: make(map[string]bool),
+		cmd:     cmd,
+		in:      in,
+		lines:   make(chan string, 64),
+	}
+	go func() {
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			uci.lines <- scanner.Text()
+		}
+		close(uci.lines)
+	}()
+
+	if err := uci.send("uci"); err != nil {
+		uci.Close()
+		return nil, err
+	}
+	err = uci.read(answerTimeout, func(fields []string) bool {
+		switch {
+		case len(fields) > 2 && fields[0] == "id" && fields[1] == "name":
+			uci.Name = strings.Join(fields[2:], " ")
+		case len(fields) > 2 && fields[0] == "option" && fields[1] == "name":
+			uci.options[optionName(fields[2:])] = true
+		}
+		return fields[0] == "uciok"
+	})
+	if err != nil {
+		uci.Close()
+		return nil, fmt.Errorf("%s: %w", command[0], err)
+	}
+
+	return uci, nil
+}
+
+// optionName reads the name of an option declaration, which ends at its
+// type.
+func optionName(fields []string) string {
+	var name []string
+	for _, field := range fields {
+		if field == "type" {
+			break
+		}
+		name = append(name, field)
+	}
+
+	return strings.Join(name, " ")
+}
+
+// HasOption reports whether the engine declared the option.
+func (uci *Engine) HasOption(name string) bool {
+	return uci.options[name]
+}
+
+// SetOption sets an option of the engine.
+func (uci *Engine) SetOption(name, value string) error {
+	if !uci.HasOption(name) {
+		return fmt.Errorf("%s has no option %q", uci.Name, name)
+	}
+
+	return uci.send("setoption name %s value %s", name, value)
+}
+
+// NewGame tells the engine the next position is of another game and waits
+// until it is ready.
+func (uci *Engine) NewGame() error {
+	if err := uci.send("ucinewgame"); err != nil {
+		return err
+	}
+	if err := uci.send("isready"); err != nil {
+		return err
+	}
+
+	return uci.read(answerTimeout, func(fields []string) bool {
+		return fields[0] == "readyok"
+	})
+}
+
+// Go searches the position reached by the moves, commands of Board.Execute,
+// from the FEN, and returns the best move as a command.
+func (uci *Engine) Go(fen string, moves []string, limits Limits) (Result, error) {
+	position := "position fen " + fen
+	if len(moves) > 0 {
+		var written []string
+		for _, move := range moves {
+			written = append(written, Move(move))
+		}
+		position += " moves " + strings.Join(written, " ")
+	}
+	if err := uci.send("%s", position); err != nil {
+		return Result{}, err
+	}
+
+	search := "go"
+	if limits.Depth > 0 {
+		search += fmt.Sprintf(" depth %d", limits.Depth)
+	}
+	if limits.MoveTime > 0 {
+		search += fmt.Sprintf(" movetime %d", limits.MoveTime.Milliseconds())
+	}
+	if err := uci.send("%s", search); err != nil {
+		return Result{}, err
+	}
+
+	var result Result
+	timeout := answerTimeout + 2*limits.MoveTime
+	if limits.MoveTime == 0 {
+		timeout = 10 * answerTimeout
+	}
+	err := uci.read(timeout, func(fields []string) bool {
+		switch fields[0] {
+		case "info":
+			if score, ok := parseScore(fields); ok {
+				result.Score, result.HasScore = score, true
+			}
+		case "bestmove":
+			if len(fields) > 1 {
+				result.Move = Command(fields[1])
+			}
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if result.Move == "" || result.Move == "(none)" {
+		return Result{}, fmt.Errorf("%s gave no move", uci.Name)
+	}
+
+	return result, nil
+}
+
+// parseScore reads "score cp 35" or "score mate -3" of an info line. Bounds
+// are taken as they are.
+func parseScore(fields []string) (int, bool) {
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i] != "score" {
+			continue
+		}
+
+		value, err := strconv.Atoi(fields[i+2])
+		if err != nil {
+			return 0, false
+		}
+		switch fields[i+1] {
+		case "cp":
+			return value, true
+		case "mate":
+			if value > 0 {
+				return engine.Mate - 2*value + 1, true
+			}
+			return -engine.Mate - 2*value, true
+		}
+	}
+
+	return 0, false
+}
+
+// Close asks the engine to quit and waits for it, killing it when it does
+// not.
+func (uci *Engine) Close() error {
+	uci.send("quit")
+	uci.in.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- uci.cmd.Wait() }()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(answerTimeout):
+		uci.cmd.Process.Kill()
+		return <-done
+	}
+}
+
+func (uci *Engine) send(format string, args ...any) error {
+	_, err := fmt.Fprintf(uci.in, format+"\n", args...)
+	return err
+}
+
+// read passes the lines of the engine to handle until it returns true.
+func (uci *Engine) read(timeout time.Duration, handle func(fields []string) bool) error {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case line, ok := <-uci.lines:
+			if !ok {
+				return fmt.Errorf("%s exited", uci.Name)
+			}
+			if fields := strings.Fields(line); len(fields) > 0 && handle(fields) {
+				return nil
+			}
+		case <-deadline:
+			return ErrTimeout
+		}
+	}
+}
+
+// Move writes a command such as "e7 e8 q" as a UCI move, e7e8q.
+func Move(command string) string {
+	return strings.ReplaceAll(command, " ", "")
+}
+
+// Command writes a UCI move such as e7e8q as a command, "e7 e8 q".
+func Command(move string) string {
+	if strings.Contains(move, "@") || len(move) < 2 {
+		return move
+	}
+
+	// The destination starts at the second letter.
+	split := strings.IndexFunc(move[1:], func(char rune) bool {
+		return char >= 'a' && char <= 'z'
+	}) + 1
+	if split == 0 {
+		return move
+	}
+
+	rest := move[split:]
+	end := 1 + strings.IndexFunc(rest[1:], func(char rune) bool {
+		return char < '0' || char > '9'
+	})
+	if end == 0 {
+		return move[:split] + " " + rest
+	}
+
+	return move[:split] + " " + rest[:end] + " " + rest[end:]
+}
+
+// ParseMove reads a move typed by a player, in SAN, as a command such as
+// "g1 f3" or in UCI such as g1f3, and returns it as a legal command.
+func ParseMove(b *board.Board, team board.Team, input string) (string, error) {
+	if command, err := b.ParseSAN(input, team); err == nil {
+		return command, nil
+	}
+	if !strings.Contains(input, " ") {
+		input = Command(input)
+	}
+	for _, move := range b.LegalMoves(team) {
+		if strings.EqualFold(move, input) {
+			return move, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: illegal move", input)
+}
@@ -0,0 +1,34 @@
+package uci
+
+import (
+	"testing"
+
+	"github.com/DmitriyKolesnikM8O/chess_on_golang/internal/board"
+)
+
+func TestParseMove(t *testing.T) {
+	b, team, err := board.ParseFEN("4k3/1P6/8/8/8/8/8/4K1N1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for input, want := range map[string]string{
+		"Nf3":     "g1 f3",
+		"g1 f3":   "g1 f3",
+		"G1 F3":   "g1 f3",
+		"g1f3":    "g1 f3",
+		"b8=N":    "b7 b8 n",
+		"b7b8n":   "b7 b8 n",
+		"b7 b8 q": "b7 b8 q",
+	} {
+		if got, err := ParseMove(b, team, input); err != nil || got != want {
+			t.Errorf("ParseMove(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"Nf4", "g1g4", "e8 e7", ""} {
+		if got, err := ParseMove(b, team, input); err == nil {
+			t.Errorf("ParseMove(%q) = %q, want an error", input, got)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"os"
 	"strconv"
 	"strings"
@@ -67,12 +68,15 @@ func StringifyBoard(board [][]string) string {
 
 	row := len(board)
 	col := len(board[0])
+	// Ranks are right-aligned when the board has ten of them.
+	width := len(strconv.Itoa(row))
+	margin := strings.Repeat(" ", width+2)
 
 	var buffer bytes.Buffer
 
 	for i := 0; i < col; i++ {
 		if i == 0 {
-			buffer.WriteString("   ")
+			buffer.WriteString(margin)
 		}
 		colLetter := (string)(i + 'a')
 		buffer.WriteString(" " + colLetter + "  ")
@@ -80,7 +84,7 @@ func StringifyBoard(board [][]string) string {
 	buffer.WriteString("\n")
 
 	for i := row - 1; i >= 0; i-- {
-		buffer.WriteString(strconv.Itoa(i + 1))
+		fmt.Fprintf(&buffer, "%*d", width, i+1)
 		buffer.WriteString(" |")
 
 		for j := 0; j < col; j++ {
@@ -97,7 +101,7 @@ func StringifyBoard(board [][]string) string {
 
 	for i := 0; i < col; i++ {
 		if i == 0 {
-			buffer.WriteString("   ")
+			buffer.WriteString(margin)
 		}
 		colLetter := (string)(i + 'a')
 		buffer.WriteString(" " + colLetter + "  ")